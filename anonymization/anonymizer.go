@@ -0,0 +1,223 @@
+// Package anonymization removes the protected health information from ASTM messages, so they can be shared (e.g. with instrument vendors)
+package anonymization
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/blutspende/bloodlab-common/encoding"
+	"github.com/krendel52/go-astm/v3/enums/anonymizationaction"
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/functions"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+)
+
+// Length of the pseudonyms (hexadecimal characters of the HMAC)
+const PseudonymLength = 12
+
+// Anonymizer replaces the identifying values with keyed pseudonyms and shifts the dates, the structure and the results are kept
+// The pseudonyms are HMAC-SHA256 based, so the same value gets the same pseudonym in every message anonymized with the same key
+type Anonymizer struct {
+	// Secret key of the pseudonyms
+	Key []byte
+	// Number of days all the dates are shifted by (negative to shift back)
+	DateShiftDays int
+	// Positions to anonymize with their action
+	Rules Rules
+	// Configuration of the messages (encoding, line separator, etc.)
+	Configuration astmmodels.Configuration
+}
+
+func NewAnonymizer(key []byte, dateShiftDays int) Anonymizer {
+	return Anonymizer{
+		Key:           key,
+		DateShiftDays: dateShiftDays,
+		Rules:         DefaultRules(),
+		Configuration: astmmodels.DefaultConfiguration,
+	}
+}
+
+// Message anonymizes a message of any type without a structure, the lines without changes are kept as they are
+func (a Anonymizer) Message(messageData []byte) (result []byte, err error) {
+	// Load configuration
+	config, err := functions.LoadConfiguration(a.Configuration)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := a.parseRules()
+	if err != nil {
+		return nil, err
+	}
+	// Convert encoding to UTF8
+	utf8Data, err := encoding.ConvertFromEncodingToUtf8(messageData, config.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	// Split the message data into lines
+	lines, err := functions.SliceLines(utf8Data, config)
+	if err != nil {
+		return nil, err
+	}
+	// Anonymize the records one by one, only the changed ones are rebuilt
+	for i, line := range lines {
+		record, err := functions.ParseGenericLine(line, config)
+		if err != nil {
+			return nil, err
+		}
+		if a.anonymizeRecord(&record, rules) {
+			lines[i], err = functions.BuildGenericLine(record, config)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	// Convert UTF8 string to encoding
+	output := functions.BuildLines(lines, config)
+	result, err = encoding.ConvertFromUtf8ToEncoding(output, config.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	// Return the result and no error if everything went well
+	return result, nil
+}
+
+// Records anonymizes schema-less records (e.g. from UnmarshalGeneric), the given records are not changed
+func (a Anonymizer) Records(records []astmmodels.GenericRecord) (result []astmmodels.GenericRecord, err error) {
+	rules, err := a.parseRules()
+	if err != nil {
+		return nil, err
+	}
+	result = make([]astmmodels.GenericRecord, len(records))
+	for i, record := range records {
+		result[i] = copyRecord(record)
+		a.anonymizeRecord(&result[i], rules)
+	}
+	return result, nil
+}
+
+// Struct anonymizes an annotated structure in place, the original lines of the round-trip preservation are dropped
+func (a Anonymizer) Struct(targetStruct interface{}) (err error) {
+	// The structure is changed in place
+	targetValue := reflect.ValueOf(targetStruct)
+	if targetValue.Kind() != reflect.Ptr || targetValue.IsNil() || targetValue.Elem().Kind() != reflect.Struct {
+		return errmsg.ErrAnnotationParsingInvalidInputStruct
+	}
+	// Load configuration
+	config, err := functions.LoadConfiguration(a.Configuration)
+	if err != nil {
+		return err
+	}
+	// The original lines would keep the original values
+	config.PreserveRawRecords = false
+	rules, err := a.parseRules()
+	if err != nil {
+		return err
+	}
+	// Build the lines of the structure and anonymize them
+	lines, err := functions.BuildStruct(targetStruct, 1, 0, config)
+	if err != nil {
+		return err
+	}
+	for i, line := range lines {
+		record, err := functions.ParseGenericLine(line, config)
+		if err != nil {
+			return err
+		}
+		if a.anonymizeRecord(&record, rules) {
+			lines[i], err = functions.BuildGenericLine(record, config)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	// Parse the anonymized lines back into the emptied structure
+	targetValue.Elem().Set(reflect.Zero(targetValue.Elem().Type()))
+	lineIndex := 0
+	return functions.ParseStruct(lines, targetStruct, &lineIndex, 1, 0, config)
+}
+
+// Pseudonym returns the pseudonym of a value, e.g. to look up an anonymized patient or specimen
+func (a Anonymizer) Pseudonym(value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, a.Key)
+	mac.Write([]byte(value))
+	return strings.ToUpper(hex.EncodeToString(mac.Sum(nil))[:PseudonymLength])
+}
+
+func (a Anonymizer) parseRules() (map[string][]rule, error) {
+	if len(a.Key) == 0 {
+		return nil, errmsg.ErrAnonymizationMissingKey
+	}
+	return a.Rules.parse()
+}
+
+// Apply the rules of the record type to the record, returns true if a value was changed
+func (a Anonymizer) anonymizeRecord(record *astmmodels.GenericRecord, rules map[string][]rule) (changed bool) {
+	for _, rule := range rules[record.Type] {
+		if rule.field > len(record.Fields) {
+			continue
+		}
+		for _, repeat := range record.Fields[rule.field-1] {
+			for i, value := range repeat {
+				if value == "" || (rule.component != 0 && rule.component != i+1) {
+					continue
+				}
+				repeat[i] = a.anonymizeValue(value, rule.action)
+				changed = changed || repeat[i] != value
+			}
+		}
+	}
+	return changed
+}
+
+func (a Anonymizer) anonymizeValue(value string, action string) string {
+	switch action {
+	case anonymizationaction.Pseudonymize:
+		return a.Pseudonym(value)
+	case anonymizationaction.ShiftDate:
+		return shiftDate(value, a.DateShiftDays)
+	default:
+		return ""
+	}
+}
+
+// ASTM date formats by their length (see the date parsing of ParseLine)
+var dateFormats = map[int]string{
+	8:  "20060102",
+	12: "060102150405",
+	14: "20060102150405",
+}
+
+// Shift a date by the given days in the same format, the values which are not dates are cleared (they could still identify)
+func shiftDate(value string, days int) string {
+	dateFormat, exists := dateFormats[len(value)]
+	if !exists {
+		return ""
+	}
+	// The dates are shifted without time zone, so the time of the day stays the same
+	date, err := time.Parse(dateFormat, value)
+	if err != nil {
+		return ""
+	}
+	return date.AddDate(0, 0, days).Format(dateFormat)
+}
+
+func copyRecord(record astmmodels.GenericRecord) astmmodels.GenericRecord {
+	result := astmmodels.GenericRecord{Type: record.Type, Fields: make([]astmmodels.GenericField, len(record.Fields))}
+	for i, field := range record.Fields {
+		if field == nil {
+			continue
+		}
+		result.Fields[i] = make(astmmodels.GenericField, len(field))
+		for j, repeat := range field {
+			result.Fields[i][j] = append([]string(nil), repeat...)
+		}
+	}
+	return result
+}
@@ -0,0 +1,176 @@
+package anonymization
+
+import (
+	"github.com/krendel52/go-astm/v3"
+	"github.com/krendel52/go-astm/v3/enums/anonymizationaction"
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testKey = []byte("secret key")
+
+const testMessage = "H|\\^&|||LIS-Host^1.0|||||Lab-System||P|LIS2-A2|20240625093000\n" +
+	"P|1|PAT-100|LAB-100||Doe^John||19800115|M||Main Street 1^Berlin||+49301234\n" +
+	"O|1|SMP-1|INS-1|^^^TSH|R||20240625080000||||||||Serum^Blood\n" +
+	"R|1|^^^TSH^^^|2.35|mU/l|0.4-4.0|N||F||TECH^||20240625092245|Analyzer-1\n" +
+	"L|1|N"
+
+func TestMessage_DefaultRules(t *testing.T) {
+	// Arrange
+	anonymizer := NewAnonymizer(testKey, -10)
+	// Act
+	result, err := anonymizer.Message([]byte(testMessage))
+	// Assert
+	assert.Nil(t, err)
+	lines := strings.Split(string(result), "\n")
+	assert.Len(t, lines, 5)
+	assert.Equal(t, "H|\\^&|||LIS-Host^1.0|||||Lab-System||P|LIS2-A2|20240615093000", lines[0])
+	assert.Equal(t, "P|1|"+anonymizer.Pseudonym("PAT-100")+"|"+anonymizer.Pseudonym("LAB-100")+"||"+
+		anonymizer.Pseudonym("Doe")+"^"+anonymizer.Pseudonym("John")+"||19800105|M||"+
+		anonymizer.Pseudonym("Main Street 1")+"^"+anonymizer.Pseudonym("Berlin")+"||"+anonymizer.Pseudonym("+49301234"), lines[1])
+	assert.Equal(t, "O|1|"+anonymizer.Pseudonym("SMP-1")+"|"+anonymizer.Pseudonym("INS-1")+"|^^^TSH|R||20240615080000||||||||Serum^Blood", lines[2])
+	// The results and the terminator are kept
+	assert.Equal(t, "R|1|^^^TSH^^^|2.35|mU/l|0.4-4.0|N||F||TECH^||20240615092245|Analyzer-1", lines[3])
+	assert.Equal(t, "L|1|N", lines[4])
+	assert.NotContains(t, string(result), "Doe")
+	assert.NotContains(t, string(result), "PAT-100")
+}
+
+func TestMessage_ConsistentPseudonyms(t *testing.T) {
+	// Arrange
+	anonymizer := NewAnonymizer(testKey, 0)
+	query := "H|\\^&\nQ|1|^SMP-1||ALL\nL|1|N"
+	// Act
+	anonymizedOrder, err := anonymizer.Message([]byte(testMessage))
+	assert.Nil(t, err)
+	anonymizedQuery, err := anonymizer.Message([]byte(query))
+	assert.Nil(t, err)
+	otherKey, err := NewAnonymizer([]byte("other key"), 0).Message([]byte(query))
+	assert.Nil(t, err)
+	// Assert
+	pseudonym := anonymizer.Pseudonym("SMP-1")
+	assert.Len(t, pseudonym, PseudonymLength)
+	assert.Contains(t, string(anonymizedOrder), "O|1|"+pseudonym+"|")
+	assert.Equal(t, "H|\\^&\nQ|1|^"+pseudonym+"||ALL\nL|1|N", string(anonymizedQuery))
+	assert.NotContains(t, string(otherKey), pseudonym)
+}
+
+func TestMessage_KeepsUnchangedLines(t *testing.T) {
+	// Arrange
+	anonymizer := NewAnonymizer(testKey, 0)
+	anonymizer.Rules = Rules{"P-6": anonymizationaction.Pseudonymize}
+	message := "H|\\^&|||Sender\nP|1||||\nO|1|SMP-1||^^^TSH&^1|||||\nL|1|N"
+	// Act
+	result, err := anonymizer.Message([]byte(message))
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, message, string(result))
+}
+
+func TestMessage_CustomDelimiters(t *testing.T) {
+	// Arrange
+	anonymizer := NewAnonymizer(testKey, 1)
+	message := "H!*$%\nP!1!!!!Doe$John!!19800115\nL!1!N"
+	// Act
+	result, err := anonymizer.Message([]byte(message))
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "H!*$%\nP!1!!!!"+anonymizer.Pseudonym("Doe")+"$"+anonymizer.Pseudonym("John")+"!!19800116\nL!1!N", string(result))
+}
+
+func TestMessage_ComponentRuleAndClear(t *testing.T) {
+	// Arrange
+	anonymizer := NewAnonymizer(testKey, 0)
+	anonymizer.Rules = Rules{
+		"P-6.1": anonymizationaction.Pseudonymize,
+		"P-11":  anonymizationaction.Clear,
+	}
+	message := "H|\\^&\nP|1||||Doe^John\\Roe^Jane|||||Main Street 1^Berlin\nL|1|N"
+	// Act
+	result, err := anonymizer.Message([]byte(message))
+	// Assert
+	assert.Nil(t, err)
+	lines := strings.Split(string(result), "\n")
+	assert.Equal(t, "P|1||||"+anonymizer.Pseudonym("Doe")+"^John\\"+anonymizer.Pseudonym("Roe")+"^Jane|||||^", lines[1])
+}
+
+func TestRecords_DoesNotChangeInput(t *testing.T) {
+	// Arrange
+	records, err := astm.UnmarshalGeneric([]byte(testMessage))
+	assert.Nil(t, err)
+	anonymizer := NewAnonymizer(testKey, 0)
+	// Act
+	result, err := anonymizer.Records(records)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "PAT-100", records[1].Value(3, 1, 1))
+	assert.Equal(t, anonymizer.Pseudonym("PAT-100"), result[1].Value(3, 1, 1))
+	assert.Equal(t, anonymizer.Pseudonym("John"), result[1].Value(6, 1, 2))
+	assert.Equal(t, records[3], result[3])
+}
+
+func TestStruct_ResultMessage(t *testing.T) {
+	// Arrange
+	config := astm.NewDefaultConfiguration()
+	config.PreserveRawRecords = true
+	var message lis02a2.ResultMessage
+	err := astm.Unmarshal([]byte(testMessage), &message, config)
+	assert.Nil(t, err)
+	anonymizer := NewAnonymizer(testKey, 365)
+	anonymizer.Configuration = config
+	// Act
+	err = anonymizer.Struct(&message)
+	// Assert
+	assert.Nil(t, err)
+	patient := message.PatientGroups[0].Patient
+	assert.Equal(t, anonymizer.Pseudonym("Doe"), patient.LastName)
+	assert.Equal(t, anonymizer.Pseudonym("LAB-100"), patient.LabAssignedPatientID)
+	assert.Equal(t, 1981, patient.DOB.Year())
+	assert.Equal(t, "M", patient.Gender)
+	// The original lines are dropped, they would keep the original values
+	assert.Equal(t, astmmodels.RawRecord{}, patient.Raw)
+	orderGroup := message.PatientGroups[0].OrderGroups[0]
+	assert.Equal(t, anonymizer.Pseudonym("SMP-1"), orderGroup.Order.SpecimenID)
+	assert.Equal(t, time.Date(2025, 6, 25, 6, 0, 0, 0, time.UTC), orderGroup.Order.SpecimenCollectionDateTime)
+	assert.Equal(t, "2.35", orderGroup.ResultGroups[0].Result.DataMeasurementValue)
+	assert.Equal(t, lis02a2.ResultStatusFinal, orderGroup.ResultGroups[0].Result.ResultStatus)
+}
+
+func TestStruct_InvalidTarget(t *testing.T) {
+	// Arrange
+	var message lis02a2.ResultMessage
+	// Act
+	err := NewAnonymizer(testKey, 0).Struct(message)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrAnnotationParsingInvalidInputStruct)
+}
+
+func TestMessage_MissingKey(t *testing.T) {
+	// Act
+	_, err := NewAnonymizer(nil, 0).Message([]byte(testMessage))
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrAnonymizationMissingKey)
+}
+
+func TestShiftDate(t *testing.T) {
+	// Arrange
+	dates := map[string]string{
+		"20240301":       "20240229",
+		"240301101500":   "240229101500",
+		"20240331023000": "20240330023000",
+		"2024-03-01":     "",
+		"2024030":        "",
+		"20241301":       "",
+	}
+	for date, expected := range dates {
+		// Act
+		result := shiftDate(date, -1)
+		// Assert
+		assert.Equal(t, expected, result, date)
+	}
+}
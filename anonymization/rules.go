@@ -0,0 +1,88 @@
+package anonymization
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/krendel52/go-astm/v3/enums/anonymizationaction"
+	"github.com/krendel52/go-astm/v3/errmsg"
+)
+
+// Rules of the anonymization: the record positions ("P-6" or "P-6.1") with their action (see anonymizationaction)
+// A position without component applies to all the components of all the repeats of the field
+type Rules map[string]string
+
+// DefaultRules covers the patient identification of the LIS02-A2 records and the dates related to the patient
+func DefaultRules() Rules {
+	return Rules{
+		// Patient identification
+		"P-3":  anonymizationaction.Pseudonymize, // practice assigned patient ID
+		"P-4":  anonymizationaction.Pseudonymize, // laboratory assigned patient ID
+		"P-5":  anonymizationaction.Pseudonymize, // patient ID No. 3
+		"P-6":  anonymizationaction.Pseudonymize, // patient name
+		"P-7":  anonymizationaction.Pseudonymize, // mother's maiden name
+		"P-8":  anonymizationaction.ShiftDate,    // birthdate
+		"P-11": anonymizationaction.Pseudonymize, // patient address
+		"P-13": anonymizationaction.Pseudonymize, // patient telephone
+		// Specimen identification, also in the queries (patient ID^specimen ID)
+		"O-3": anonymizationaction.Pseudonymize, // specimen ID
+		"O-4": anonymizationaction.Pseudonymize, // instrument specimen ID
+		"Q-3": anonymizationaction.Pseudonymize, // starting range ID
+		"Q-4": anonymizationaction.Pseudonymize, // ending range ID
+		// Dates
+		"H-14": anonymizationaction.ShiftDate, // message date and time
+		"O-7":  anonymizationaction.ShiftDate, // requested date and time
+		"O-8":  anonymizationaction.ShiftDate, // specimen collection date and time
+		"O-9":  anonymizationaction.ShiftDate, // collection end time
+		"O-23": anonymizationaction.ShiftDate, // date and time results reported
+		"R-12": anonymizationaction.ShiftDate, // date and time test started
+		"R-13": anonymizationaction.ShiftDate, // date and time test completed
+	}
+}
+
+// Parsed rule (the component is 0 if the whole field is anonymized)
+type rule struct {
+	recordType string
+	field      int
+	component  int
+	action     string
+}
+
+// Parse and check every rule, grouped by record type and sorted by position for a deterministic order
+func (r Rules) parse() (rules map[string][]rule, err error) {
+	rules = make(map[string][]rule)
+	for position, action := range r {
+		if action != anonymizationaction.Pseudonymize && action != anonymizationaction.ShiftDate && action != anonymizationaction.Clear {
+			return nil, fmt.Errorf("%w: %q", errmsg.ErrAnonymizationUnknownAction, action)
+		}
+		recordType, fieldPosition, found := strings.Cut(position, "-")
+		if !found || recordType == "" {
+			return nil, fmt.Errorf("%w: %q", errmsg.ErrAnonymizationInvalidRule, position)
+		}
+		fieldPart, componentPart, hasComponent := strings.Cut(fieldPosition, ".")
+		field, err := strconv.Atoi(fieldPart)
+		// The record type and the sequence number (or the header delimiters) are kept
+		if err != nil || field < 3 {
+			return nil, fmt.Errorf("%w: %q", errmsg.ErrAnonymizationInvalidRule, position)
+		}
+		component := 0
+		if hasComponent {
+			component, err = strconv.Atoi(componentPart)
+			if err != nil || component < 1 {
+				return nil, fmt.Errorf("%w: %q", errmsg.ErrAnonymizationInvalidRule, position)
+			}
+		}
+		rules[recordType] = append(rules[recordType], rule{recordType: recordType, field: field, component: component, action: action})
+	}
+	for _, recordRules := range rules {
+		sort.Slice(recordRules, func(i, j int) bool {
+			if recordRules[i].field != recordRules[j].field {
+				return recordRules[i].field < recordRules[j].field
+			}
+			return recordRules[i].component < recordRules[j].component
+		})
+	}
+	return rules, nil
+}
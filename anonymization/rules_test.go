@@ -0,0 +1,53 @@
+package anonymization
+
+import (
+	"github.com/krendel52/go-astm/v3/enums/anonymizationaction"
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRulesParse_Valid(t *testing.T) {
+	// Arrange
+	rules := Rules{
+		"P-8":   anonymizationaction.ShiftDate,
+		"P-6.2": anonymizationaction.Pseudonymize,
+		"P-6.1": anonymizationaction.Pseudonymize,
+		"O-3":   anonymizationaction.Clear,
+	}
+	// Act
+	result, err := rules.parse()
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []rule{
+		{recordType: "P", field: 6, component: 1, action: anonymizationaction.Pseudonymize},
+		{recordType: "P", field: 6, component: 2, action: anonymizationaction.Pseudonymize},
+		{recordType: "P", field: 8, component: 0, action: anonymizationaction.ShiftDate},
+	}, result["P"])
+	assert.Len(t, result["O"], 1)
+}
+
+func TestRulesParse_InvalidPositions(t *testing.T) {
+	// Arrange
+	positions := []string{"P6", "-6", "P-x", "P-2", "P-6.0", "P-6.x"}
+	for _, position := range positions {
+		// Act
+		_, err := Rules{position: anonymizationaction.Clear}.parse()
+		// Assert
+		assert.ErrorIs(t, err, errmsg.ErrAnonymizationInvalidRule, position)
+	}
+}
+
+func TestRulesParse_UnknownAction(t *testing.T) {
+	// Act
+	_, err := Rules{"P-6": "HASH"}.parse()
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrAnonymizationUnknownAction)
+}
+
+func TestDefaultRules_Valid(t *testing.T) {
+	// Act
+	_, err := DefaultRules().parse()
+	// Assert
+	assert.Nil(t, err)
+}
@@ -0,0 +1,173 @@
+package astm
+
+import (
+	"strings"
+	"time"
+
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+)
+
+// Version written into the header of the built messages
+const BuilderVersion = "LIS2-A2"
+
+// Fluent builder of order messages, e.g. astm.NewOrder().Sender("LIS").Patient(p).AddTest("S1", "TSH", lis02a2.PriorityRoutine).Build()
+// The header date/time, processing ID, version and terminator are set automatically, the result can be passed to Marshal
+type OrderBuilder struct {
+	header  lis02a2.Header
+	message lis02a2.OrderMessage
+}
+
+func NewOrder() *OrderBuilder {
+	return &OrderBuilder{header: newBuilderHeader()}
+}
+
+func (b *OrderBuilder) Sender(senderNameOrID string) *OrderBuilder {
+	b.header.SenderNameOrID = senderNameOrID
+	return b
+}
+
+func (b *OrderBuilder) Receiver(receiverID string) *OrderBuilder {
+	b.header.ReceiverID = receiverID
+	return b
+}
+
+func (b *OrderBuilder) MessageControlID(messageControlID string) *OrderBuilder {
+	b.header.MessageControlID = messageControlID
+	return b
+}
+
+func (b *OrderBuilder) ProcessingID(processingID lis02a2.ProcessingID) *OrderBuilder {
+	b.header.ProcessingID = processingID
+	return b
+}
+
+// Header date and time (the time of Build if not set)
+func (b *OrderBuilder) DateTime(dateAndTime time.Time) *OrderBuilder {
+	b.header.DateAndTime = dateAndTime
+	return b
+}
+
+// Start a new patient, the following tests and orders are added to it
+func (b *OrderBuilder) Patient(patient lis02a2.Patient) *OrderBuilder {
+	b.message.PatientOrders = append(b.message.PatientOrders, lis02a2.PatientOrder{Patient: patient})
+	return b
+}
+
+// Add a new order of a single test for the specimen to the current patient
+func (b *OrderBuilder) AddTest(specimenID string, testCode string, priority lis02a2.Priority) *OrderBuilder {
+	return b.AddOrder(lis02a2.Order{
+		SpecimenID:      specimenID,
+		UniversalTestID: lis02a2.StandardUniversalTestID{ManufacturersTestType: testCode},
+		Priority:        priority,
+		ActionCode:      lis02a2.ActionCodeNew,
+		ReportType:      lis02a2.ReportTypeOrder,
+	})
+}
+
+// Add an order to the current patient (an empty patient is started if there is none yet)
+func (b *OrderBuilder) AddOrder(order lis02a2.Order) *OrderBuilder {
+	if len(b.message.PatientOrders) == 0 {
+		b.Patient(lis02a2.Patient{})
+	}
+	current := &b.message.PatientOrders[len(b.message.PatientOrders)-1]
+	current.Orders = append(current.Orders, order)
+	return b
+}
+
+// Build the message, the builder can be used again afterwards without affecting the built message
+func (b *OrderBuilder) Build() lis02a2.OrderMessage {
+	message := lis02a2.OrderMessage{
+		Header:     buildHeader(b.header),
+		Terminator: lis02a2.Terminator{TerminatorCode: lis02a2.TerminatorCodeNormal},
+	}
+	for _, patientOrder := range b.message.PatientOrders {
+		patientOrder.Orders = append([]lis02a2.Order(nil), patientOrder.Orders...)
+		message.PatientOrders = append(message.PatientOrders, patientOrder)
+	}
+	return message
+}
+
+// Fluent builder of query messages, e.g. astm.NewQuery().Sender("LIS").AddQuery("S1", "", lis02a2.RequestInformationStatusOrdersOnly).Build()
+// The header date/time, processing ID, version and terminator are set automatically, the result can be passed to Marshal
+type QueryBuilder struct {
+	header    lis02a2.Header
+	queries   []lis02a2.Query
+	component string
+}
+
+// The component delimiter of the configuration (the default one if not given) is used in the query strings
+func NewQuery(configuration ...astmmodels.Configuration) *QueryBuilder {
+	component := astmmodels.DefaultDelimiters.Component
+	if len(configuration) > 0 && configuration[0].Delimiters.Component != "" {
+		component = configuration[0].Delimiters.Component
+	}
+	return &QueryBuilder{header: newBuilderHeader(), component: component}
+}
+
+func (b *QueryBuilder) Sender(senderNameOrID string) *QueryBuilder {
+	b.header.SenderNameOrID = senderNameOrID
+	return b
+}
+
+func (b *QueryBuilder) Receiver(receiverID string) *QueryBuilder {
+	b.header.ReceiverID = receiverID
+	return b
+}
+
+func (b *QueryBuilder) MessageControlID(messageControlID string) *QueryBuilder {
+	b.header.MessageControlID = messageControlID
+	return b
+}
+
+func (b *QueryBuilder) ProcessingID(processingID lis02a2.ProcessingID) *QueryBuilder {
+	b.header.ProcessingID = processingID
+	return b
+}
+
+// Header date and time (the time of Build if not set)
+func (b *QueryBuilder) DateTime(dateAndTime time.Time) *QueryBuilder {
+	b.header.DateAndTime = dateAndTime
+	return b
+}
+
+// Add a query for the specimen (11.3 patient ID^specimen ID), an empty test code queries all tests (11.5 ^^^ALL)
+func (b *QueryBuilder) AddQuery(specimenID string, testCode string, status lis02a2.RequestInformationStatus) *QueryBuilder {
+	if testCode == "" {
+		testCode = "ALL"
+	}
+	// The query fields are plain strings, so the components are joined here
+	return b.AddQueryRecord(lis02a2.Query{
+		StartingRangeIDNumber:    b.component + specimenID,
+		UniversalTestID:          strings.Repeat(b.component, 3) + testCode,
+		RequestInformationStatus: status,
+	})
+}
+
+func (b *QueryBuilder) AddQueryRecord(query lis02a2.Query) *QueryBuilder {
+	b.queries = append(b.queries, query)
+	return b
+}
+
+// Build the message, the builder can be used again afterwards without affecting the built message
+func (b *QueryBuilder) Build() lis02a2.QueryMessage {
+	return lis02a2.QueryMessage{
+		Header:     buildHeader(b.header),
+		Queries:    append([]lis02a2.Query(nil), b.queries...),
+		Terminator: lis02a2.Terminator{TerminatorCode: lis02a2.TerminatorCodeNormal},
+	}
+}
+
+func newBuilderHeader() lis02a2.Header {
+	return lis02a2.Header{
+		ProcessingID: lis02a2.ProcessingIDProduction,
+		Version:      BuilderVersion,
+	}
+}
+
+func buildHeader(header lis02a2.Header) lis02a2.Header {
+	if header.DateAndTime.IsZero() {
+		header.DateAndTime = time.Now()
+	}
+	return header
+}
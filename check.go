@@ -0,0 +1,20 @@
+package astm
+
+import (
+	"errors"
+	"github.com/krendel52/go-astm/v3/functions"
+	"reflect"
+)
+
+// CheckType walks a message structure type and returns all the annotation mistakes joined into one error
+func CheckType(structType reflect.Type) error {
+	return errors.Join(functions.LintStruct(structType)...)
+}
+
+// MustRegister checks the annotations of a message structure and panics on any mistake
+// It is meant to be called from init functions or tests, e.g. astm.MustRegister[lis02a2.OrderMessage]()
+func MustRegister[T any]() {
+	if err := CheckType(reflect.TypeOf((*T)(nil)).Elem()); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/krendel52/go-astm/v3"
+	"github.com/krendel52/go-astm/v3/enums/jsonkeys"
+	"github.com/krendel52/go-astm/v3/enums/lineseparator"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+)
+
+// Convert an ASTM message to JSON (-to json) or a JSON message to ASTM (-to astm) using the selected structure
+func runConvert(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("convert", flag.ContinueOnError)
+	configFlags := addConfigFlags(flagSet)
+	messageFlags := addMessageFlags(flagSet)
+	target := flagSet.String("to", "json", "output format: json or astm")
+	keys := flagSet.String("keys", "names", "JSON keys of the record fields: names (Go field names) or positions (ASTM positions)")
+	generic := flagSet.Bool("generic", false, "schema-less conversion with the generic field, repeat and component layout")
+	if !parseFlags(flagSet, args, stderr) {
+		return exitUsage
+	}
+	config := astm.NewDefaultConfiguration()
+	if err := configFlags.apply(flagSet, &config); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	switch *keys {
+	case "names":
+		config.JSONKeys = jsonkeys.FieldNames
+	case "positions":
+		config.JSONKeys = jsonkeys.Positions
+	default:
+		fmt.Fprintf(stderr, "invalid json keys: %s\n", *keys)
+		return exitUsage
+	}
+	inputData, err := readInput(flagSet, stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if *generic {
+		return convertGeneric(*target, inputData, config, stdout, stderr)
+	}
+
+	switch *target {
+	case "json":
+		selected, err := messageFlags.selectForAstm(inputData, config)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		if err := configFlags.apply(flagSet, &selected.config); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		selected.config.JSONKeys = config.JSONKeys
+		if err := astm.Unmarshal(inputData, selected.message, selected.config); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitInvalid
+		}
+		output, err := astm.ToJSON(selected.message, selected.config)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitInvalid
+		}
+		writeJSON(stdout, output)
+	case "astm":
+		selected, err := messageFlags.selectForJson(config)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		if err := configFlags.apply(flagSet, &selected.config); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		selected.config.JSONKeys = config.JSONKeys
+		if err := astm.FromJSON(inputData, selected.message, selected.config); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitInvalid
+		}
+		lines, err := astm.Marshal(selected.message, selected.config)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitInvalid
+		}
+		writeLines(stdout, lines, selected.config)
+	default:
+		fmt.Fprintf(stderr, "invalid output format: %s\n", *target)
+		return exitUsage
+	}
+	return exitOk
+}
+
+// Convert without any structure: the records are kept in the generic layout
+func convertGeneric(target string, inputData []byte, config astmmodels.Configuration, stdout io.Writer, stderr io.Writer) int {
+	switch target {
+	case "json":
+		records, err := astm.UnmarshalGeneric(inputData, config)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitInvalid
+		}
+		output, err := json.Marshal(records)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitInvalid
+		}
+		writeJSON(stdout, output)
+	case "astm":
+		var records []astmmodels.GenericRecord
+		if err := json.Unmarshal(inputData, &records); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitInvalid
+		}
+		lines, err := astm.MarshalGeneric(records, config)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitInvalid
+		}
+		writeLines(stdout, lines, config)
+	default:
+		fmt.Fprintf(stderr, "invalid output format: %s\n", target)
+		return exitUsage
+	}
+	return exitOk
+}
+
+// Write the JSON indented for reading
+func writeJSON(stdout io.Writer, output []byte) {
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, output, "", "  "); err != nil {
+		indented.Reset()
+		indented.Write(output)
+	}
+	indented.WriteString("\n")
+	_, _ = stdout.Write(indented.Bytes())
+}
+
+// Write the lines with the line separator of the configuration, LF if it is auto-detected
+func writeLines(stdout io.Writer, lines [][]byte, config astmmodels.Configuration) {
+	separator := []byte(lineseparator.LF)
+	if !config.AutoDetectLineSeparator && config.LineSeparator != "" {
+		separator = []byte(config.LineSeparator)
+	}
+	_, _ = stdout.Write(append(bytes.Join(lines, separator), separator...))
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/krendel52/go-astm/v3"
+)
+
+// Compare two messages record by record (Diff), e.g. the output of an instrument before and after a firmware update
+// Like diff, the exit code is 1 if the messages are different
+func runDiff(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("diff", flag.ContinueOnError)
+	configFlags := addConfigFlags(flagSet)
+	ignore := flagSet.String("ignore", "", "comma separated positions to ignore: record types (M), fields (H-14) or components (R-4.2)")
+	if !parseFlags(flagSet, args, stderr) {
+		return exitUsage
+	}
+	config := astm.NewDefaultConfiguration()
+	if err := configFlags.apply(flagSet, &config); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if flagSet.NArg() != 2 {
+		fmt.Fprintln(stderr, "two input files have to be given")
+		return exitUsage
+	}
+	messageDataA, err := readDiffInput(flagSet.Arg(0), stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	messageDataB, err := readDiffInput(flagSet.Arg(1), stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	var ignoredPositions []string
+	if *ignore != "" {
+		ignoredPositions = strings.Split(*ignore, ",")
+	}
+
+	report, err := astm.Diff(messageDataA, messageDataB, ignoredPositions, config)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	for _, difference := range report.Differences {
+		fmt.Fprintln(stdout, difference)
+	}
+	if !report.IsEqual() {
+		return exitInvalid
+	}
+	return exitOk
+}
+
+// Read one of the compared messages, "-" is the standard input
+func readDiffInput(path string, stdin io.Reader) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(path)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/blutspende/bloodlab-common/encoding"
+	"github.com/krendel52/go-astm/v3"
+	"github.com/krendel52/go-astm/v3/functions"
+)
+
+// Print the records of the message as a table of their fields, repeats and components (no structure is needed)
+func runDump(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("dump", flag.ContinueOnError)
+	configFlags := addConfigFlags(flagSet)
+	showEmpty := flagSet.Bool("empty", false, "also print the empty fields")
+	if !parseFlags(flagSet, args, stderr) {
+		return exitUsage
+	}
+	config := astm.NewDefaultConfiguration()
+	if err := configFlags.apply(flagSet, &config); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	messageData, err := readInput(flagSet, stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	parseConfig, err := functions.LoadConfiguration(config)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	utf8Data, err := encoding.ConvertFromEncodingToUtf8(messageData, parseConfig.Encoding)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitInvalid
+	}
+	lines, err := functions.SliceLines(utf8Data, parseConfig)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitInvalid
+	}
+
+	table := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "LINE\tRECORD\tFIELD\tREPEAT\tCOMPONENT\tVALUE")
+	for i, line := range lines {
+		record, err := functions.ParseGenericLine(line, parseConfig)
+		if err != nil {
+			_ = table.Flush()
+			fmt.Fprintf(stderr, "line %d: %v\n", i+1, err)
+			return exitInvalid
+		}
+		for fieldIndex, field := range record.Fields {
+			if len(field) == 0 && *showEmpty {
+				fmt.Fprintf(table, "%d\t%s\t%d\t\t\t\n", i+1, record.Type, fieldIndex+1)
+			}
+			for repeatIndex, repeat := range field {
+				for componentIndex, component := range repeat {
+					if component == "" && !*showEmpty {
+						continue
+					}
+					// Repeat and component positions are only printed if the field has them
+					repeatPos, componentPos := "", ""
+					if len(field) > 1 {
+						repeatPos = strconv.Itoa(repeatIndex + 1)
+					}
+					if len(repeat) > 1 {
+						componentPos = strconv.Itoa(componentIndex + 1)
+					}
+					fmt.Fprintf(table, "%d\t%s\t%d\t%s\t%s\t%s\n", i+1, record.Type, fieldIndex+1, repeatPos, componentPos, component)
+				}
+			}
+		}
+	}
+	if err := table.Flush(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitInvalid
+	}
+	return exitOk
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/krendel52/go-astm/v3"
+	"github.com/krendel52/go-astm/v3/profiles"
+)
+
+// Identify the message type (IdentifyMessage) and the instrument profile of the message
+func runIdentify(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("identify", flag.ContinueOnError)
+	configFlags := addConfigFlags(flagSet)
+	if !parseFlags(flagSet, args, stderr) {
+		return exitUsage
+	}
+	config := astm.NewDefaultConfiguration()
+	if err := configFlags.apply(flagSet, &config); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	messageData, err := readInput(flagSet, stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	messageType, err := astm.IdentifyMessage(messageData, config)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitInvalid
+	}
+	fmt.Fprintf(stdout, "message type: %s\n", messageType)
+	if profile, err := profiles.Identify(messageData); err == nil {
+		fmt.Fprintf(stdout, "profile:      %s (%s %s)\n", profile.Name, profile.Manufacturer, profile.Instrument)
+	} else {
+		fmt.Fprintln(stdout, "profile:      none")
+	}
+	return exitOk
+}
@@ -0,0 +1,65 @@
+// Command astm is a troubleshooting tool for instrument captures built on the go-astm library
+//
+//	astm identify [flags] [file]
+//	astm validate [flags] [file]
+//	astm dump [flags] [file]
+//	astm convert -to json|astm [flags] [file]
+//	astm diff [flags] file1 file2
+//	astm simulate -connect|-listen address [flags] [file...]
+//
+// The message is read from the file, or from the standard input if the file is omitted or "-".
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Exit codes
+const (
+	exitOk      = 0
+	exitInvalid = 1
+	exitUsage   = 2
+)
+
+const usage = `Usage: astm <command> [flags] [file]
+
+Commands:
+  identify   identify the message type and the instrument profile
+  validate   unmarshal into a message structure and report the problems
+  dump       print the records, fields and components as a table
+  convert    convert between ASTM and JSON
+  diff       compare two messages record by record
+  simulate   simulate an instrument over TCP with LIS1-A framing
+
+Run "astm <command> -h" for the flags of a command.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+	commands := map[string]func(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int{
+		"identify": runIdentify,
+		"validate": runValidate,
+		"dump":     runDump,
+		"convert":  runConvert,
+		"diff":     runDiff,
+		"simulate": runSimulate,
+	}
+	command, exists := commands[args[0]]
+	if !exists {
+		if args[0] != "-h" && args[0] != "-help" && args[0] != "help" {
+			fmt.Fprintf(stderr, "unknown command: %s\n", args[0])
+		}
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+	return command(args[1:], stdin, stdout, stderr)
+}
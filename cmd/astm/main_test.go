@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/krendel52/go-astm/v3/enums/lis1arole"
+	"github.com/krendel52/go-astm/v3/lis1a"
+	"github.com/stretchr/testify/assert"
+)
+
+const orderExample = "../../examples/galileo/order.astm"
+
+func runCommand(stdin string, args ...string) (exitCode int, stdout string, stderr string) {
+	var outBuffer, errBuffer bytes.Buffer
+	exitCode = run(args, strings.NewReader(stdin), &outBuffer, &errBuffer)
+	return exitCode, outBuffer.String(), errBuffer.String()
+}
+
+func TestRun_UnknownCommand(t *testing.T) {
+	// Act
+	exitCode, _, stderr := runCommand("", "unknown")
+	// Assert
+	assert.Equal(t, exitUsage, exitCode)
+	assert.Contains(t, stderr, "unknown command: unknown")
+	assert.Contains(t, stderr, "Usage: astm")
+}
+
+func TestIdentify_Example(t *testing.T) {
+	// Act
+	exitCode, stdout, _ := runCommand("", "identify", orderExample)
+	// Assert
+	assert.Equal(t, exitOk, exitCode)
+	assert.Contains(t, stdout, "message type: ORDER")
+	assert.Contains(t, stdout, "immucor-galileo")
+}
+
+func TestIdentify_StandardInput(t *testing.T) {
+	// Arrange
+	message := "H|\\^&|||Unknown|\rQ|1|^SAMPLE\rL|1|N\r"
+	// Act
+	exitCode, stdout, _ := runCommand(message, "identify", "-linesep", "cr", "-")
+	// Assert
+	assert.Equal(t, exitOk, exitCode)
+	assert.Contains(t, stdout, "message type: QUERY")
+	assert.Contains(t, stdout, "profile:      none")
+}
+
+func TestValidate_PositionalError(t *testing.T) {
+	// Arrange
+	message := "H|\\^&|||LIS|\nP|1||PID\nO|2|SAMPLE\nL|1\n"
+	// Act
+	exitCode, stdout, _ := runCommand(message, "validate", "-type", "OrderMessage")
+	// Assert
+	assert.Equal(t, exitInvalid, exitCode)
+	assert.Contains(t, stdout, "line 3: sequence number mismatch")
+	assert.Contains(t, stdout, "  O|2|SAMPLE")
+}
+
+func TestValidate_UnexpectedRecord(t *testing.T) {
+	// Arrange
+	message := "H|\\^&|||LIS|\nP|1||PID\nL|1\nX|1\n"
+	// Act
+	exitCode, stdout, _ := runCommand(message, "validate", "-type", "OrderMessage")
+	// Assert
+	assert.Equal(t, exitInvalid, exitCode)
+	assert.Contains(t, stdout, "line 4: unexpected record")
+}
+
+func TestValidate_ProfileExample(t *testing.T) {
+	// Act
+	exitCode, stdout, _ := runCommand("", "validate", "../../examples/galileo/result.astm")
+	// Assert
+	assert.Equal(t, exitOk, exitCode)
+	assert.Contains(t, stdout, "structure: immucor-galileo RESULT")
+	assert.Contains(t, stdout, "valid")
+}
+
+func TestValidate_StrictFailsOnWarnings(t *testing.T) {
+	// Act
+	exitCode, stdout, _ := runCommand("", "validate", orderExample)
+	strictExitCode, strictStdout, _ := runCommand("", "validate", "-strict", orderExample)
+	// Assert
+	assert.Equal(t, exitOk, exitCode)
+	assert.Contains(t, stdout, "warning: ")
+	assert.Equal(t, exitInvalid, strictExitCode)
+	assert.NotContains(t, strictStdout, "warning: ")
+}
+
+func TestValidate_InvalidFlags(t *testing.T) {
+	// Act
+	typeExitCode, _, typeStderr := runCommand("", "validate", "-type", "Unknown", orderExample)
+	delimitersExitCode, _, delimitersStderr := runCommand("", "validate", "-delimiters", "|^", orderExample)
+	// Assert
+	assert.Equal(t, exitUsage, typeExitCode)
+	assert.Contains(t, typeStderr, "unknown message type: Unknown")
+	assert.Equal(t, exitUsage, delimitersExitCode)
+	assert.Contains(t, delimitersStderr, "invalid delimiters")
+}
+
+func TestDump_Table(t *testing.T) {
+	// Arrange
+	message := "H|\\^&|||LIS|\nR|1|^^^A\\^^^B|5\n"
+	// Act
+	exitCode, stdout, _ := runCommand(message, "dump")
+	// Assert
+	assert.Equal(t, exitOk, exitCode)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	assert.Equal(t, []string{"LINE", "RECORD", "FIELD", "REPEAT", "COMPONENT", "VALUE"}, strings.Fields(lines[0]))
+	assert.Contains(t, lines, "1     H       2                         \\^&")
+	assert.Equal(t, []string{"2", "R", "3", "1", "4", "A"}, strings.Fields(lines[6]))
+	assert.Equal(t, []string{"2", "R", "3", "2", "4", "B"}, strings.Fields(lines[7]))
+	assert.Equal(t, []string{"2", "R", "4", "5"}, strings.Fields(lines[8]))
+}
+
+func TestConvert_AstmToJsonAndBack(t *testing.T) {
+	// Arrange
+	original, err := os.ReadFile(orderExample)
+	assert.Nil(t, err)
+	jsonFile := filepath.Join(t.TempDir(), "order.json")
+	// Act
+	exitCode, jsonOutput, _ := runCommand("", "convert", "-to", "json", orderExample)
+	assert.Nil(t, os.WriteFile(jsonFile, []byte(jsonOutput), 0o600))
+	backExitCode, astmOutput, _ := runCommand("", "convert", "-to", "astm", "-profile", "immucor-galileo", "-message", "order", jsonFile)
+	// Assert
+	assert.Equal(t, exitOk, exitCode)
+	assert.True(t, json.Valid([]byte(jsonOutput)))
+	assert.Contains(t, jsonOutput, `"SenderNameOrID": "LIS"`)
+	assert.Equal(t, exitOk, backExitCode)
+	// Check the records are the same (the trailing empty fields are not kept in the input)
+	originalLines := strings.Split(strings.TrimSpace(string(original)), "\n")
+	convertedLines := strings.Split(strings.TrimSpace(astmOutput), "\n")
+	assert.Len(t, convertedLines, len(originalLines))
+	for i := range originalLines {
+		assert.Equal(t, strings.TrimRight(strings.TrimSpace(originalLines[i]), "|"), strings.TrimRight(convertedLines[i], "|"))
+	}
+}
+
+func TestConvert_JsonNeedsStructure(t *testing.T) {
+	// Act
+	exitCode, _, stderr := runCommand("{}", "convert", "-to", "astm")
+	// Assert
+	assert.Equal(t, exitUsage, exitCode)
+	assert.Contains(t, stderr, "JSON input needs -type")
+}
+
+func TestConvert_PositionKeys(t *testing.T) {
+	// Act
+	exitCode, stdout, _ := runCommand("", "convert", "-keys", "positions", orderExample)
+	// Assert
+	assert.Equal(t, exitOk, exitCode)
+	assert.Contains(t, stdout, `"3": "0651439A"`)
+}
+
+func TestConvert_Generic(t *testing.T) {
+	// Arrange
+	message := "H|\\^&|||LIS|\nR|1|^^^A&^B|5\nL|1|N\n"
+	// Act
+	exitCode, jsonOutput, _ := runCommand(message, "convert", "-generic")
+	backExitCode, astmOutput, _ := runCommand(jsonOutput, "convert", "-generic", "-to", "astm")
+	// Assert
+	assert.Equal(t, exitOk, exitCode)
+	assert.Contains(t, jsonOutput, `"type": "R"`)
+	assert.Equal(t, exitOk, backExitCode)
+	assert.Equal(t, message, astmOutput)
+}
+
+func TestDiff_Differences(t *testing.T) {
+	// Arrange
+	directory := t.TempDir()
+	fileA := filepath.Join(directory, "a.astm")
+	fileB := filepath.Join(directory, "b.astm")
+	assert.Nil(t, os.WriteFile(fileA, []byte("H|\\^&|||LIS|||||||||20240101120000\nR|3|^^^TSH|7.41\nL|1|N"), 0o600))
+	assert.Nil(t, os.WriteFile(fileB, []byte("H|\\^&|||LIS|||||||||20240202120000\nR|3|^^^TSH|7.4\nL|1|N"), 0o600))
+	// Act
+	exitCode, stdout, _ := runCommand("", "diff", fileA, fileB)
+	ignoredExitCode, ignoredStdout, _ := runCommand("", "diff", "-ignore", "H-14", fileA, fileB)
+	// Assert
+	assert.Equal(t, exitInvalid, exitCode)
+	assert.Equal(t, "H field 14.1: 20240101120000 → 20240202120000\nR|3 field 4.1: 7.41 → 7.4\n", stdout)
+	assert.Equal(t, exitInvalid, ignoredExitCode)
+	assert.Equal(t, "R|3 field 4.1: 7.41 → 7.4\n", ignoredStdout)
+}
+
+func TestDiff_EqualWithStandardInput(t *testing.T) {
+	// Arrange
+	message, err := os.ReadFile(orderExample)
+	assert.Nil(t, err)
+	// Act
+	exitCode, stdout, _ := runCommand(string(message), "diff", "-", orderExample)
+	// Assert
+	assert.Equal(t, exitOk, exitCode)
+	assert.Equal(t, "", stdout)
+}
+
+func TestDiff_Usage(t *testing.T) {
+	// Act
+	exitCode, _, stderr := runCommand("", "diff", orderExample)
+	// Assert
+	assert.Equal(t, exitUsage, exitCode)
+	assert.Contains(t, stderr, "two input files")
+}
+
+func TestSimulate_ConnectReplaysAndResponds(t *testing.T) {
+	// Arrange
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+	type commandResult struct {
+		exitCode int
+		stdout   string
+	}
+	done := make(chan commandResult, 1)
+	go func() {
+		exitCode, stdout, _ := runCommand("", "simulate", "-connect", listener.Addr().String(), "-values", "TSH=7.41", orderExample)
+		done <- commandResult{exitCode: exitCode, stdout: stdout}
+	}()
+	conn, err := listener.Accept()
+	assert.Nil(t, err)
+	lis := lis1a.NewConn(conn, lis1arole.Computer)
+	order := "H|\\^&|||LIS\nP|1\nO|1|S1||^^^TSH\nL|1|N"
+	// Act
+	replayed, errReplayed := lis.Receive()
+	errSend := lis.Send(bytes.Split([]byte(order), []byte("\n")))
+	results, errResults := lis.Receive()
+	lis.Close()
+	result := <-done
+	// Assert
+	assert.Nil(t, errReplayed)
+	assert.Equal(t, "H|\\^&|||LIS|||||Echo|||LIS2-A2|20050222140243", string(replayed[0]))
+	assert.Nil(t, errSend)
+	assert.Nil(t, errResults)
+	assert.True(t, strings.HasPrefix(string(results[3]), "R|1|^^^TSH^^^|7.41^^|"))
+	assert.Equal(t, exitOk, result.exitCode)
+	assert.Equal(t, "received:\n"+order+"\n\n", result.stdout)
+}
+
+func TestSimulate_Usage(t *testing.T) {
+	// Act
+	exitCode, _, stderr := runCommand("", "simulate", orderExample)
+	valuesExitCode, _, valuesStderr := runCommand("", "simulate", "-connect", "localhost:1", "-values", "TSH", orderExample)
+	// Assert
+	assert.Equal(t, exitUsage, exitCode)
+	assert.Contains(t, stderr, "either -connect or -listen")
+	assert.Equal(t, exitUsage, valuesExitCode)
+	assert.Contains(t, valuesStderr, "invalid result value")
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blutspende/bloodlab-common/encoding"
+	"github.com/blutspende/bloodlab-common/messagetype"
+	"github.com/blutspende/bloodlab-common/timezone"
+	"github.com/krendel52/go-astm/v3"
+	"github.com/krendel52/go-astm/v3/enums/lineseparator"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+	"github.com/krendel52/go-astm/v3/profiles"
+)
+
+// Flags mapping onto the Configuration, shared by all the commands
+type configFlags struct {
+	encoding      string
+	lineSeparator string
+	delimiters    string
+	timeZone      string
+}
+
+func addConfigFlags(flagSet *flag.FlagSet) *configFlags {
+	flags := &configFlags{}
+	flagSet.StringVar(&flags.encoding, "encoding", string(astmmodels.DefaultConfiguration.Encoding), "character encoding of the message (e.g. UTF8, ISO8859-1, Windows1252)")
+	flagSet.StringVar(&flags.lineSeparator, "linesep", "auto", "line separator: auto, lf, cr, crlf or lfcr")
+	flagSet.StringVar(&flags.delimiters, "delimiters", "", "field, repeat, component and escape delimiters for the output (e.g. |\\^&)")
+	flagSet.StringVar(&flags.timeZone, "timezone", string(astmmodels.DefaultConfiguration.TimeZone), "time zone of the dates in the message")
+	return flags
+}
+
+var lineSeparators = map[string]string{
+	"lf":   lineseparator.LF,
+	"cr":   lineseparator.CR,
+	"crlf": lineseparator.CRLF,
+	"lfcr": lineseparator.LFCR,
+}
+
+// Apply the flags onto the configuration, only the explicitly set ones are applied (a profile's configuration is kept otherwise)
+func (f *configFlags) apply(flagSet *flag.FlagSet, config *astmmodels.Configuration) (err error) {
+	flagSet.Visit(func(setFlag *flag.Flag) {
+		if err != nil {
+			return
+		}
+		switch setFlag.Name {
+		case "encoding":
+			config.Encoding = encoding.Encoding(f.encoding)
+		case "linesep":
+			if f.lineSeparator == "auto" {
+				config.AutoDetectLineSeparator = true
+				return
+			}
+			separator, exists := lineSeparators[strings.ToLower(f.lineSeparator)]
+			if !exists {
+				err = fmt.Errorf("invalid line separator: %s", f.lineSeparator)
+				return
+			}
+			config.LineSeparator = separator
+			config.AutoDetectLineSeparator = false
+		case "delimiters":
+			if len(f.delimiters) != 4 {
+				err = fmt.Errorf("invalid delimiters: %q (4 characters are expected)", f.delimiters)
+				return
+			}
+			config.Delimiters.Field = f.delimiters[0:1]
+			config.Delimiters.Repeat = f.delimiters[1:2]
+			config.Delimiters.Component = f.delimiters[2:3]
+			config.Delimiters.Escape = f.delimiters[3:4]
+		case "timezone":
+			if _, loadErr := time.LoadLocation(f.timeZone); loadErr != nil {
+				err = fmt.Errorf("invalid time zone: %s", f.timeZone)
+				return
+			}
+			config.TimeZone = timezone.TimeZone(f.timeZone)
+		}
+	})
+	return err
+}
+
+// Flags choosing the message structure: a lis02a2 message type or an instrument profile
+type messageFlags struct {
+	messageType string
+	profile     string
+	message     string
+}
+
+func addMessageFlags(flagSet *flag.FlagSet) *messageFlags {
+	flags := &messageFlags{}
+	flagSet.StringVar(&flags.messageType, "type", "", "lis02a2 message structure: "+strings.Join(messageTypeNames(), ", "))
+	flagSet.StringVar(&flags.profile, "profile", "", "instrument profile: "+strings.Join(profileNames(), ", "))
+	flagSet.StringVar(&flags.message, "message", "", "message type of the profile if it can not be identified (RESULT, ORDER or QUERY)")
+	return flags
+}
+
+// Message structures of the lis02a2 format selectable with the -type flag
+var messageTypes = map[string]func() interface{}{
+	"OrderMessage":           func() interface{} { return &lis02a2.OrderMessage{} },
+	"ResultMessage":          func() interface{} { return &lis02a2.ResultMessage{} },
+	"ResultMultiMessage":     func() interface{} { return &lis02a2.ResultMultiMessage{} },
+	"QueryMessage":           func() interface{} { return &lis02a2.QueryMessage{} },
+	"ScientificMessage":      func() interface{} { return &lis02a2.ScientificMessage{} },
+	"ScientificMultiMessage": func() interface{} { return &lis02a2.ScientificMultiMessage{} },
+}
+
+// Default lis02a2 structures of the identified message types
+var identifiedMessageTypes = map[messagetype.MessageType]string{
+	messagetype.Order:  "OrderMessage",
+	messagetype.Result: "ResultMessage",
+	messagetype.Query:  "QueryMessage",
+}
+
+func messageTypeNames() (names []string) {
+	for name := range messageTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func profileNames() (names []string) {
+	for _, profile := range profiles.All() {
+		names = append(names, profile.Name)
+	}
+	return names
+}
+
+// Selected message structure with its description for the output and its configuration (the profile's one if selected)
+// Note: the configuration flags have to be applied on the configuration again, as they override the profile's settings
+type selection struct {
+	message     interface{}
+	description string
+	config      astmmodels.Configuration
+}
+
+// Select the message structure for ASTM input: the flags take precedence, otherwise the profile and the type are identified
+func (f *messageFlags) selectForAstm(messageData []byte, config astmmodels.Configuration) (result selection, err error) {
+	if f.messageType != "" && f.profile != "" {
+		return result, errors.New("-type and -profile can not be used together")
+	}
+	if f.messageType != "" {
+		return f.selectType(f.messageType, config)
+	}
+	var profile profiles.Profile
+	if f.profile != "" {
+		var exists bool
+		if profile, exists = profiles.Get(f.profile); !exists {
+			return result, fmt.Errorf("unknown profile: %s", f.profile)
+		}
+	} else if identified, identifyErr := profiles.Identify(messageData); identifyErr == nil {
+		profile = identified
+	}
+	if profile.Name == "" {
+		// No profile: the lis02a2 structure of the identified message type is used
+		messageType, err := astm.IdentifyMessage(messageData, config)
+		if err != nil {
+			return result, err
+		}
+		name, exists := identifiedMessageTypes[messageType]
+		if !exists {
+			return result, fmt.Errorf("message type can not be identified: %s (use -type)", messageType)
+		}
+		return f.selectType(name, config)
+	}
+	messageType := messagetype.MessageType(strings.ToUpper(f.message))
+	if f.message == "" {
+		if messageType, err = astm.IdentifyMessage(messageData, profile.Configuration); err != nil {
+			return result, err
+		}
+	}
+	return f.selectProfile(profile, messageType)
+}
+
+// Select the message structure for JSON input: it can not be identified, so -type or -profile with -message is needed
+func (f *messageFlags) selectForJson(config astmmodels.Configuration) (result selection, err error) {
+	switch {
+	case f.messageType != "" && f.profile != "":
+		return result, errors.New("-type and -profile can not be used together")
+	case f.messageType != "":
+		return f.selectType(f.messageType, config)
+	case f.profile != "" && f.message != "":
+		profile, exists := profiles.Get(f.profile)
+		if !exists {
+			return result, fmt.Errorf("unknown profile: %s", f.profile)
+		}
+		return f.selectProfile(profile, messagetype.MessageType(strings.ToUpper(f.message)))
+	}
+	return result, errors.New("JSON input needs -type, or -profile with -message")
+}
+
+func (f *messageFlags) selectType(name string, config astmmodels.Configuration) (result selection, err error) {
+	factory, exists := messageTypes[name]
+	if !exists {
+		return result, fmt.Errorf("unknown message type: %s", name)
+	}
+	return selection{message: factory(), description: "lis02a2." + name, config: config}, nil
+}
+
+func (f *messageFlags) selectProfile(profile profiles.Profile, messageType messagetype.MessageType) (result selection, err error) {
+	message, err := profile.NewMessage(messageType)
+	if err != nil {
+		return result, err
+	}
+	return selection{message: message, description: fmt.Sprintf("%s %s", profile.Name, messageType), config: profile.Configuration}, nil
+}
+
+// Read the input file given as the only argument, or the standard input
+func readInput(flagSet *flag.FlagSet, stdin io.Reader) ([]byte, error) {
+	switch flagSet.NArg() {
+	case 0:
+		return io.ReadAll(stdin)
+	case 1:
+		if flagSet.Arg(0) == "-" {
+			return io.ReadAll(stdin)
+		}
+		return os.ReadFile(flagSet.Arg(0))
+	}
+	return nil, errors.New("only one input file can be given")
+}
+
+// Parse the flags of a command, the usage is printed on error
+func parseFlags(flagSet *flag.FlagSet, args []string, stderr io.Writer) bool {
+	flagSet.SetOutput(stderr)
+	return flagSet.Parse(args) == nil
+}
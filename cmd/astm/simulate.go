@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/krendel52/go-astm/v3"
+	"github.com/krendel52/go-astm/v3/lis1a"
+	"github.com/krendel52/go-astm/v3/simulator"
+)
+
+// Simulate an instrument over TCP with LIS1-A framing: replay the given messages, then print the received ones
+// It connects to the LIS (-connect) or waits for the connections of the LIS (-listen) until interrupted
+func runSimulate(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	configFlags := addConfigFlags(flagSet)
+	connect := flagSet.String("connect", "", "address of the LIS to connect to (e.g. localhost:4001)")
+	listen := flagSet.String("listen", "", "address to wait for the connections of the LIS on (e.g. :4001)")
+	once := flagSet.Bool("once", false, "stop after the first connection in listen mode")
+	respond := flagSet.Bool("respond", false, "answer the orders with results")
+	values := flagSet.String("values", "", "comma separated result values by test code for -respond (e.g. TSH=7.41,FT4=12.1)")
+	contentionFile := flagSet.String("contention-message", "", "file of the message sent after a contention (default is the last sent message)")
+	var faults lis1a.Faults
+	flagSet.IntVar(&faults.BadChecksums, "bad-checksums", 0, "number of frames sent with a wrong checksum")
+	flagSet.IntVar(&faults.NAKs, "naks", 0, "number of received frames answered with NAK")
+	flagSet.IntVar(&faults.Timeouts, "timeouts", 0, "number of received frames left unanswered")
+	flagSet.IntVar(&faults.DroppedEOTs, "dropped-eots", 0, "number of transfers not terminated with EOT")
+	flagSet.IntVar(&faults.Contentions, "contentions", 0, "number of ENQs of the LIS answered with ENQ")
+	flagSet.DurationVar(&faults.ReplyDelay, "reply-delay", 0, "delay before answering ENQs and frames (e.g. 20s to cause timeouts)")
+	if !parseFlags(flagSet, args, stderr) {
+		return exitUsage
+	}
+	config := astm.NewDefaultConfiguration()
+	if err := configFlags.apply(flagSet, &config); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if (*connect == "") == (*listen == "") {
+		fmt.Fprintln(stderr, "either -connect or -listen has to be given")
+		return exitUsage
+	}
+	resultValues, err := parseResultValues(*values)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	var messages [][]byte
+	for _, path := range flagSet.Args() {
+		message, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		messages = append(messages, message)
+	}
+
+	instrument := simulator.New(messages...)
+	instrument.Configuration = config
+	instrument.Faults = faults
+	if *contentionFile != "" {
+		if instrument.ContentionMessage, err = os.ReadFile(*contentionFile); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+	if *respond || *values != "" {
+		instrument.Responder = simulator.ResultResponder(resultValues, config)
+	}
+	instrument.OnReceive = func(message []byte) {
+		fmt.Fprintf(stdout, "received:\n%s\n\n", message)
+	}
+	instrument.ErrorHandler = func(err error) {
+		fmt.Fprintln(stderr, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *connect != "" {
+		err = instrument.Dial(ctx, *connect)
+	} else {
+		err = serve(ctx, instrument, *listen, *once)
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		fmt.Fprintln(stderr, err)
+		return exitInvalid
+	}
+	return exitOk
+}
+
+func serve(ctx context.Context, instrument *simulator.Simulator, address string, once bool) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	if !once {
+		return instrument.Serve(ctx, listener)
+	}
+	conn, err := listener.Accept()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return instrument.Run(ctx, conn)
+}
+
+// Parse the result values, e.g. TSH=7.41,FT4=12.1
+func parseResultValues(values string) (map[string]string, error) {
+	resultValues := make(map[string]string)
+	if values == "" {
+		return resultValues, nil
+	}
+	for _, value := range strings.Split(values, ",") {
+		testCode, result, found := strings.Cut(value, "=")
+		if !found || testCode == "" {
+			return nil, fmt.Errorf("invalid result value: %q (TEST=VALUE is expected)", value)
+		}
+		resultValues[testCode] = result
+	}
+	return resultValues, nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/blutspende/bloodlab-common/encoding"
+	"github.com/krendel52/go-astm/v3"
+	"github.com/krendel52/go-astm/v3/functions"
+)
+
+// Unmarshal the message into the selected structure and print the problems with their positions
+// The parsing stops at the first error (reported with its line), then the unmarshalled structure is checked with Validate
+// The problems found by Validate (e.g. unescaped delimiters) only affect marshalling it again, so they are warnings unless strict
+func runValidate(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("validate", flag.ContinueOnError)
+	configFlags := addConfigFlags(flagSet)
+	messageFlags := addMessageFlags(flagSet)
+	strict := flagSet.Bool("strict", false, "also fail on the problems found by Validate (otherwise they are warnings)")
+	if !parseFlags(flagSet, args, stderr) {
+		return exitUsage
+	}
+	config := astm.NewDefaultConfiguration()
+	if err := configFlags.apply(flagSet, &config); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	messageData, err := readInput(flagSet, stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	selected, err := messageFlags.selectForAstm(messageData, config)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if err := configFlags.apply(flagSet, &selected.config); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	fmt.Fprintf(stdout, "structure: %s\n", selected.description)
+
+	// Unmarshal step by step to know the position of the error
+	parseConfig, err := functions.LoadConfiguration(selected.config)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	utf8Data, err := encoding.ConvertFromEncodingToUtf8(messageData, parseConfig.Encoding)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitInvalid
+	}
+	lines, err := functions.SliceLines(utf8Data, parseConfig)
+	if err != nil {
+		fmt.Fprintln(stdout, err)
+		return exitInvalid
+	}
+	lineIndex := 0
+	err = functions.ParseStruct(lines, selected.message, &lineIndex, 1, 0, parseConfig)
+	if err != nil {
+		// The line index is already past the failing line (no line is failing if the structure itself is invalid)
+		if lineIndex == 0 {
+			fmt.Fprintln(stdout, err)
+			return exitInvalid
+		}
+		lineIndex = min(lineIndex, len(lines))
+		fmt.Fprintf(stdout, "line %d: %v\n", lineIndex, err)
+		fmt.Fprintf(stdout, "  %s\n", lines[lineIndex-1])
+		return exitInvalid
+	}
+	valid := true
+	// The lines not matching the structure are ignored by Unmarshal
+	for ; lineIndex < len(lines); lineIndex++ {
+		fmt.Fprintf(stdout, "line %d: unexpected record\n", lineIndex+1)
+		fmt.Fprintf(stdout, "  %s\n", lines[lineIndex])
+		valid = false
+	}
+
+	// Check the unmarshalled structure
+	report, err := astm.Validate(selected.message, selected.config)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitInvalid
+	}
+	for _, issue := range report.Issues {
+		if *strict {
+			fmt.Fprintln(stdout, issue.Error())
+			valid = false
+		} else {
+			fmt.Fprintf(stdout, "warning: %s\n", issue.Error())
+		}
+	}
+	if !valid {
+		return exitInvalid
+	}
+	fmt.Fprintln(stdout, "valid")
+	return exitOk
+}
@@ -4,8 +4,19 @@ package constants
 const MaxDepth int = 42
 
 // Attributes for annotations
-const AttributeRequired string = "required" // field-annotation: by default all fields are optinal
-const AttributeOptional string = "optional" // record-annotation: by default all records are mandatory
-const AttributeLongdate string = "longdate" // Indicating that the date should be formatted as date and time (output only)
-const AttributeLength string = "length"     // used for specifying the decimal length of float fields - astm:"1,length:2" (output only)
-const AttributeSubname string = "subname"   // used for specifying a subname for a record - astm:"M,subname:MATRIX"
+const AttributeRequired string = "required"     // field-annotation: by default all fields are optinal
+const AttributeOptional string = "optional"     // record-annotation: by default all records are mandatory
+const AttributeLongdate string = "longdate"     // Indicating that the date should be formatted as date and time (output only)
+const AttributeLength string = "length"         // used for specifying the decimal length of float fields - astm:"1,length:2" (output only)
+const AttributeSubname string = "subname"       // used for specifying a subname for a record - astm:"M,subname:MATRIX"
+const AttributeOmitempty string = "omitempty"   // zero values (0, 0.0, zero time) are written as empty fields - astm:"3,omitempty" (output only)
+const AttributeDefault string = "default"       // value used for empty fields in both directions - astm:"3,default:N"
+const AttributeMaxlen string = "maxlen"         // maximum number of characters of string fields - astm:"3,maxlen:20" (output only)
+const AttributeTrim string = "trim"             // leading and trailing whitespace is removed from string fields (e.g. vendor padding)
+const AttributeUpper string = "upper"           // string fields are converted to upper case
+const AttributeCharset string = "charset"       // allowed characters of string fields (ascii or printable) - astm:"3,charset:ascii"
+const AttributeDelimiters string = "delimiters" // header field capturing the delimiters (astmmodels.Delimiters type) - astm:"2,delimiters"
+
+// Sensible date range for validation (inclusive years)
+const ValidationMinYear int = 1900
+const ValidationMaxYear int = 2099
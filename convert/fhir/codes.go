@@ -0,0 +1,91 @@
+package fhir
+
+import (
+	"regexp"
+
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+)
+
+// Code systems used in the exported resources
+const (
+	SystemLOINC                     = "http://loinc.org"
+	SystemObservationInterpretation = "http://terminology.hl7.org/CodeSystem/v3-ObservationInterpretation"
+	SystemObservationCategory       = "http://terminology.hl7.org/CodeSystem/observation-category"
+	SystemDiagnosticServiceSection  = "http://terminology.hl7.org/CodeSystem/v2-0074"
+)
+
+// CodeMapper turns the universal test ID of an order or a result into a coding (e.g. LOINC)
+// The orders only have the first 4 components of the universal test ID set
+type CodeMapper func(testID lis02a2.ExtendedUniversalTestID) (coding Coding, found bool)
+
+// Code mapping table keyed by the manufacturer's test type (e.g. TSH), or the universal test ID if there is no test type
+type CodeTable map[string]Coding
+
+// Lookup can be used as CodeMapper
+func (t CodeTable) Lookup(testID lis02a2.ExtendedUniversalTestID) (coding Coding, found bool) {
+	key := testID.ManufacturersTestType
+	if key == "" {
+		key = testID.UniversalTestID
+	}
+	coding, found = t[key]
+	return coding, found
+}
+
+// LOINC code format: up to 7 digits and the check digit
+var regexLOINC = regexp.MustCompile(`^\d{1,7}-\d$`)
+
+// LOINCFromTestName is a CodeMapper for the instruments sending the LOINC code as manufacturer's test name (e.g. Yumizen ^^^MCV^787-2)
+func LOINCFromTestName(testID lis02a2.ExtendedUniversalTestID) (coding Coding, found bool) {
+	if !regexLOINC.MatchString(testID.ManufacturersTestName) {
+		return Coding{}, false
+	}
+	return Coding{System: SystemLOINC, Code: testID.ManufacturersTestName}, true
+}
+
+// Interpretations of the abnormal flags, the LIS02-A2 flags are the same as the HL7 v3 codes
+var interpretationDisplays = map[lis02a2.ResultAbnormalFlag]string{
+	lis02a2.ResultAbnormalFlagLow:               "Low",
+	lis02a2.ResultAbnormalFlagHigh:              "High",
+	lis02a2.ResultAbnormalFlagPanicLow:          "Critical low",
+	lis02a2.ResultAbnormalFlagPanicHigh:         "Critical high",
+	lis02a2.ResultAbnormalFlagBelowAbsoluteLow:  "Off scale low",
+	lis02a2.ResultAbnormalFlagAboveAbsoluteHigh: "Off scale high",
+	lis02a2.ResultAbnormalFlagNormal:            "Normal",
+	lis02a2.ResultAbnormalFlagAbnormal:          "Abnormal",
+	lis02a2.ResultAbnormalFlagVeryAbnormal:      "Critical abnormal",
+	lis02a2.ResultAbnormalFlagSignificantlyUp:   "Significant change up",
+	lis02a2.ResultAbnormalFlagSignificantlyDown: "Significant change down",
+	lis02a2.ResultAbnormalFlagBetter:            "Better",
+	lis02a2.ResultAbnormalFlagWorse:             "Worse",
+}
+
+// Observation statuses of the result statuses (the unknown ones are "unknown")
+var observationStatuses = map[lis02a2.ResultStatus]string{
+	lis02a2.ResultStatusCorrection:    "corrected",
+	lis02a2.ResultStatusPreliminary:   "preliminary",
+	lis02a2.ResultStatusFinal:         "final",
+	lis02a2.ResultStatusCancelled:     "cancelled",
+	lis02a2.ResultStatusPending:       "registered",
+	lis02a2.ResultStatusPartial:       "preliminary",
+	lis02a2.ResultStatusMIC:           "final",
+	lis02a2.ResultStatusPrevious:      "final",
+	lis02a2.ResultStatusNew:           "final",
+	lis02a2.ResultStatusQueryResponse: "final",
+	lis02a2.ResultStatusVerified:      "final",
+	lis02a2.ResultStatusWarning:       "preliminary",
+}
+
+// Request priorities of the order priorities (callback and preoperative have no counterpart)
+var requestPriorities = map[lis02a2.Priority]string{
+	lis02a2.PriorityStat:    "stat",
+	lis02a2.PriorityASAP:    "asap",
+	lis02a2.PriorityRoutine: "routine",
+}
+
+// Administrative genders of the patient sex codes
+var genders = map[string]string{
+	"M": "male",
+	"F": "female",
+	"U": "unknown",
+	"O": "other",
+}
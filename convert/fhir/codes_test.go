@@ -0,0 +1,36 @@
+package fhir
+
+import (
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCodeTable_Lookup(t *testing.T) {
+	// Arrange
+	table := CodeTable{"TSH": tshCoding, "1234": {Code: "1234-5"}}
+	// Act
+	byTestType, foundByTestType := table.Lookup(lis02a2.ExtendedUniversalTestID{UniversalTestID: "1234", ManufacturersTestType: "TSH"})
+	byTestID, foundByTestID := table.Lookup(lis02a2.ExtendedUniversalTestID{UniversalTestID: "1234"})
+	_, foundUnknown := table.Lookup(lis02a2.ExtendedUniversalTestID{ManufacturersTestType: "FT4"})
+	// Assert
+	assert.True(t, foundByTestType)
+	assert.Equal(t, tshCoding, byTestType)
+	assert.True(t, foundByTestID)
+	assert.Equal(t, "1234-5", byTestID.Code)
+	assert.False(t, foundUnknown)
+}
+
+func TestLOINCFromTestName(t *testing.T) {
+	// Arrange
+	names := map[string]bool{"787-2": true, "32623-1": true, "Thyrotropin": false, "787": false, "787-22": false, "": false}
+	for name, expected := range names {
+		// Act
+		coding, found := LOINCFromTestName(lis02a2.ExtendedUniversalTestID{ManufacturersTestType: "MCV", ManufacturersTestName: name})
+		// Assert
+		assert.Equal(t, expected, found, name)
+		if expected {
+			assert.Equal(t, Coding{System: SystemLOINC, Code: name}, coding)
+		}
+	}
+}
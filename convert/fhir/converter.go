@@ -0,0 +1,311 @@
+package fhir
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/krendel52/go-astm/v3/functions"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+)
+
+// Diagnostic report statuses of the order report types (the other ones are "unknown")
+var reportStatuses = map[lis02a2.ReportType]string{
+	lis02a2.ReportTypeFinal:       "final",
+	lis02a2.ReportTypeCorrection:  "corrected",
+	lis02a2.ReportTypePreliminary: "preliminary",
+	lis02a2.ReportTypeCancelled:   "cancelled",
+	lis02a2.ReportTypePending:     "registered",
+}
+
+// Service request statuses of the order report types (the other ones are "active")
+var requestStatuses = map[lis02a2.ReportType]string{
+	lis02a2.ReportTypeFinal:      "completed",
+	lis02a2.ReportTypeCorrection: "completed",
+	lis02a2.ReportTypeCancelled:  "revoked",
+}
+
+// Numeric result values with an optional comparator (e.g. <0.01)
+var regexQuantity = regexp.MustCompile(`^(<=|>=|<|>)?\s*(-?\d+(?:[.,]\d+)?)$`)
+
+// Converter of the LIS02-A2 result messages into FHIR R4 bundles
+type Converter struct {
+	// Maps the universal test IDs to codings (e.g. LOINC), the unmapped tests only have a text
+	CodeMapper CodeMapper
+	// Configuration of the ASTM side, the dates are exported in its time zone
+	Configuration astmmodels.Configuration
+}
+
+func NewConverter() Converter {
+	return Converter{
+		Configuration: astmmodels.DefaultConfiguration,
+	}
+}
+
+// ResultsToBundle converts a result message into a collection bundle
+// The patient is mapped to Patient, every order to ServiceRequest, Specimen and DiagnosticReport, every result to Observation
+// The entries have name based UUIDs derived from the header, so converting the same message again gives the same bundle
+func (c Converter) ResultsToBundle(message lis02a2.ResultMessage) (bundle Bundle, err error) {
+	// Load configuration
+	config, err := functions.LoadConfiguration(c.Configuration)
+	if err != nil {
+		return Bundle{}, err
+	}
+	builder := bundleBuilder{
+		codeMapper: c.CodeMapper,
+		location:   config.TimeLocation,
+		seed:       strings.Join([]string{message.Header.MessageControlID, message.Header.SenderNameOrID, message.Header.DateAndTime.Format(time.RFC3339Nano)}, "|"),
+	}
+	builder.bundle = Bundle{
+		ResourceType: "Bundle",
+		Type:         "collection",
+		Timestamp:    builder.dateTime(message.Header.DateAndTime),
+	}
+	for i, patientGroup := range message.PatientGroups {
+		path := fmt.Sprintf("patient/%d", i+1)
+		// An empty patient record (e.g. from a quality control measurement) is not exported
+		var subject *Reference
+		if patient, exists := builder.patient(patientGroup.Patient); exists {
+			subject = &Reference{Reference: builder.add(path, patient)}
+		}
+		for j, orderGroup := range patientGroup.OrderGroups {
+			builder.order(fmt.Sprintf("%s/order/%d", path, j+1), orderGroup, subject)
+		}
+	}
+	// Return the bundle and no error if everything went well
+	return builder.bundle, nil
+}
+
+// ResultsToJSON converts a result message into the JSON of a collection bundle (see ResultsToBundle)
+func (c Converter) ResultsToJSON(message lis02a2.ResultMessage) (result []byte, err error) {
+	bundle, err := c.ResultsToBundle(message)
+	if err != nil {
+		return nil, err
+	}
+	// The comparators of the quantities (e.g. <) are not escaped
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(bundle); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// State of a conversion: the bundle built so far and the settings
+type bundleBuilder struct {
+	bundle     Bundle
+	codeMapper CodeMapper
+	location   *time.Location
+	seed       string
+}
+
+// Add a resource with a name based (version 5 style) UUID of its path in the message, the full URL is returned for the references
+func (b *bundleBuilder) add(path string, resource Resource) (fullURL string) {
+	hash := sha1.Sum([]byte(b.seed + "/" + path))
+	hash[6] = (hash[6] & 0x0f) | 0x50
+	hash[8] = (hash[8] & 0x3f) | 0x80
+	fullURL = fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", hash[0:4], hash[4:6], hash[6:8], hash[8:10], hash[10:16])
+	b.bundle.Entry = append(b.bundle.Entry, BundleEntry{FullURL: fullURL, Resource: resource})
+	return fullURL
+}
+
+func (b *bundleBuilder) patient(patient lis02a2.Patient) (result Patient, exists bool) {
+	result = Patient{ResourceType: "Patient", Gender: genders[strings.ToUpper(patient.Gender)]}
+	for _, id := range []string{patient.PracticeAssignedPatientID, patient.LabAssignedPatientID} {
+		if id != "" {
+			result.Identifier = append(result.Identifier, Identifier{Value: id})
+		}
+	}
+	if patient.LastName != "" || patient.FirstName != "" {
+		name := HumanName{Family: patient.LastName}
+		if patient.FirstName != "" {
+			name.Given = []string{patient.FirstName}
+		}
+		result.Name = []HumanName{name}
+	}
+	if !patient.DOB.IsZero() {
+		result.BirthDate = patient.DOB.In(b.location).Format(time.DateOnly)
+	}
+	exists = len(result.Identifier) > 0 || len(result.Name) > 0 || result.Gender != "" || result.BirthDate != ""
+	return result, exists
+}
+
+func (b *bundleBuilder) order(path string, orderGroup lis02a2.OrderGroup, subject *Reference) {
+	order := orderGroup.Order
+	testID := lis02a2.ExtendedUniversalTestID{
+		UniversalTestID:       order.UniversalTestID.UniversalTestID,
+		UniversalTestIDName:   order.UniversalTestID.UniversalTestIDName,
+		UniversalTestIDType:   order.UniversalTestID.UniversalTestIDType,
+		ManufacturersTestType: order.UniversalTestID.ManufacturersTestType,
+	}
+	collected := b.dateTime(order.SpecimenCollectionDateTime)
+
+	// Specimen
+	var specimens []Reference
+	var specimenReference *Reference
+	if order.SpecimenID != "" || order.SpecimenType != "" || collected != "" {
+		specimen := Specimen{ResourceType: "Specimen", Subject: subject}
+		if order.SpecimenID != "" {
+			specimen.AccessionIdentifier = &Identifier{Value: order.SpecimenID}
+		}
+		if order.SpecimenType != "" {
+			specimen.Type = &CodeableConcept{Text: order.SpecimenType}
+		}
+		if collected != "" {
+			specimen.Collection = &SpecimenCollection{CollectedDateTime: collected}
+		}
+		specimenReference = &Reference{Reference: b.add(path+"/specimen", specimen)}
+		specimens = []Reference{*specimenReference}
+	}
+
+	// Service request with the order comments
+	code := b.code(testID)
+	request := ServiceRequest{
+		ResourceType: "ServiceRequest",
+		Status:       valueOrDefault(requestStatuses[order.ReportType], "active"),
+		Intent:       "order",
+		Priority:     requestPriorities[order.Priority],
+		Subject:      subject,
+		AuthoredOn:   b.dateTime(order.RequestedOrderDateTime),
+		Specimen:     specimens,
+		Note:         notes(orderGroup.Comments),
+	}
+	if code.Text != "" || len(code.Coding) > 0 {
+		request.Code = &code
+	}
+	if order.SpecimenID != "" {
+		request.Identifier = []Identifier{{Value: order.SpecimenID}}
+	}
+	if order.OrderingPhysician != "" {
+		request.Requester = &Reference{Display: order.OrderingPhysician}
+	}
+	basedOn := []Reference{{Reference: b.add(path+"/request", request)}}
+
+	// Observations of the results
+	var results []Reference
+	for i, resultGroup := range orderGroup.ResultGroups {
+		observation := b.observation(resultGroup, subject, specimenReference, basedOn)
+		if observation.EffectiveDateTime == "" {
+			observation.EffectiveDateTime = collected
+		}
+		results = append(results, Reference{Reference: b.add(fmt.Sprintf("%s/result/%d", path, i+1), observation)})
+	}
+
+	// Diagnostic report of the order
+	report := DiagnosticReport{
+		ResourceType:      "DiagnosticReport",
+		BasedOn:           basedOn,
+		Status:            valueOrDefault(reportStatuses[order.ReportType], "unknown"),
+		Category:          []CodeableConcept{{Coding: []Coding{{System: SystemDiagnosticServiceSection, Code: "LAB", Display: "Laboratory"}}}},
+		Code:              code,
+		Subject:           subject,
+		EffectiveDateTime: collected,
+		Issued:            b.dateTime(order.DateTimeResultsReported),
+		Specimen:          specimens,
+		Result:            results,
+	}
+	if report.Code.Text == "" && len(report.Code.Coding) == 0 {
+		report.Code.Text = "Laboratory report"
+	}
+	b.add(path+"/report", report)
+}
+
+func (b *bundleBuilder) observation(resultGroup lis02a2.ResultGroup, subject *Reference, specimen *Reference, basedOn []Reference) Observation {
+	result := resultGroup.Result
+	observation := Observation{
+		ResourceType: "Observation",
+		BasedOn:      basedOn,
+		Status:       valueOrDefault(observationStatuses[result.ResultStatus], "unknown"),
+		Category:     []CodeableConcept{{Coding: []Coding{{System: SystemObservationCategory, Code: "laboratory", Display: "Laboratory"}}}},
+		Code:         b.code(result.UniversalTestID),
+		Subject:      subject,
+		Issued:       b.dateTime(result.DateTimeCompleted),
+		Specimen:     specimen,
+		Note:         notes(resultGroup.Comments),
+	}
+	// The value is a quantity if it is numeric, otherwise a string
+	if quantity, isNumeric := newQuantity(result.DataMeasurementValue, result.Units); isNumeric {
+		observation.ValueQuantity = quantity
+	} else {
+		observation.ValueString = result.DataMeasurementValue
+	}
+	if display, exists := interpretationDisplays[result.ResultAbnormalFlag]; exists {
+		observation.Interpretation = []CodeableConcept{{Coding: []Coding{{System: SystemObservationInterpretation, Code: string(result.ResultAbnormalFlag), Display: display}}}}
+	}
+	if referenceRange, exists := newReferenceRange(result.ReferenceRange, result.Units); exists {
+		observation.ReferenceRange = []ObservationReferenceRange{referenceRange}
+	}
+	if result.InstrumentIdentification != "" {
+		observation.Device = &Reference{Display: result.InstrumentIdentification}
+	}
+	return observation
+}
+
+// Code of a test with the mapped coding, the text is the manufacturer's test type (or the universal test ID)
+func (b *bundleBuilder) code(testID lis02a2.ExtendedUniversalTestID) (concept CodeableConcept) {
+	concept.Text = valueOrDefault(testID.ManufacturersTestType, testID.UniversalTestID)
+	if b.codeMapper != nil {
+		if coding, found := b.codeMapper(testID); found {
+			concept.Coding = []Coding{coding}
+		}
+	}
+	return concept
+}
+
+// FHIR dateTime in the configured location, zero times are empty
+func (b *bundleBuilder) dateTime(timeValue time.Time) string {
+	if timeValue.IsZero() {
+		return ""
+	}
+	return timeValue.In(b.location).Format(time.RFC3339)
+}
+
+func newQuantity(value string, unit string) (*Quantity, bool) {
+	matches := regexQuantity.FindStringSubmatch(strings.TrimSpace(value))
+	if matches == nil {
+		return nil, false
+	}
+	return &Quantity{Value: json.Number(strings.Replace(matches[2], ",", ".", 1)), Comparator: matches[1], Unit: unit}, true
+}
+
+// Numeric reference ranges are exported with their bounds, the other ones as text
+func newReferenceRange(referenceRange lis02a2.ReferenceRange, unit string) (result ObservationReferenceRange, exists bool) {
+	if referenceRange.IsEmpty() {
+		return ObservationReferenceRange{}, false
+	}
+	bounds, err := referenceRange.Bounds()
+	if err != nil || bounds.Qualitative != "" {
+		return ObservationReferenceRange{Text: referenceRange.Range}, true
+	}
+	if bounds.Lower != nil {
+		result.Low = &Quantity{Value: json.Number(strconv.FormatFloat(*bounds.Lower, 'f', -1, 64)), Unit: unit}
+	}
+	if bounds.Upper != nil {
+		result.High = &Quantity{Value: json.Number(strconv.FormatFloat(*bounds.Upper, 'f', -1, 64)), Unit: unit}
+	}
+	return result, true
+}
+
+func notes(comments []lis02a2.Comment) (result []Annotation) {
+	for _, comment := range comments {
+		if comment.CommentText != "" {
+			result = append(result, Annotation{Text: comment.CommentText})
+		}
+	}
+	return result
+}
+
+func valueOrDefault(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,126 @@
+package fhir
+
+import (
+	"encoding/json"
+	"github.com/krendel52/go-astm/v3"
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+	"github.com/krendel52/go-astm/v3/models/messageformat/yumizen"
+	"github.com/krendel52/go-astm/v3/profiles"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+var tshCoding = Coding{System: SystemLOINC, Code: "3016-3", Display: "Thyrotropin [Units/volume] in Serum or Plasma"}
+
+func readResultMessage(t *testing.T) (message lis02a2.ResultMessage) {
+	data, err := os.ReadFile("testdata/result.astm")
+	assert.Nil(t, err)
+	err = astm.Unmarshal(data, &message)
+	assert.Nil(t, err)
+	return message
+}
+
+func TestResultsToJSON_Fixture(t *testing.T) {
+	// Arrange
+	message := readResultMessage(t)
+	converter := NewConverter()
+	converter.CodeMapper = CodeTable{"TSH": tshCoding}.Lookup
+	expected, err := os.ReadFile("testdata/result_bundle.json")
+	assert.Nil(t, err)
+	// Act
+	result, err := converter.ResultsToJSON(message)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), string(result))
+}
+
+func TestResultsToBundle_Resources(t *testing.T) {
+	// Arrange
+	message := readResultMessage(t)
+	// Act
+	bundle, err := NewConverter().ResultsToBundle(message)
+	// Assert
+	assert.Nil(t, err)
+	resourceTypes := make([]string, len(bundle.Entry))
+	for i, entry := range bundle.Entry {
+		resourceTypes[i] = entry.Resource.GetResourceType()
+	}
+	assert.Equal(t, []string{"Patient", "Specimen", "ServiceRequest", "Observation", "Observation", "DiagnosticReport"}, resourceTypes)
+	observation := bundle.Entry[3].Resource.(Observation)
+	// Without code mapping the tests only have a text
+	assert.Equal(t, CodeableConcept{Text: "TSH"}, observation.Code)
+	assert.Equal(t, &Quantity{Value: "0.01", Comparator: "<", Unit: "mU/l"}, observation.ValueQuantity)
+	report := bundle.Entry[5].Resource.(DiagnosticReport)
+	assert.Equal(t, []Reference{{Reference: bundle.Entry[3].FullURL}, {Reference: bundle.Entry[4].FullURL}}, report.Result)
+	assert.Equal(t, &Reference{Reference: bundle.Entry[0].FullURL}, report.Subject)
+}
+
+func TestResultsToBundle_StableIdentifiers(t *testing.T) {
+	// Arrange
+	message := readResultMessage(t)
+	otherMessage := readResultMessage(t)
+	otherMessage.Header.MessageControlID = "MSG0002"
+	// Act
+	first, err := NewConverter().ResultsToBundle(message)
+	assert.Nil(t, err)
+	second, err := NewConverter().ResultsToBundle(message)
+	assert.Nil(t, err)
+	other, err := NewConverter().ResultsToBundle(otherMessage)
+	assert.Nil(t, err)
+	// Assert
+	assert.Equal(t, first.Entry[0].FullURL, second.Entry[0].FullURL)
+	assert.NotEqual(t, first.Entry[0].FullURL, other.Entry[0].FullURL)
+	assert.Regexp(t, `^urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-5[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, first.Entry[0].FullURL)
+}
+
+func TestResultsToBundle_YumizenLOINC(t *testing.T) {
+	// Arrange
+	data, err := os.ReadFile("../../examples/yumizen/result_corrected.astm")
+	assert.Nil(t, err)
+	var message yumizen.ResultMessage
+	err = astm.Unmarshal(data, &message, profiles.Yumizen().Configuration)
+	assert.Nil(t, err)
+	converter := NewConverter()
+	converter.CodeMapper = LOINCFromTestName
+	// Act
+	bundle, err := converter.ResultsToBundle(message.LIS02A2())
+	// Assert
+	assert.Nil(t, err)
+	// The empty patient of the control measurement is not exported
+	assert.Equal(t, "Specimen", bundle.Entry[0].Resource.GetResourceType())
+	observation := bundle.Entry[2].Resource.(Observation)
+	assert.Nil(t, observation.Subject)
+	assert.Equal(t, CodeableConcept{Coding: []Coding{{System: SystemLOINC, Code: "787-2"}}, Text: "MCV"}, observation.Code)
+	assert.Equal(t, &Quantity{Value: "78.4", Unit: "um3"}, observation.ValueQuantity)
+	assert.Equal(t, []ObservationReferenceRange{{Low: &Quantity{Value: "73.5", Unit: "um3"}, High: &Quantity{Value: "83.5", Unit: "um3"}}}, observation.ReferenceRange)
+	assert.Equal(t, "N", observation.Interpretation[0].Coding[0].Code)
+	// The bundle is valid JSON
+	_, err = json.Marshal(bundle)
+	assert.Nil(t, err)
+}
+
+func TestResultsToBundle_ObservationStatusAndValue(t *testing.T) {
+	// Arrange
+	message := lis02a2.ResultMessage{PatientGroups: []lis02a2.PatientGroup{{OrderGroups: []lis02a2.OrderGroup{{
+		ResultGroups: []lis02a2.ResultGroup{
+			{Result: lis02a2.Result{DataMeasurementValue: "1,5", ResultStatus: lis02a2.ResultStatusCorrection}},
+			{Result: lis02a2.Result{DataMeasurementValue: "see comment", ResultStatus: "?"}},
+		},
+	}}}}}
+	// Act
+	bundle, err := NewConverter().ResultsToBundle(message)
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, bundle.Entry, 4)
+	first := bundle.Entry[1].Resource.(Observation)
+	assert.Equal(t, "corrected", first.Status)
+	assert.Equal(t, json.Number("1.5"), first.ValueQuantity.Value)
+	assert.Nil(t, first.Interpretation)
+	second := bundle.Entry[2].Resource.(Observation)
+	assert.Equal(t, "unknown", second.Status)
+	assert.Equal(t, "see comment", second.ValueString)
+	report := bundle.Entry[3].Resource.(DiagnosticReport)
+	assert.Equal(t, "unknown", report.Status)
+	assert.Equal(t, "Laboratory report", report.Code.Text)
+}
@@ -0,0 +1,126 @@
+// Package fhir exports the LIS02-A2 result messages as FHIR R4 bundles
+package fhir
+
+import "encoding/json"
+
+// FHIR R4 resources and data types, only the elements filled by the converter are declared
+
+type Resource interface {
+	GetResourceType() string
+}
+
+type Bundle struct {
+	ResourceType string        `json:"resourceType"`
+	Type         string        `json:"type"`
+	Timestamp    string        `json:"timestamp,omitempty"`
+	Entry        []BundleEntry `json:"entry"`
+}
+type BundleEntry struct {
+	FullURL  string   `json:"fullUrl"`
+	Resource Resource `json:"resource"`
+}
+
+// Data types //
+
+type Identifier struct {
+	System string `json:"system,omitempty"`
+	Value  string `json:"value"`
+}
+type Coding struct {
+	System  string `json:"system,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Display string `json:"display,omitempty"`
+}
+type CodeableConcept struct {
+	Coding []Coding `json:"coding,omitempty"`
+	Text   string   `json:"text,omitempty"`
+}
+type Reference struct {
+	Reference string `json:"reference,omitempty"`
+	Display   string `json:"display,omitempty"`
+}
+type HumanName struct {
+	Family string   `json:"family,omitempty"`
+	Given  []string `json:"given,omitempty"`
+}
+type Quantity struct {
+	Value      json.Number `json:"value"`
+	Comparator string      `json:"comparator,omitempty"`
+	Unit       string      `json:"unit,omitempty"`
+}
+type ObservationReferenceRange struct {
+	Low  *Quantity `json:"low,omitempty"`
+	High *Quantity `json:"high,omitempty"`
+	Text string    `json:"text,omitempty"`
+}
+type Annotation struct {
+	Text string `json:"text"`
+}
+type SpecimenCollection struct {
+	CollectedDateTime string `json:"collectedDateTime,omitempty"`
+}
+
+// Resources //
+
+type Patient struct {
+	ResourceType string       `json:"resourceType"`
+	Identifier   []Identifier `json:"identifier,omitempty"`
+	Name         []HumanName  `json:"name,omitempty"`
+	Gender       string       `json:"gender,omitempty"`
+	BirthDate    string       `json:"birthDate,omitempty"`
+}
+type ServiceRequest struct {
+	ResourceType string           `json:"resourceType"`
+	Identifier   []Identifier     `json:"identifier,omitempty"`
+	Status       string           `json:"status"`
+	Intent       string           `json:"intent"`
+	Priority     string           `json:"priority,omitempty"`
+	Code         *CodeableConcept `json:"code,omitempty"`
+	Subject      *Reference       `json:"subject,omitempty"`
+	AuthoredOn   string           `json:"authoredOn,omitempty"`
+	Requester    *Reference       `json:"requester,omitempty"`
+	Specimen     []Reference      `json:"specimen,omitempty"`
+	Note         []Annotation     `json:"note,omitempty"`
+}
+type Specimen struct {
+	ResourceType        string              `json:"resourceType"`
+	AccessionIdentifier *Identifier         `json:"accessionIdentifier,omitempty"`
+	Type                *CodeableConcept    `json:"type,omitempty"`
+	Subject             *Reference          `json:"subject,omitempty"`
+	Collection          *SpecimenCollection `json:"collection,omitempty"`
+}
+type Observation struct {
+	ResourceType      string                      `json:"resourceType"`
+	BasedOn           []Reference                 `json:"basedOn,omitempty"`
+	Status            string                      `json:"status"`
+	Category          []CodeableConcept           `json:"category,omitempty"`
+	Code              CodeableConcept             `json:"code"`
+	Subject           *Reference                  `json:"subject,omitempty"`
+	EffectiveDateTime string                      `json:"effectiveDateTime,omitempty"`
+	Issued            string                      `json:"issued,omitempty"`
+	ValueQuantity     *Quantity                   `json:"valueQuantity,omitempty"`
+	ValueString       string                      `json:"valueString,omitempty"`
+	Interpretation    []CodeableConcept           `json:"interpretation,omitempty"`
+	Note              []Annotation                `json:"note,omitempty"`
+	Specimen          *Reference                  `json:"specimen,omitempty"`
+	Device            *Reference                  `json:"device,omitempty"`
+	ReferenceRange    []ObservationReferenceRange `json:"referenceRange,omitempty"`
+}
+type DiagnosticReport struct {
+	ResourceType      string            `json:"resourceType"`
+	BasedOn           []Reference       `json:"basedOn,omitempty"`
+	Status            string            `json:"status"`
+	Category          []CodeableConcept `json:"category,omitempty"`
+	Code              CodeableConcept   `json:"code"`
+	Subject           *Reference        `json:"subject,omitempty"`
+	EffectiveDateTime string            `json:"effectiveDateTime,omitempty"`
+	Issued            string            `json:"issued,omitempty"`
+	Specimen          []Reference       `json:"specimen,omitempty"`
+	Result            []Reference       `json:"result,omitempty"`
+}
+
+func (r Patient) GetResourceType() string          { return r.ResourceType }
+func (r ServiceRequest) GetResourceType() string   { return r.ResourceType }
+func (r Specimen) GetResourceType() string         { return r.ResourceType }
+func (r Observation) GetResourceType() string      { return r.ResourceType }
+func (r DiagnosticReport) GetResourceType() string { return r.ResourceType }
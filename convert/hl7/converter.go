@@ -0,0 +1,290 @@
+package hl7
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/krendel52/go-astm/v3/enums/hl7messagetype"
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/functions"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+)
+
+// HL7 version of the built messages
+const Version = "2.5.1"
+
+// ASTM fields of the LIS02-A2 records holding a timestamp (the HL7 timestamps are converted to the ASTM format)
+var timestampFields = map[string][]int{
+	"H": {14},
+	"P": {8},
+	"O": {7, 8, 9, 23},
+	"R": {10, 12, 13},
+}
+
+// Converter between the LIS02-A2 messages and HL7 v2.5.1 messages
+type Converter struct {
+	// Field mapping between the HL7 segments and the ASTM records
+	Mapping Mapping
+	// Message type of the built result messages (hl7messagetype.ORUR01 or hl7messagetype.OULR22)
+	ResultMessageType string
+	// Configuration of the ASTM side (notation, time zone, etc.)
+	Configuration astmmodels.Configuration
+}
+
+func NewConverter() Converter {
+	return Converter{
+		Mapping:           DefaultMapping(),
+		ResultMessageType: hl7messagetype.ORUR01,
+		Configuration:     astmmodels.DefaultConfiguration,
+	}
+}
+
+// ResultsToHL7 converts a result message (e.g. lis02a2.ResultMessage) into an ORU^R01 or OUL^R22 message
+// The records are mapped to segments: H to MSH, P to PID, O to ORC and OBR (and SPM), R to OBX and C to NTE
+func (c Converter) ResultsToHL7(sourceStruct interface{}) (message Message, err error) {
+	// Load configuration
+	config, err := functions.LoadConfiguration(c.Configuration)
+	if err != nil {
+		return Message{}, err
+	}
+	if c.ResultMessageType != hl7messagetype.ORUR01 && c.ResultMessageType != hl7messagetype.OULR22 {
+		return Message{}, fmt.Errorf("%w: %q", errmsg.ErrHL7ConversionUnsupportedMessageType, c.ResultMessageType)
+	}
+	entries, err := c.Mapping.entries()
+	if err != nil {
+		return Message{}, err
+	}
+	// Build the ASTM lines and split them into records
+	lines, err := functions.BuildStruct(sourceStruct, 1, 0, config)
+	if err != nil {
+		return Message{}, err
+	}
+	records := make([]astmmodels.GenericRecord, len(lines))
+	for i, line := range lines {
+		records[i], err = functions.ParseGenericLine(line, config)
+		if err != nil {
+			return Message{}, err
+		}
+	}
+	if len(records) == 0 || records[0].Type != "H" {
+		return Message{}, errmsg.ErrHL7ConversionInvalidMessage
+	}
+
+	// Map the records to segments, the set IDs are counted per message (PID, OBR, SPM) and per parent segment (OBX, NTE)
+	pidSetID, obrSetID, obxSetID, nteSetID := 0, 0, 0, 0
+	for i, record := range records {
+		switch record.Type {
+		case "H":
+			if i > 0 {
+				return Message{}, errmsg.ErrHL7ConversionMultipleMessages
+			}
+			message.Segments = append(message.Segments, c.buildMSH(record, entries))
+		case "P":
+			pidSetID++
+			message.Segments = append(message.Segments, buildSegment("PID", pidSetID, record, entries))
+		case "O":
+			obrSetID++
+			orc := buildSegment("ORC", 0, record, entries)
+			orc.SetValue(1, 1, 1, "RE")
+			obr := buildSegment("OBR", obrSetID, record, entries)
+			if c.ResultMessageType == hl7messagetype.OULR22 {
+				// In OUL^R22 the specimen is the parent of the order
+				message.Segments = append(message.Segments, buildSegment("SPM", obrSetID, record, entries), obr, orc)
+			} else {
+				message.Segments = append(message.Segments, orc, obr)
+			}
+			obxSetID = 0
+		case "R":
+			obxSetID++
+			obx := buildSegment("OBX", obxSetID, record, entries)
+			if value := obx.Value(5, 1, 1); value != "" {
+				obx.SetValue(2, 1, 1, valueType(value))
+			}
+			message.Segments = append(message.Segments, obx)
+		case "C":
+			nteSetID++
+			message.Segments = append(message.Segments, buildSegment("NTE", nteSetID, record, entries))
+			continue
+		}
+		// The notes are counted per parent segment
+		nteSetID = 0
+	}
+
+	// Return the message and no error if everything went well
+	return message, nil
+}
+
+// OrdersFromHL7 converts an OML^O21 message into an order message
+// The segments are mapped to records: MSH to H, PID to P, ORC, OBR and SPM to O (every ORC or repeated OBR starts a new order)
+func (c Converter) OrdersFromHL7(messageData []byte) (orderMessage lis02a2.OrderMessage, err error) {
+	// Load configuration
+	config, err := functions.LoadConfiguration(c.Configuration)
+	if err != nil {
+		return lis02a2.OrderMessage{}, err
+	}
+	entries, err := c.Mapping.entries()
+	if err != nil {
+		return lis02a2.OrderMessage{}, err
+	}
+	// Parse and check the HL7 message
+	message, err := ParseMessage(messageData)
+	if err != nil {
+		return lis02a2.OrderMessage{}, err
+	}
+	msh := message.Segments[0]
+	if msh.Name != "MSH" {
+		return lis02a2.OrderMessage{}, errmsg.ErrHL7ConversionInvalidMessage
+	}
+	if messageType := msh.Value(9, 1, 1) + "^" + msh.Value(9, 2, 1); messageType != hl7messagetype.OMLO21 {
+		return lis02a2.OrderMessage{}, fmt.Errorf("%w: %q", errmsg.ErrHL7ConversionUnsupportedMessageType, messageType)
+	}
+
+	// Map the segments to records
+	header := newRecord("H", 1)
+	records := []*astmmodels.GenericRecord{&header}
+	var patient, order *astmmodels.GenericRecord
+	patientSequence, orderSequence, orderHasOBR := 0, 0, false
+	newPatient := func() {
+		patientSequence++
+		record := newRecord("P", patientSequence)
+		patient, order, orderSequence = &record, nil, 0
+		records = append(records, patient)
+	}
+	newOrder := func() {
+		if patient == nil {
+			// The orders without patient are assigned to an empty one
+			newPatient()
+		}
+		orderSequence++
+		record := newRecord("O", orderSequence)
+		order, orderHasOBR = &record, false
+		records = append(records, order)
+	}
+	for _, segment := range message.Segments {
+		var record *astmmodels.GenericRecord
+		switch segment.Name {
+		case "MSH":
+			record = &header
+		case "PID":
+			newPatient()
+			record = patient
+		case "ORC":
+			newOrder()
+			record = order
+		case "OBR":
+			if order == nil || orderHasOBR {
+				newOrder()
+			}
+			orderHasOBR = true
+			record = order
+		case "SPM":
+			if order == nil {
+				newOrder()
+			}
+			record = order
+		default:
+			// The other segments (e.g. NTE, TQ1) have no order record counterpart
+			continue
+		}
+		err = segmentToRecord(segment, record, entries, func(astmField int, value string) (string, error) {
+			return convertTimestamp(record.Type, astmField, value, config.TimeLocation)
+		})
+		if err != nil {
+			return lis02a2.OrderMessage{}, err
+		}
+	}
+	terminator := newRecord("L", 1)
+	setRecordValue(&terminator, 3, 1, "N")
+	records = append(records, &terminator)
+
+	// Build the ASTM lines and parse them into the order message
+	lines := make([]string, len(records))
+	for i, record := range records {
+		lines[i], err = functions.BuildGenericLine(*record, config)
+		if err != nil {
+			return lis02a2.OrderMessage{}, err
+		}
+	}
+	lineIndex := 0
+	err = functions.ParseStruct(lines, &orderMessage, &lineIndex, 1, 0, config)
+	if err != nil {
+		return lis02a2.OrderMessage{}, err
+	}
+
+	// Return the order message and no error if everything went well
+	return orderMessage, nil
+}
+
+// Build the MSH segment with the message type, the version and the mapped header fields
+func (c Converter) buildMSH(record astmmodels.GenericRecord, entries []mappingEntry) Segment {
+	msh := NewSegment("MSH")
+	recordToSegment(record, &msh, entries)
+	messageType := strings.Split(c.ResultMessageType, "^")
+	msh.SetComponents(9, append(messageType, strings.Join(messageType, "_")))
+	// The processing ID is mandatory, production is assumed if it is not mapped
+	if msh.Value(11, 1, 1) == "" {
+		msh.SetValue(11, 1, 1, string(lis02a2.ProcessingIDProduction))
+	}
+	msh.SetValue(12, 1, 1, Version)
+	return msh
+}
+
+// Build a segment from the mapped fields of the record, with the set ID in the first field (if it is not 0)
+func buildSegment(name string, setID int, record astmmodels.GenericRecord, entries []mappingEntry) Segment {
+	segment := NewSegment(name)
+	if setID > 0 {
+		segment.SetValue(1, 1, 1, strconv.Itoa(setID))
+	}
+	recordToSegment(record, &segment, entries)
+	return segment
+}
+
+// Numeric values are NM, everything else is ST
+func valueType(value string) string {
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return "NM"
+	}
+	return "ST"
+}
+
+func newRecord(recordType string, sequenceNumber int) astmmodels.GenericRecord {
+	return astmmodels.GenericRecord{
+		Type:   recordType,
+		Fields: []astmmodels.GenericField{{{recordType}}, {{strconv.Itoa(sequenceNumber)}}},
+	}
+}
+
+// Convert an HL7 timestamp (YYYYMMDD[HH[MM[SS[.S]]]][+/-ZZZZ]) of a timestamp field into the ASTM format
+// A timestamp with time zone is converted into the configured location, the dates are kept as they are
+func convertTimestamp(recordType string, astmField int, value string, location *time.Location) (string, error) {
+	isTimestamp := false
+	for _, field := range timestampFields[recordType] {
+		isTimestamp = isTimestamp || field == astmField
+	}
+	if !isTimestamp {
+		return value, nil
+	}
+	digits, zone := value, ""
+	if index := strings.IndexAny(value, "+-"); index >= 0 {
+		digits, zone = value[:index], value[index:]
+	}
+	digits, _, _ = strings.Cut(digits, ".")
+	if _, err := strconv.Atoi(digits); err != nil || len(digits) < 8 || len(digits) > 14 || len(digits)%2 != 0 {
+		return "", fmt.Errorf("%w: %q", errmsg.ErrHL7ConversionInvalidTimestamp, value)
+	}
+	if len(digits) == 8 {
+		return digits, nil
+	}
+	digits += strings.Repeat("0", 14-len(digits))
+	if zone == "" {
+		return digits, nil
+	}
+	timestamp, err := time.Parse("20060102150405-0700", digits+zone)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q", errmsg.ErrHL7ConversionInvalidTimestamp, value)
+	}
+	return timestamp.In(location).Format("20060102150405"), nil
+}
@@ -0,0 +1,191 @@
+package hl7
+
+import (
+	"github.com/krendel52/go-astm/v3"
+	"github.com/krendel52/go-astm/v3/enums/hl7messagetype"
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Read a fixture, the HL7 fixtures are stored with LF line endings to keep them readable
+func readFixture(t *testing.T, name string) []byte {
+	data, err := os.ReadFile("testdata/" + name)
+	assert.Nil(t, err)
+	if strings.HasSuffix(name, ".hl7") {
+		data = []byte(strings.ReplaceAll(string(data), "\n", SegmentSeparator))
+	}
+	return data
+}
+
+func readResultMessage(t *testing.T) (message lis02a2.ResultMessage) {
+	err := astm.Unmarshal(readFixture(t, "result.astm"), &message)
+	assert.Nil(t, err)
+	return message
+}
+
+func TestResultsToHL7_ORUR01(t *testing.T) {
+	// Arrange
+	message := readResultMessage(t)
+	converter := NewConverter()
+	// Act
+	result, err := converter.ResultsToHL7(&message)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, string(readFixture(t, "result_oru_r01.hl7")), string(result.Bytes()))
+}
+
+func TestResultsToHL7_OULR22(t *testing.T) {
+	// Arrange
+	message := readResultMessage(t)
+	converter := NewConverter()
+	converter.ResultMessageType = hl7messagetype.OULR22
+	// Act
+	result, err := converter.ResultsToHL7(&message)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, string(readFixture(t, "result_oul_r22.hl7")), string(result.Bytes()))
+}
+
+func TestResultsToHL7_CustomMapping(t *testing.T) {
+	// Arrange
+	message := readResultMessage(t)
+	converter := NewConverter()
+	converter.Mapping = Mapping{
+		"PID-3":   "6",
+		"OBX-3":   "3",
+		"OBX-5.1": "4.1",
+		"OBX-6.2": "5",
+	}
+	// Act
+	result, err := converter.ResultsToHL7(&message)
+	// Assert
+	assert.Nil(t, err)
+	pid, _ := result.Segment("PID")
+	assert.Equal(t, "PID|1||Doe^John", pid.String())
+	obx, _ := result.Segment("OBX")
+	assert.Equal(t, "OBX|1|NM|^^^TSH^Thyrotropin||2.35|^mU/l", obx.String())
+	// The message type, processing ID and version are always set
+	assert.Equal(t, "MSH|^~\\&|||||||ORU^R01^ORU_R01||P|2.5.1", result.Segments[0].String())
+}
+
+func TestResultsToHL7_InvalidMapping(t *testing.T) {
+	// Arrange
+	message := readResultMessage(t)
+	mappings := []Mapping{
+		{"XYZ-1": "3"},
+		{"PID3": "3"},
+		{"PID-0": "3"},
+		{"PID-3.x": "3"},
+		{"PID-3": "2"},
+		{"MSH-2": "3"},
+	}
+	for _, mapping := range mappings {
+		converter := NewConverter()
+		converter.Mapping = mapping
+		// Act
+		_, err := converter.ResultsToHL7(&message)
+		// Assert
+		assert.ErrorIs(t, err, errmsg.ErrHL7ConversionInvalidPosition)
+	}
+}
+
+func TestResultsToHL7_UnsupportedMessageType(t *testing.T) {
+	// Arrange
+	message := readResultMessage(t)
+	converter := NewConverter()
+	converter.ResultMessageType = hl7messagetype.OMLO21
+	// Act
+	_, err := converter.ResultsToHL7(&message)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrHL7ConversionUnsupportedMessageType)
+}
+
+func TestResultsToHL7_MultipleMessages(t *testing.T) {
+	// Arrange
+	message := readResultMessage(t)
+	multiMessage := lis02a2.ResultMultiMessage{ResultMessages: []lis02a2.ResultMessage{message, message}}
+	// Act
+	_, err := NewConverter().ResultsToHL7(&multiMessage)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrHL7ConversionMultipleMessages)
+}
+
+func TestOrdersFromHL7_OMLO21(t *testing.T) {
+	// Arrange
+	converter := NewConverter()
+	// Act
+	message, err := converter.OrdersFromHL7(readFixture(t, "order_oml_o21.hl7"))
+	// Assert
+	assert.Nil(t, err)
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	assert.Equal(t, "ORD0001", message.Header.MessageControlID)
+	assert.Equal(t, "Lab-System", message.Header.SenderNameOrID)
+	assert.Equal(t, "LIS-Host", message.Header.ReceiverID)
+	assert.Equal(t, lis02a2.ProcessingIDProduction, message.Header.ProcessingID)
+	// The timestamp with time zone is converted into the configured location (the parsed timestamps are UTC)
+	assert.Equal(t, time.Date(2024, 6, 25, 10, 0, 0, 0, time.UTC), message.Header.DateAndTime)
+	assert.Len(t, message.PatientOrders, 1)
+	patient := message.PatientOrders[0].Patient
+	assert.Equal(t, "PAT-100", patient.PracticeAssignedPatientID)
+	assert.Equal(t, "LAB-100", patient.LabAssignedPatientID)
+	assert.Equal(t, "Doe", patient.LastName)
+	assert.Equal(t, "John", patient.FirstName)
+	assert.Equal(t, "M", patient.Gender)
+	assert.Equal(t, time.Date(1980, 1, 15, 0, 0, 0, 0, berlin), patient.DOB)
+	orders := message.PatientOrders[0].Orders
+	assert.Len(t, orders, 2)
+	assert.Equal(t, "SMP-1", orders[0].SpecimenID)
+	assert.Equal(t, "TSH", orders[0].UniversalTestID.ManufacturersTestType)
+	assert.Equal(t, lis02a2.PriorityRoutine, orders[0].Priority)
+	// The short timestamp is completed and read in the configured location
+	assert.Equal(t, time.Date(2024, 6, 25, 8, 0, 0, 0, berlin).UTC(), orders[0].SpecimenCollectionDateTime)
+	assert.Equal(t, "Serum", orders[0].SpecimenType)
+	assert.Equal(t, "Blood", orders[0].SpecimenSource)
+	assert.Equal(t, "Dr. Who", orders[0].OrderingPhysician)
+	assert.Equal(t, "SMP-2", orders[1].SpecimenID)
+	assert.Equal(t, "FT4", orders[1].UniversalTestID.ManufacturersTestType)
+	assert.Equal(t, lis02a2.PriorityStat, orders[1].Priority)
+	// The fraction of the seconds is dropped
+	assert.Equal(t, time.Date(2024, 6, 25, 6, 15, 0, 0, time.UTC), orders[1].SpecimenCollectionDateTime)
+}
+
+func TestOrdersFromHL7_OrdersWithoutPatient(t *testing.T) {
+	// Arrange
+	data := "MSH|^~\\&|||||||OML^O21|ORD0002\rOBR|1|SMP-1||TSH\rOBR|2|SMP-2||FT4\r"
+	// Act
+	message, err := NewConverter().OrdersFromHL7([]byte(data))
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, message.PatientOrders, 1)
+	assert.Equal(t, lis02a2.Patient{}, message.PatientOrders[0].Patient)
+	assert.Len(t, message.PatientOrders[0].Orders, 2)
+	assert.Equal(t, "SMP-2", message.PatientOrders[0].Orders[1].SpecimenID)
+}
+
+func TestOrdersFromHL7_UnsupportedMessageType(t *testing.T) {
+	// Act
+	_, err := NewConverter().OrdersFromHL7(readFixture(t, "result_oru_r01.hl7"))
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrHL7ConversionUnsupportedMessageType)
+}
+
+func TestOrdersFromHL7_InvalidTimestamp(t *testing.T) {
+	// Arrange
+	data := "MSH|^~\\&|||||||OML^O21|ORD0003\rPID|1||||||2024-01-15\r"
+	// Act
+	_, err := NewConverter().OrdersFromHL7([]byte(data))
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrHL7ConversionInvalidTimestamp)
+}
+
+func TestOrdersFromHL7_InvalidMessage(t *testing.T) {
+	// Act
+	_, err := NewConverter().OrdersFromHL7([]byte("PID|1"))
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrHL7ConversionInvalidMessage)
+}
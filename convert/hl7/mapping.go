@@ -0,0 +1,215 @@
+package hl7
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+)
+
+// Mapping of the HL7 positions ("SEG-F" or "SEG-F.C") to the ASTM positions ("F" or "F.C") of the corresponding record
+// A position without component on both sides copies all the components of the field
+type Mapping map[string]string
+
+// ASTM record type of every mapped HL7 segment
+var segmentRecordTypes = map[string]string{
+	"MSH": "H",
+	"PID": "P",
+	"ORC": "O",
+	"OBR": "O",
+	"SPM": "O",
+	"OBX": "R",
+	"NTE": "C",
+}
+
+// DefaultMapping maps the most common fields of the LIS02-A2 records to their HL7 v2.5.1 counterparts
+func DefaultMapping() Mapping {
+	return Mapping{
+		// Header
+		"MSH-3.1": "5.1",
+		"MSH-5.1": "10",
+		"MSH-7":   "14",
+		"MSH-10":  "3",
+		"MSH-11":  "12",
+		// Patient
+		"PID-2":   "3",
+		"PID-3.1": "4",
+		"PID-4":   "5",
+		"PID-5.1": "6.1",
+		"PID-5.2": "6.2",
+		"PID-6.1": "7",
+		"PID-7":   "8",
+		"PID-8":   "9",
+		"PID-10":  "10",
+		"PID-11":  "11",
+		"PID-13":  "13",
+		// Order
+		"ORC-2":   "3",
+		"ORC-3":   "4",
+		"OBR-2":   "3",
+		"OBR-3":   "4",
+		"OBR-4.1": "5.4",
+		"OBR-5":   "6",
+		"OBR-7":   "8",
+		"OBR-16":  "17",
+		"OBR-25":  "26",
+		"SPM-2.1": "3",
+		"SPM-4":   "16",
+		// Result
+		"OBX-3.1":  "3.4",
+		"OBX-3.2":  "3.5",
+		"OBX-5":    "4.1",
+		"OBX-6.1":  "5",
+		"OBX-7":    "6.1",
+		"OBX-8":    "7",
+		"OBX-11":   "9",
+		"OBX-14":   "13",
+		"OBX-16.1": "11.1",
+		"OBX-18":   "14",
+		// Comment
+		"NTE-2": "3",
+		"NTE-3": "4",
+		"NTE-4": "5",
+	}
+}
+
+// Parsed position of a mapping entry (the component is 0 if the whole field is mapped)
+type mappingEntry struct {
+	segment       string
+	field         int
+	component     int
+	astmField     int
+	astmComponent int
+	wholeField    bool
+}
+
+// Parse and check every entry of the mapping, sorted by their HL7 position for a deterministic order
+func (m Mapping) entries() (entries []mappingEntry, err error) {
+	for hl7Position, astmPosition := range m {
+		segment, position, found := strings.Cut(hl7Position, "-")
+		if _, known := segmentRecordTypes[segment]; !found || !known {
+			return nil, fmt.Errorf("%w: %q", errmsg.ErrHL7ConversionInvalidPosition, hl7Position)
+		}
+		field, component, err := parsePosition(position)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", errmsg.ErrHL7ConversionInvalidPosition, hl7Position)
+		}
+		astmField, astmComponent, err := parsePosition(astmPosition)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", errmsg.ErrHL7ConversionInvalidPosition, astmPosition)
+		}
+		// The record type and the sequence number (or the header delimiters) are not mappable
+		if astmField < 3 || (segment == "MSH" && field < 3) {
+			return nil, fmt.Errorf("%w: %q", errmsg.ErrHL7ConversionInvalidPosition, hl7Position)
+		}
+		entries = append(entries, mappingEntry{
+			segment:       segment,
+			field:         field,
+			component:     component,
+			astmField:     astmField,
+			astmComponent: astmComponent,
+			wholeField:    component == 0 && astmComponent == 0,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].segment != entries[j].segment {
+			return entries[i].segment < entries[j].segment
+		}
+		if entries[i].field != entries[j].field {
+			return entries[i].field < entries[j].field
+		}
+		return entries[i].component < entries[j].component
+	})
+	return entries, nil
+}
+
+// Parse a "F" or "F.C" position
+func parsePosition(position string) (field int, component int, err error) {
+	fieldPart, componentPart, hasComponent := strings.Cut(position, ".")
+	field, err = strconv.Atoi(fieldPart)
+	if err != nil || field < 1 {
+		return 0, 0, errmsg.ErrHL7ConversionInvalidPosition
+	}
+	if hasComponent {
+		component, err = strconv.Atoi(componentPart)
+		if err != nil || component < 1 {
+			return 0, 0, errmsg.ErrHL7ConversionInvalidPosition
+		}
+	}
+	return field, component, nil
+}
+
+// Copy the mapped values of the record into the segment (the empty values are not set)
+func recordToSegment(record astmmodels.GenericRecord, segment *Segment, entries []mappingEntry) {
+	for _, entry := range entries {
+		if entry.segment != segment.Name {
+			continue
+		}
+		if entry.wholeField {
+			if entry.astmField > len(record.Fields) || len(record.Fields[entry.astmField-1]) == 0 {
+				continue
+			}
+			components := record.Fields[entry.astmField-1][0]
+			if strings.Join(components, "") != "" {
+				segment.SetComponents(entry.field, trimComponents(components))
+			}
+			continue
+		}
+		if value := record.Value(entry.astmField, 1, max(entry.astmComponent, 1)); value != "" {
+			segment.SetValue(entry.field, entry.component, 1, value)
+		}
+	}
+}
+
+// Copy the mapped values of the segment into the record (the empty values are not set)
+func segmentToRecord(segment Segment, record *astmmodels.GenericRecord, entries []mappingEntry, convertValue func(astmField int, value string) (string, error)) error {
+	for _, entry := range entries {
+		if entry.segment != segment.Name {
+			continue
+		}
+		var components []string
+		if entry.wholeField {
+			components = trimComponents(segment.Components(entry.field))
+		} else {
+			components = []string{segment.Value(entry.field, entry.component, 1)}
+		}
+		if strings.Join(components, "") == "" {
+			continue
+		}
+		for i, component := range components {
+			value, err := convertValue(entry.astmField, component)
+			if err != nil {
+				return err
+			}
+			setRecordValue(record, entry.astmField, max(entry.astmComponent, 1)+i, value)
+		}
+	}
+	return nil
+}
+
+// Set a value of the first repeat of a record field, the missing fields and components are added
+func setRecordValue(record *astmmodels.GenericRecord, fieldPos int, componentPos int, value string) {
+	for len(record.Fields) < fieldPos {
+		record.Fields = append(record.Fields, nil)
+	}
+	field := record.Fields[fieldPos-1]
+	if len(field) == 0 {
+		field = astmmodels.GenericField{nil}
+	}
+	for len(field[0]) < componentPos {
+		field[0] = append(field[0], "")
+	}
+	field[0][componentPos-1] = value
+	record.Fields[fieldPos-1] = field
+}
+
+// Remove the empty components at the end
+func trimComponents(components []string) []string {
+	for len(components) > 0 && components[len(components)-1] == "" {
+		components = components[:len(components)-1]
+	}
+	return components
+}
@@ -0,0 +1,278 @@
+// Package hl7 converts between the LIS02-A2 message structures and HL7 v2.5.1 messages
+package hl7
+
+import (
+	"strings"
+
+	"github.com/krendel52/go-astm/v3/errmsg"
+)
+
+// Standard HL7 v2 delimiters, used for all the built messages
+const (
+	FieldSeparator     = "|"
+	EncodingCharacters = `^~\&`
+	SegmentSeparator   = "\r"
+)
+
+// HL7 v2 message: the segments in their order
+type Message struct {
+	Segments []Segment
+}
+
+// HL7 v2 segment, the fields are 1-based in the methods (Fields[0] is the field 1)
+// In the MSH segment the field 1 is the field separator and the field 2 is the encoding characters, as in the standard
+type Segment struct {
+	Name   string
+	Fields []Field
+}
+
+// Repeats of a field with their components and subcomponents (the values are unescaped)
+type Field [][][]string
+
+// Delimiters of a parsed message (read from the MSH segment)
+type delimiters struct {
+	field        string
+	component    string
+	repeat       string
+	escape       string
+	subComponent string
+}
+
+var standardDelimiters = delimiters{field: "|", component: "^", repeat: "~", escape: `\`, subComponent: "&"}
+
+func NewSegment(name string) Segment {
+	segment := Segment{Name: name}
+	if name == "MSH" {
+		segment.Fields = []Field{{{{FieldSeparator}}}, {{{EncodingCharacters}}}}
+	}
+	return segment
+}
+
+// Value of the field at the given position (1-based, the component and subcomponent 0 mean the first one)
+func (s Segment) Value(fieldPos int, componentPos int, subComponentPos int) string {
+	if fieldPos < 1 || fieldPos > len(s.Fields) || len(s.Fields[fieldPos-1]) == 0 {
+		return ""
+	}
+	repeat := s.Fields[fieldPos-1][0]
+	componentPos, subComponentPos = max(componentPos, 1), max(subComponentPos, 1)
+	if componentPos > len(repeat) || subComponentPos > len(repeat[componentPos-1]) {
+		return ""
+	}
+	return repeat[componentPos-1][subComponentPos-1]
+}
+
+// Components of the first repeat of the field (the first subcomponents)
+func (s Segment) Components(fieldPos int) (components []string) {
+	if fieldPos < 1 || fieldPos > len(s.Fields) || len(s.Fields[fieldPos-1]) == 0 {
+		return nil
+	}
+	for _, component := range s.Fields[fieldPos-1][0] {
+		if len(component) == 0 {
+			components = append(components, "")
+		} else {
+			components = append(components, component[0])
+		}
+	}
+	return components
+}
+
+// Set the value at the given position (1-based, the component and subcomponent 0 mean the first one)
+func (s *Segment) SetValue(fieldPos int, componentPos int, subComponentPos int, value string) {
+	if fieldPos < 1 {
+		return
+	}
+	componentPos, subComponentPos = max(componentPos, 1), max(subComponentPos, 1)
+	for len(s.Fields) < fieldPos {
+		s.Fields = append(s.Fields, nil)
+	}
+	field := s.Fields[fieldPos-1]
+	if len(field) == 0 {
+		field = Field{nil}
+	}
+	for len(field[0]) < componentPos {
+		field[0] = append(field[0], []string{""})
+	}
+	for len(field[0][componentPos-1]) < subComponentPos {
+		field[0][componentPos-1] = append(field[0][componentPos-1], "")
+	}
+	field[0][componentPos-1][subComponentPos-1] = value
+	s.Fields[fieldPos-1] = field
+}
+
+// Set the components of the first repeat of the field
+func (s *Segment) SetComponents(fieldPos int, components []string) {
+	for i, component := range components {
+		s.SetValue(fieldPos, i+1, 1, component)
+	}
+}
+
+// ParseMessage reads an HL7 v2 message, the segments can be separated by CR, LF or CRLF
+func ParseMessage(data []byte) (message Message, err error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\r")
+	text = strings.ReplaceAll(text, "\n", "\r")
+	delimiters := standardDelimiters
+	for _, line := range strings.Split(text, "\r") {
+		line = strings.Trim(line, " ")
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "MSH") {
+			// The delimiters are defined by the MSH segment itself
+			if len(line) < 8 {
+				return Message{}, errmsg.ErrHL7ConversionInvalidMessage
+			}
+			delimiters = delimiters.fromMSH(line)
+		} else if len(message.Segments) == 0 {
+			return Message{}, errmsg.ErrHL7ConversionInvalidMessage
+		}
+		message.Segments = append(message.Segments, parseSegment(line, delimiters))
+	}
+	if len(message.Segments) == 0 {
+		return Message{}, errmsg.ErrHL7ConversionInvalidMessage
+	}
+	return message, nil
+}
+
+func (d delimiters) fromMSH(line string) delimiters {
+	return delimiters{field: line[3:4], component: line[4:5], repeat: line[5:6], escape: line[6:7], subComponent: line[7:8]}
+}
+
+func parseSegment(line string, delimiters delimiters) (segment Segment) {
+	fields := strings.Split(line, delimiters.field)
+	segment.Name = fields[0]
+	if segment.Name == "MSH" {
+		// The field separator and the encoding characters are not split
+		segment.Fields = []Field{{{{delimiters.field}}}, {{{fields[1]}}}}
+		fields = fields[2:]
+	} else {
+		fields = fields[1:]
+	}
+	for _, field := range fields {
+		var parsedField Field
+		if field != "" {
+			for _, repeat := range strings.Split(field, delimiters.repeat) {
+				var components [][]string
+				for _, component := range strings.Split(repeat, delimiters.component) {
+					var subComponents []string
+					for _, subComponent := range strings.Split(component, delimiters.subComponent) {
+						subComponents = append(subComponents, unescape(subComponent, delimiters))
+					}
+					components = append(components, subComponents)
+				}
+				parsedField = append(parsedField, components)
+			}
+		}
+		segment.Fields = append(segment.Fields, parsedField)
+	}
+	return segment
+}
+
+// Bytes builds the message with the standard delimiters, every segment is terminated by CR
+func (m Message) Bytes() []byte {
+	var builder strings.Builder
+	for _, segment := range m.Segments {
+		builder.WriteString(segment.String())
+		builder.WriteString(SegmentSeparator)
+	}
+	return []byte(builder.String())
+}
+
+// String builds the segment with the standard delimiters (without the segment separator)
+func (s Segment) String() string {
+	fields := s.Fields
+	var builder strings.Builder
+	builder.WriteString(s.Name)
+	if s.Name == "MSH" {
+		// The field separator and the encoding characters are fixed
+		builder.WriteString(FieldSeparator + EncodingCharacters)
+		if len(fields) > 2 {
+			fields = fields[2:]
+		} else {
+			fields = nil
+		}
+	}
+	for _, field := range fields {
+		builder.WriteString(FieldSeparator)
+		repeats := make([]string, len(field))
+		for i, repeat := range field {
+			components := make([]string, len(repeat))
+			for j, component := range repeat {
+				subComponents := make([]string, len(component))
+				for k, subComponent := range component {
+					subComponents[k] = escape(subComponent)
+				}
+				components[j] = strings.Join(subComponents, standardDelimiters.subComponent)
+			}
+			repeats[i] = strings.Join(components, standardDelimiters.component)
+		}
+		builder.WriteString(strings.Join(repeats, standardDelimiters.repeat))
+	}
+	return builder.String()
+}
+
+// Segment returns the first segment with the given name
+func (m Message) Segment(name string) (Segment, bool) {
+	for _, segment := range m.Segments {
+		if segment.Name == name {
+			return segment, true
+		}
+	}
+	return Segment{}, false
+}
+
+// Escape the delimiters with the standard escape sequences (\F\, \S\, \T\, \R\ and \E\)
+func escape(value string) string {
+	var builder strings.Builder
+	for _, character := range value {
+		switch string(character) {
+		case standardDelimiters.escape:
+			builder.WriteString(`\E\`)
+		case standardDelimiters.field:
+			builder.WriteString(`\F\`)
+		case standardDelimiters.component:
+			builder.WriteString(`\S\`)
+		case standardDelimiters.subComponent:
+			builder.WriteString(`\T\`)
+		case standardDelimiters.repeat:
+			builder.WriteString(`\R\`)
+		default:
+			builder.WriteRune(character)
+		}
+	}
+	return builder.String()
+}
+
+func unescape(value string, delimiters delimiters) string {
+	if !strings.Contains(value, delimiters.escape) {
+		return value
+	}
+	sequences := map[string]string{
+		"F": delimiters.field,
+		"S": delimiters.component,
+		"T": delimiters.subComponent,
+		"R": delimiters.repeat,
+		"E": delimiters.escape,
+	}
+	var builder strings.Builder
+	for {
+		start := strings.Index(value, delimiters.escape)
+		if start < 0 {
+			break
+		}
+		end := strings.Index(value[start+1:], delimiters.escape)
+		if end < 0 {
+			break
+		}
+		sequence := value[start+1 : start+1+end]
+		builder.WriteString(value[:start])
+		if replacement, known := sequences[sequence]; known {
+			builder.WriteString(replacement)
+		} else {
+			// Unknown sequences (e.g. formatting) are kept as they are
+			builder.WriteString(value[start : start+end+2])
+		}
+		value = value[start+end+2:]
+	}
+	builder.WriteString(value)
+	return builder.String()
+}
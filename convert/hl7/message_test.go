@@ -0,0 +1,110 @@
+package hl7
+
+import (
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseMessage_SegmentSeparators(t *testing.T) {
+	// Arrange
+	inputs := []string{
+		"MSH|^~\\&|Sender\rPID|1||ID-1\r",
+		"MSH|^~\\&|Sender\nPID|1||ID-1\n",
+		"MSH|^~\\&|Sender\r\nPID|1||ID-1\r\n",
+	}
+	for _, input := range inputs {
+		// Act
+		message, err := ParseMessage([]byte(input))
+		// Assert
+		assert.Nil(t, err)
+		assert.Len(t, message.Segments, 2)
+		assert.Equal(t, "Sender", message.Segments[0].Value(3, 1, 1))
+		assert.Equal(t, "ID-1", message.Segments[1].Value(3, 1, 1))
+	}
+}
+
+func TestParseMessage_MSHFields(t *testing.T) {
+	// Act
+	message, err := ParseMessage([]byte("MSH|^~\\&|Sender^1.0||||||OML^O21^OML_O21"))
+	// Assert
+	assert.Nil(t, err)
+	msh, found := message.Segment("MSH")
+	assert.True(t, found)
+	assert.Equal(t, "|", msh.Value(1, 1, 1))
+	assert.Equal(t, "^~\\&", msh.Value(2, 1, 1))
+	assert.Equal(t, []string{"Sender", "1.0"}, msh.Components(3))
+	assert.Equal(t, []string{"OML", "O21", "OML_O21"}, msh.Components(9))
+}
+
+func TestParseMessage_RepeatsAndSubComponents(t *testing.T) {
+	// Act
+	message, err := ParseMessage([]byte("MSH|^~\\&\rPID|1||ID-1^^^A&B~ID-2"))
+	// Assert
+	assert.Nil(t, err)
+	pid := message.Segments[1]
+	assert.Equal(t, Field{{{"ID-1"}, {""}, {""}, {"A", "B"}}, {{"ID-2"}}}, pid.Fields[2])
+	assert.Equal(t, "B", pid.Value(3, 4, 2))
+	assert.Equal(t, "", pid.Value(3, 5, 1))
+	assert.Equal(t, "", pid.Value(30, 1, 1))
+}
+
+func TestParseMessage_CustomDelimiters(t *testing.T) {
+	// Act
+	message, err := ParseMessage([]byte("MSH#*!$%#Sender*1.0\rPID#1##ID-1*X%Y!ID-2"))
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Sender", "1.0"}, message.Segments[0].Components(3))
+	assert.Equal(t, "Y", message.Segments[1].Value(3, 2, 2))
+	// The message is built with the standard delimiters
+	assert.Equal(t, "MSH|^~\\&|Sender^1.0\rPID|1||ID-1^X&Y~ID-2\r", string(message.Bytes()))
+}
+
+func TestParseMessage_EscapeSequences(t *testing.T) {
+	// Act
+	message, err := ParseMessage([]byte("MSH|^~\\&\rNTE|1||A\\F\\B\\S\\C\\T\\D\\R\\E\\E\\F\\.br\\G"))
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "A|B^C&D~E\\F\\.br\\G", message.Segments[1].Value(3, 1, 1))
+}
+
+func TestParseMessage_Invalid(t *testing.T) {
+	// Arrange
+	inputs := []string{"", "\r\n", "MSH|^~", "PID|1||ID-1\rMSH|^~\\&"}
+	for _, input := range inputs {
+		// Act
+		_, err := ParseMessage([]byte(input))
+		// Assert
+		assert.ErrorIs(t, err, errmsg.ErrHL7ConversionInvalidMessage, input)
+	}
+}
+
+func TestSegment_SetValue(t *testing.T) {
+	// Arrange
+	segment := NewSegment("OBX")
+	// Act
+	segment.SetValue(1, 1, 1, "1")
+	segment.SetValue(3, 2, 0, "Name")
+	segment.SetValue(5, 1, 2, "Sub")
+	segment.SetComponents(6, []string{"mU/l", "", "UCUM"})
+	// Assert
+	assert.Equal(t, "OBX|1||^Name||&Sub|mU/l^^UCUM", segment.String())
+}
+
+func TestMessage_BytesEscapesValues(t *testing.T) {
+	// Arrange
+	msh := NewSegment("MSH")
+	msh.SetValue(3, 1, 1, "A|B")
+	nte := NewSegment("NTE")
+	nte.SetValue(3, 1, 1, "x^y&z~w\\v")
+	message := Message{Segments: []Segment{msh, nte}}
+	// Act
+	result := message.Bytes()
+	// Assert
+	assert.Equal(t, "MSH|^~\\&|A\\F\\B\rNTE|||x\\S\\y\\T\\z\\R\\w\\E\\v\r", string(result))
+	// The escaped values are parsed back
+	parsed, err := ParseMessage(result)
+	assert.Nil(t, err)
+	assert.Equal(t, "A|B", parsed.Segments[0].Value(3, 1, 1))
+	assert.Equal(t, "x^y&z~w\\v", parsed.Segments[1].Value(3, 1, 1))
+}
@@ -0,0 +1,32 @@
+package astm
+
+import (
+	"github.com/krendel52/go-astm/v3/functions"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+)
+
+// Diff compares two messages without a structure, the records are aligned by their type and sequence number
+// The ignored positions are record types (e.g. M), fields (e.g. H-14) or components (e.g. R-4.2)
+func Diff(messageDataA []byte, messageDataB []byte, ignoredPositions []string, configuration ...astmmodels.Configuration) (report astmmodels.DiffReport, err error) {
+	// Load configuration
+	config, err := functions.LoadConfiguration(configuration...)
+	if err != nil {
+		return astmmodels.DiffReport{}, err
+	}
+	// Split both messages into records, each of them with its own header delimiters
+	recordsA, err := UnmarshalGeneric(messageDataA, *config)
+	if err != nil {
+		return astmmodels.DiffReport{}, err
+	}
+	recordsB, err := UnmarshalGeneric(messageDataB, *config)
+	if err != nil {
+		return astmmodels.DiffReport{}, err
+	}
+	// Compare the records
+	report, err = functions.DiffRecords(recordsA, recordsB, ignoredPositions)
+	if err != nil {
+		return astmmodels.DiffReport{}, err
+	}
+	// Return the report and no error if everything went well
+	return report, nil
+}
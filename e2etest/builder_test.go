@@ -0,0 +1,116 @@
+package e2e
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/krendel52/go-astm/v3"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildOrderMessage(t *testing.T) {
+	// Arrange
+	dateAndTime := time.Date(2024, 7, 9, 10, 35, 36, 0, config.TimeLocation)
+	// Act
+	message := astm.NewOrder().
+		Sender("LIS").
+		Receiver("Analyzer").
+		DateTime(dateAndTime).
+		Patient(lis02a2.Patient{LabAssignedPatientID: "P1", LastName: "Doe", FirstName: "Jane"}).
+		AddTest("S1", "TSH", lis02a2.PriorityRoutine).
+		AddTest("S1", "FT4", lis02a2.PriorityStat).
+		Patient(lis02a2.Patient{LabAssignedPatientID: "P2"}).
+		AddTest("S2", "HBA1C", lis02a2.PriorityRoutine).
+		Build()
+	lines, err := astm.Marshal(message, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"H|\\^&|||LIS|||||Analyzer||P|LIS2-A2|20240709103536",
+		"P|1||P1||Doe^Jane|||||||||||||||||||||||||||||",
+		"O|1|S1||^^^TSH|R||||||N||||^||||||||||O|||||",
+		"O|2|S1||^^^FT4|S||||||N||||^||||||||||O|||||",
+		"P|2||P2||^|||||||||||||||||||||||||||||",
+		"O|1|S2||^^^HBA1C|R||||||N||||^||||||||||O|||||",
+		"L|1|N",
+	}, lineStrings(lines))
+}
+
+func TestBuildOrderMessageUnmarshalRoundTrip(t *testing.T) {
+	// Arrange
+	built := astm.NewOrder().Sender("LIS").AddTest("S1", "TSH", lis02a2.PriorityASAP).Build()
+	lines, err := astm.Marshal(built, config)
+	assert.Nil(t, err)
+	// Act
+	var message lis02a2.OrderMessage
+	err = astm.Unmarshal(bytes.Join(lines, []byte("\n")), &message, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, lis02a2.ProcessingIDProduction, message.Header.ProcessingID)
+	assert.Equal(t, astm.BuilderVersion, message.Header.Version)
+	assert.WithinDuration(t, time.Now(), message.Header.DateAndTime, time.Minute)
+	assert.Len(t, message.PatientOrders, 1)
+	assert.Len(t, message.PatientOrders[0].Orders, 1)
+	assert.Equal(t, "S1", message.PatientOrders[0].Orders[0].SpecimenID)
+	assert.Equal(t, "TSH", message.PatientOrders[0].Orders[0].UniversalTestID.ManufacturersTestType)
+	assert.Equal(t, lis02a2.PriorityASAP, message.PatientOrders[0].Orders[0].Priority)
+	assert.Equal(t, lis02a2.TerminatorCodeNormal, message.Terminator.TerminatorCode)
+}
+
+func TestBuildOrderMessageBuilderReuse(t *testing.T) {
+	// Arrange
+	builder := astm.NewOrder().AddTest("S1", "TSH", lis02a2.PriorityRoutine)
+	first := builder.Build()
+	// Act
+	second := builder.AddTest("S1", "FT4", lis02a2.PriorityRoutine).Build()
+	// Assert
+	assert.Len(t, first.PatientOrders[0].Orders, 1)
+	assert.Len(t, second.PatientOrders[0].Orders, 2)
+}
+
+func TestBuildQueryMessage(t *testing.T) {
+	// Arrange
+	dateAndTime := time.Date(2024, 7, 9, 10, 35, 36, 0, config.TimeLocation)
+	// Act
+	message := astm.NewQuery().
+		Sender("LIS").
+		DateTime(dateAndTime).
+		AddQuery("S1", "", lis02a2.RequestInformationStatusOrdersOnly).
+		AddQuery("S2", "TSH", lis02a2.RequestInformationStatusFinal).
+		Build()
+	lines, err := astm.Marshal(message, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"H|\\^&|||LIS|||||||P|LIS2-A2|20240709103536",
+		"Q|1|^S1||^^^ALL||||||||O",
+		"Q|2|^S2||^^^TSH||||||||F",
+		"L|1|N",
+	}, lineStrings(lines))
+}
+
+func TestBuildQueryMessageCustomDelimiters(t *testing.T) {
+	// Arrange
+	config.Delimiters = astmmodels.Delimiters{Field: "/", Repeat: "!", Component: "*", Escape: "%"}
+	message := astm.NewQuery(config).AddQuery("S1", "TSH", lis02a2.RequestInformationStatusFinal).Build()
+	// Act
+	lines, err := astm.Marshal(message, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "*S1", message.Queries[0].StartingRangeIDNumber)
+	assert.Equal(t, "***TSH", message.Queries[0].UniversalTestID)
+	assert.Equal(t, "Q/1/*S1//***TSH////////F", string(lines[1]))
+	// Teardown
+	teardown()
+}
+
+func lineStrings(lines [][]byte) []string {
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		result[i] = string(line)
+	}
+	return result
+}
@@ -0,0 +1,74 @@
+package e2e
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/krendel52/go-astm/v3"
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models/messageformat/biorad"
+	"github.com/krendel52/go-astm/v3/models/messageformat/euroimmun"
+	"github.com/krendel52/go-astm/v3/models/messageformat/galileo"
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+	"github.com/krendel52/go-astm/v3/models/messageformat/yumizen"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckTypeMessageFormats(t *testing.T) {
+	// Arrange
+	messages := []interface{}{
+		lis02a2.ResultMessage{},
+		lis02a2.ResultMultiMessage{},
+		lis02a2.QueryMessage{},
+		lis02a2.OrderMessage{},
+		lis02a2.ScientificMessage{},
+		galileo.OrderMessage{},
+		galileo.ResultMessage{},
+		yumizen.ResultMessage{},
+		euroimmun.ResultMessage{},
+		biorad.ResultMessage{},
+	}
+	for _, message := range messages {
+		// Act
+		err := astm.CheckType(reflect.TypeOf(message))
+		// Assert
+		assert.Nil(t, err, reflect.TypeOf(message).String())
+	}
+}
+
+type BrokenHeader struct {
+	Delimiters string `astm:"2,delimiters"`
+	First      string `astm:"3"`
+}
+type BrokenRecord struct {
+	Reserved  string  `astm:"2"`
+	First     string  `astm:"3"`
+	Duplicate string  `astm:"3"`
+	Component string  `astm:"4.1"`
+	Plain     string  `astm:"4"`
+	Length    int     `astm:"5,length:2"`
+	Unknown   float64 `astm:"6,unknown"`
+}
+type BrokenMessage struct {
+	Header BrokenHeader   `astm:"H"`
+	Record []BrokenRecord `astm:"R"`
+}
+
+func TestCheckTypeReportsAllMistakes(t *testing.T) {
+	// Act
+	err := astm.CheckType(reflect.TypeOf(BrokenMessage{}))
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrAnnotationLintingInvalidDelimitersField)
+	assert.ErrorIs(t, err, errmsg.ErrAnnotationLintingReservedFieldPos)
+	assert.ErrorIs(t, err, errmsg.ErrAnnotationLintingDuplicateFieldPos)
+	assert.ErrorIs(t, err, errmsg.ErrAnnotationLintingComponentCollision)
+	assert.ErrorIs(t, err, errmsg.ErrAnnotationLintingLengthOnNonFloat)
+	assert.ErrorIs(t, err, errmsg.ErrAnnotationParsingInvalidAstmAttribute)
+	assert.Contains(t, err.Error(), `"e2e.BrokenMessage.Record.Plain"`)
+}
+
+func TestMustRegister(t *testing.T) {
+	// Assert
+	assert.NotPanics(t, astm.MustRegister[lis02a2.OrderMessage])
+	assert.Panics(t, astm.MustRegister[BrokenMessage])
+}
@@ -0,0 +1,35 @@
+package e2e
+
+import (
+	"testing"
+
+	"github.com/krendel52/go-astm/v3"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffCustomDelimitersAndLineSeparators(t *testing.T) {
+	// Arrange
+	messageA := "H|\\^&|||Sender|||||LIS|||LIS2-A2|20240709103536\n" +
+		"R|1|^^^TSH|7.41|mU/l\n" +
+		"R|2|^^^FT4|a&|b|pmol/l\n" +
+		"L|1|N"
+	// Same values with other delimiters and escapes, CR line separators and another timestamp
+	messageB := "H!*$%!!!Sender!!!!!LIS!!!LIS2-A2!20240709113536\r" +
+		"R!1!$$$TSH!7.4!mU/l\r" +
+		"R!2!$$$FT4!a|b!pmol/l\r" +
+		"L!1!N"
+	// Act
+	report, err := astm.Diff([]byte(messageA), []byte(messageB), []string{"H-14"}, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []astmmodels.Difference{{Record: "R|1", Field: "4.1", Old: "7.41", New: "7.4"}}, report.Differences)
+}
+
+func TestDiffEqualMessages(t *testing.T) {
+	// Act
+	report, err := astm.Diff([]byte(jsonResultMessage), []byte(jsonResultMessage), nil, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, report.IsEqual())
+}
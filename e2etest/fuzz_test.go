@@ -0,0 +1,105 @@
+package e2e
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/krendel52/go-astm/v3"
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"github.com/krendel52/go-astm/v3/models/messageformat/biorad"
+	"github.com/krendel52/go-astm/v3/models/messageformat/euroimmun"
+	"github.com/krendel52/go-astm/v3/models/messageformat/galileo"
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+	"github.com/krendel52/go-astm/v3/models/messageformat/yumizen"
+)
+
+// Message structures the fuzzed data is unmarshalled into
+var fuzzTargets = []func() interface{}{
+	func() interface{} { return &lis02a2.OrderMessage{} },
+	func() interface{} { return &lis02a2.ResultMessage{} },
+	func() interface{} { return &lis02a2.ResultMultiMessage{} },
+	func() interface{} { return &lis02a2.QueryMessage{} },
+	func() interface{} { return &lis02a2.ScientificMessage{} },
+	func() interface{} { return &galileo.OrderMessage{} },
+	func() interface{} { return &galileo.ResultMessage{} },
+	func() interface{} { return &biorad.ResultMessage{} },
+	func() interface{} { return &yumizen.ResultMessage{} },
+	func() interface{} { return &euroimmun.ResultMessage{} },
+}
+
+// Errors of the malformed input, Unmarshal returns one of them instead of panicking
+// (the errors of invalid structures can not be caused by the input of the fuzz targets)
+var unmarshalInputErrors = []error{
+	errmsg.ErrLineProcessingEmptyInput,
+	errmsg.ErrLineProcessingInvalidLinebreak,
+	errmsg.ErrLineParsingEmptyInput,
+	errmsg.ErrLineParsingHeaderTooShort,
+	errmsg.ErrLineParsingMandatoryInputFieldsMissing,
+	errmsg.ErrLineParsingSequenceNumberMismatch,
+	errmsg.ErrLineParsingRequiredInputFieldMissing,
+	errmsg.ErrLineParsingInputComponentsMissing,
+	errmsg.ErrLineParsingDataParsingError,
+	errmsg.ErrLineParsingInvalidDateFormat,
+	errmsg.ErrLineParsingInvalidEnumValue,
+	errmsg.ErrStructureParsingInputLinesDepleted,
+	errmsg.ErrStructureParsingLineTypeNameMismatch,
+}
+
+// Seed the corpus with the examples and truncated headers
+func addFuzzSeeds(f *testing.F) {
+	examples, err := filepath.Glob("../examples/*/*.astm")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, example := range examples {
+		data, err := os.ReadFile(example)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	for _, seed := range []string{"", "H", "H|\\^&", "H|\\^&|", "H|\\^&\nL|1|N", "H|||||\nP|1", "R|1|^^^TSH|7.41\r\nL"} {
+		f.Add([]byte(seed))
+	}
+}
+
+func FuzzUnmarshal(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		alternateConfig := astmmodels.DefaultConfiguration
+		alternateConfig.PreserveRawRecords = true
+		alternateConfig.EnforceSequenceNumberCheck = false
+		alternateConfig.ValidateEnums = true
+		for _, configuration := range []astmmodels.Configuration{config, alternateConfig} {
+			for _, target := range fuzzTargets {
+				err := astm.Unmarshal(data, target(), configuration)
+				if err != nil && !isUnmarshalInputError(err) {
+					t.Fatalf("untyped error: %v", err)
+				}
+			}
+			_, err := astm.UnmarshalGeneric(data, configuration)
+			if err != nil && !isUnmarshalInputError(err) {
+				t.Fatalf("untyped error: %v", err)
+			}
+		}
+	})
+}
+
+func isUnmarshalInputError(err error) bool {
+	for _, inputError := range unmarshalInputErrors {
+		if errors.Is(err, inputError) {
+			return true
+		}
+	}
+	return false
+}
+
+func FuzzIdentifyMessage(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _ = astm.IdentifyMessage(data, config)
+	})
+}
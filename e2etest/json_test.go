@@ -0,0 +1,161 @@
+package e2e
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blutspende/bloodlab-common/timezone"
+	"github.com/krendel52/go-astm/v3"
+	"github.com/krendel52/go-astm/v3/enums/jsonkeys"
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+	"github.com/krendel52/go-astm/v3/models/messageformat/yumizen"
+	"github.com/krendel52/go-astm/v3/streamcodec"
+	"github.com/stretchr/testify/assert"
+)
+
+const jsonResultMessage = "H|\\^&|||Sender|||||LIS|||LIS2-A2|20240709103536\n" +
+	"P|1||PID\n" +
+	"O|1|S1||^^^TSH|R||||||||||^||||||||||F\n" +
+	"R|1|^^^TSH|<0.01|mU/l|0.4-4.0|L||F||||20240625092245|\n" +
+	"L|1|N"
+
+func TestToJSONFieldNames(t *testing.T) {
+	// Arrange
+	var message lis02a2.ResultMessage
+	err := astm.Unmarshal([]byte(jsonResultMessage), &message, config)
+	assert.Nil(t, err)
+	// Act
+	jsonData, err := astm.ToJSON(message, config)
+	// Assert
+	assert.Nil(t, err)
+	var decoded map[string]interface{}
+	assert.Nil(t, json.Unmarshal(jsonData, &decoded))
+	result := decoded["PatientGroups"].([]interface{})[0].(map[string]interface{})["OrderGroups"].([]interface{})[0].(map[string]interface{})["ResultGroups"].([]interface{})[0].(map[string]interface{})["Result"].(map[string]interface{})
+	// Check the qualified value and the time in the configured time zone
+	assert.Equal(t, "<0.01", result["DataMeasurementValue"])
+	assert.Equal(t, "2024-06-25T09:22:45+02:00", result["DateTimeCompleted"])
+	assert.Equal(t, "0.4-4.0", result["ReferenceRange"].(map[string]interface{})["Range"])
+	assert.Nil(t, result["DateTimeTestStarted"])
+	// Check the readability and the field order
+	assert.Contains(t, string(jsonData), `"Escape":"&"`)
+	assert.Less(t, strings.Index(string(jsonData), `"Header"`), strings.Index(string(jsonData), `"Terminator"`))
+	// Check the raw line is not included if it is not captured
+	assert.NotContains(t, string(jsonData), `"Raw"`)
+}
+
+func TestToJSONPositions(t *testing.T) {
+	// Arrange
+	var message lis02a2.ResultMessage
+	err := astm.Unmarshal([]byte(jsonResultMessage), &message, config)
+	assert.Nil(t, err)
+	config.JSONKeys = jsonkeys.Positions
+	// Act
+	jsonData, err := astm.ToJSON(message, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Contains(t, string(jsonData), `"Result":{"3":{"1":"","2":"","3":"","4":"TSH","5":"","6":"","7":""},"4.1":"<0.01","4.2":"","4.3":"","5":"mU/l","6":{"1":"0.4-4.0","2":""},"7":"L"`)
+	assert.Contains(t, string(jsonData), `"Terminator":{"3":"N"}`)
+	// Teardown
+	teardown()
+}
+
+func TestToJSONTimeZone(t *testing.T) {
+	// Arrange
+	message := lis02a2.QueryMessage{
+		Header: lis02a2.Header{DateAndTime: time.Date(2024, 7, 9, 8, 35, 36, 0, time.UTC)},
+	}
+	config.TimeZone = timezone.AmericaNewYork
+	// Act
+	jsonData, err := astm.ToJSON(message, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Contains(t, string(jsonData), `"DateAndTime":"2024-07-09T04:35:36-04:00"`)
+	// Teardown
+	teardown()
+}
+
+func TestFromJSONMarshal(t *testing.T) {
+	// Arrange
+	for _, keys := range []string{jsonkeys.FieldNames, jsonkeys.Positions} {
+		config.JSONKeys = keys
+		var message lis02a2.ResultMessage
+		err := astm.Unmarshal([]byte(jsonResultMessage), &message, config)
+		assert.Nil(t, err)
+		jsonData, err := astm.ToJSON(message, config)
+		assert.Nil(t, err)
+		var converted lis02a2.ResultMessage
+		// Act
+		err = astm.FromJSON(jsonData, &converted, config)
+		lines, errMarshal := astm.Marshal(converted, config)
+		// Assert
+		assert.Nil(t, err, keys)
+		assert.Nil(t, errMarshal, keys)
+		expected, _ := astm.Marshal(message, config)
+		assert.Equal(t, expected, lines, keys)
+		assert.Equal(t, "R|1|^^^TSH^^^|<0.01^^|mU/l|0.4-4.0^|L||F||^||20240625092245|", string(lines[4]), keys)
+	}
+	// Teardown
+	teardown()
+}
+
+func TestFromJSONPreservesManufacturerStream(t *testing.T) {
+	// Arrange
+	values := []float32{1.5, 2.25, 3}
+	stream, err := streamcodec.NewFloat32Stream("FLOATLE-stream/deflate:base64", values)
+	assert.Nil(t, err)
+	message := yumizen.ResultMessage{}
+	message.PatientGroups = []yumizen.PatientGroup{{OrderGroups: []yumizen.OrderGroup{{Histograms: []yumizen.Stream{{StreamType: "HISTOGRAM", Name: "RBC", Contents: stream}}}}}}
+	jsonData, err := astm.ToJSON(message, config)
+	assert.Nil(t, err)
+	var converted yumizen.ResultMessage
+	// Act
+	err = astm.FromJSON(jsonData, &converted, config)
+	// Assert
+	assert.Nil(t, err)
+	convertedStream := converted.PatientGroups[0].OrderGroups[0].Histograms[0].Contents
+	assert.Equal(t, stream, convertedStream)
+	decoded, err := convertedStream.Float32()
+	assert.Nil(t, err)
+	assert.Equal(t, values, decoded)
+}
+
+func TestFromJSONErrors(t *testing.T) {
+	// Arrange
+	var message lis02a2.QueryMessage
+	// Act
+	errUnknown := astm.FromJSON([]byte(`{"Header":{"Unknown":"x"}}`), &message, config)
+	errInvalid := astm.FromJSON([]byte(`{"Header":{"SenderNameOrID":5}}`), &message, config)
+	errTime := astm.FromJSON([]byte(`{"Header":{"DateAndTime":"20240709"}}`), &message, config)
+	errTarget := astm.FromJSON([]byte(`{}`), message, config)
+	// Assert
+	assert.ErrorIs(t, errUnknown, errmsg.ErrJSONConversionUnknownKey)
+	assert.EqualError(t, errUnknown, `unknown json key: "Header.Unknown"`)
+	assert.ErrorIs(t, errInvalid, errmsg.ErrJSONConversionInvalidValue)
+	assert.EqualError(t, errInvalid, `invalid json value: "Header.SenderNameOrID"`)
+	assert.ErrorIs(t, errTime, errmsg.ErrJSONConversionInvalidValue)
+	assert.ErrorIs(t, errTarget, errmsg.ErrAnnotationParsingInvalidInputStruct)
+}
+
+func TestGenericJSON(t *testing.T) {
+	// Arrange
+	message := "H|\\^&|||Sender^1.0|\nR|1|^^^A&^B\\^^^C|va&|ue||\nL|1|N"
+	// Act
+	records, err := astm.UnmarshalGeneric([]byte(message), config)
+	jsonData, errJSON := json.Marshal(records)
+	var converted []astmmodels.GenericRecord
+	errFromJSON := json.Unmarshal(jsonData, &converted)
+	lines, errMarshal := astm.MarshalGeneric(converted, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, errJSON)
+	assert.Nil(t, errFromJSON)
+	assert.Nil(t, errMarshal)
+	assert.Contains(t, string(jsonData), `{"type":"R","fields":[[["R"]],[["1"]],[["","","","A^B"],["","","","C"]],[["va|ue"]],null,null]}`)
+	assert.Equal(t, "H|\\^&|||Sender^1.0|", string(lines[0]))
+	assert.Equal(t, "R|1|^^^A&^B\\^^^C|va&|ue||", string(lines[1]))
+	assert.Equal(t, "L|1|N", string(lines[2]))
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/krendel52/go-astm/v3"
 	"github.com/krendel52/go-astm/v3/enums/notation"
 	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+	"github.com/krendel52/go-astm/v3/models/messageformat/yumizen"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/text/encoding/charmap"
 	"testing"
@@ -617,3 +618,215 @@ func TestEscapedCharactersMessageMarshal(t *testing.T) {
 	// Teardown
 	teardown()
 }
+
+func TestMarshalScientificMessage(t *testing.T) {
+	// Arrange
+	message := lis02a2.ScientificMessage{
+		ScientificGroups: []lis02a2.ScientificGroup{
+			{
+				Scientific: lis02a2.Scientific{
+					AnalyticalMethod: "PHOTO",
+					SpecimenID:       "QC-0001",
+					Analyte:          "GLU",
+					Result:           "101.5",
+					ResultUnits:      "mg/dL",
+				},
+				Comments: []lis02a2.Comment{
+					{
+						CommentSource: "I",
+						CommentText:   "Control within range",
+					},
+				},
+			},
+			{
+				Scientific: lis02a2.Scientific{
+					SpecimenID: "QC-0002",
+				},
+			},
+		},
+		Terminator: lis02a2.Terminator{
+			TerminatorCode: "N",
+		},
+	}
+	config.Notation = notation.Short
+	// Act
+	lines, err := astm.Marshal(message, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, lines, 5)
+	assert.Equal(t, "S|1|PHOTO||||||||QC-0001|GLU|101.5|mg/dL", string(lines[1]))
+	assert.Equal(t, "C|1|I|Control within range", string(lines[2]))
+	assert.Equal(t, "S|2|||||||||QC-0002", string(lines[3]))
+	assert.Equal(t, "L|1|N", string(lines[4]))
+	// Teardown
+	teardown()
+}
+
+func TestMarshalQueryRequestInformationStatus(t *testing.T) {
+	// Arrange
+	var query lis02a2.QueryMessage
+	query.Terminator.TerminatorCode = "N"
+	query.Queries = []lis02a2.Query{
+		{
+			StartingRangeIDNumber:    "SampleCode1",
+			UniversalTestID:          "ALL",
+			RequestInformationStatus: lis02a2.RequestInformationStatusOrdersOnly,
+		},
+	}
+	// Act
+	lines, err := astm.Marshal(query, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "Q|1|SampleCode1||ALL||||||||O", string(lines[1]))
+	// Teardown
+	teardown()
+}
+
+func TestMarshalYumizenReagentLots(t *testing.T) {
+	// Arrange
+	message := struct {
+		Traceability yumizen.Traceability `astm:"M,subname:REAGENT"`
+	}{
+		Traceability: yumizen.Traceability{
+			RecordType:   "REAGENT",
+			ReagentNames: []string{"DILUENT", "LYSE"},
+			ReagentLots: []yumizen.ReagentLot{
+				{LotNumber: "240423H1(", OpenedAt: time.Date(2024, 9, 5, 0, 0, 0, 0, time.UTC), ExpiryDate: time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)},
+				{LotNumber: "240411M11", ExpiryDate: time.Date(2024, 10, 28, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+	}
+	config.TimeZone = timezone.UTC
+	// Act
+	lines, err := astm.Marshal(message, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "M|1|REAGENT|DILUENT\\LYSE|240423H1(^20240905000000^20250305\\240411M11^^20241028", string(lines[0]))
+	// Teardown
+	teardown()
+}
+
+func TestMarshalBioRadComponentArrays(t *testing.T) {
+	// Arrange
+	message := struct {
+		Order struct {
+			SpecimenID            string   `astm:"3"`
+			InstrumentSpecimenIDs []string `astm:"4.1"`
+		} `astm:"O"`
+	}{}
+	message.Order.SpecimenID = "1122206642"
+	message.Order.InstrumentSpecimenIDs = []string{"1122206642", "1122206643"}
+	// Act
+	lines, err := astm.Marshal(message, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "O|1|1122206642|1122206642\\1122206643", string(lines[0]))
+}
+
+func TestMarshalOmitemptyAndDefault(t *testing.T) {
+	// Arrange
+	message := struct {
+		Result struct {
+			Value  float64                    `astm:"3,omitempty"`
+			Count  int                        `astm:"4,omitempty"`
+			Status lis02a2.ResultStatus       `astm:"5,default:F"`
+			Flag   lis02a2.ResultAbnormalFlag `astm:"6,default:N"`
+		} `astm:"R"`
+	}{}
+	message.Result.Flag = lis02a2.ResultAbnormalFlagHigh
+	// Act
+	lines, err := astm.Marshal(message, config)
+	var parsed struct {
+		Result struct {
+			Value  float64                    `astm:"3,omitempty"`
+			Count  int                        `astm:"4,omitempty"`
+			Status lis02a2.ResultStatus       `astm:"5,default:F"`
+			Flag   lis02a2.ResultAbnormalFlag `astm:"6,default:N"`
+		} `astm:"R"`
+	}
+	errUnmarshal := astm.Unmarshal([]byte("R|1||||\n"), &parsed, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "R|1|||F|H", string(lines[0]))
+	assert.Nil(t, errUnmarshal)
+	assert.Equal(t, lis02a2.ResultStatusFinal, parsed.Result.Status)
+	assert.Equal(t, lis02a2.ResultAbnormalFlagNormal, parsed.Result.Flag)
+}
+
+func TestUnmarshalModifyMarshalPreservesFormatting(t *testing.T) {
+	// Arrange
+	messageString := "H/!*%///Sender/////////220311090000\n"
+	messageString += "P/1//PID  //Last*First//19590422/M///\n"
+	messageString += "O/1/SID//***ABO/R/220311103217\n"
+	messageString += "R/1/***ABO/A///N//F//user//20220311114103/\n"
+	messageString += "L/1/N \n"
+	config.PreserveRawRecords = true
+	var message lis02a2.ResultMessage
+	// Act
+	errUnmarshal := astm.Unmarshal([]byte(messageString), &message, config)
+	message.PatientGroups[0].OrderGroups[0].ResultGroups[0].Result.DataMeasurementValue = "B"
+	lines, errMarshal := astm.Marshal(message, config)
+	// Assert
+	assert.Nil(t, errUnmarshal)
+	assert.Nil(t, errMarshal)
+	assert.Len(t, lines, 5)
+	assert.Equal(t, "H/!*%///Sender/////////220311090000", string(lines[0]))
+	assert.Equal(t, "P/1//PID  //Last*First//19590422/M///", string(lines[1]))
+	assert.Equal(t, "O/1/SID//***ABO/R/220311103217", string(lines[2]))
+	// Check only the changed field is rendered again (without more components than the original one)
+	assert.Equal(t, "R/1/***ABO/B///N//F//user//20220311114103/", string(lines[3]))
+	assert.Equal(t, "L/1/N ", string(lines[4]))
+	// Teardown
+	teardown()
+}
+
+func TestUnmarshalModifyMarshalKeepsComponentCount(t *testing.T) {
+	// Arrange
+	messageString := "H|\\^&|||Sender\n"
+	messageString += "P|1||PID\n"
+	messageString += "O|1|SID||^^^TSH\n"
+	messageString += "R|1|^^^TSH|7.410|mIU/L||N||F\n"
+	messageString += "L|1|N\n"
+	config.PreserveRawRecords = true
+	var message lis02a2.ResultMessage
+	// Act
+	errUnmarshal := astm.Unmarshal([]byte(messageString), &message, config)
+	result := &message.PatientGroups[0].OrderGroups[0].ResultGroups[0].Result
+	result.DataMeasurementValue = "7.5"
+	result.ResultStatus = lis02a2.ResultStatusCorrection
+	lines, errMarshal := astm.Marshal(message, config)
+	// Assert
+	assert.Nil(t, errUnmarshal)
+	assert.Nil(t, errMarshal)
+	assert.Len(t, lines, 5)
+	assert.Equal(t, "R|1|^^^TSH|7.5|mIU/L||N||C", string(lines[3]))
+	// Teardown
+	teardown()
+}
+
+func TestMarshalPreservationAddsNewRecords(t *testing.T) {
+	// Arrange
+	messageString := "H|\\^&|||Sender\n"
+	messageString += "P|1||PID\n"
+	messageString += "O|1|SID||^^^ABO\n"
+	messageString += "L|1|N\n"
+	config.PreserveRawRecords = true
+	var message lis02a2.ResultMessage
+	// Act
+	errUnmarshal := astm.Unmarshal([]byte(messageString), &message, config)
+	orderGroup := &message.PatientGroups[0].OrderGroups[0]
+	orderGroup.ResultGroups = append(orderGroup.ResultGroups, lis02a2.ResultGroup{
+		Result: lis02a2.Result{DataMeasurementValue: "A"},
+	})
+	config.Notation = notation.Short
+	lines, errMarshal := astm.Marshal(message, config)
+	// Assert
+	assert.Nil(t, errUnmarshal)
+	assert.Nil(t, errMarshal)
+	assert.Len(t, lines, 5)
+	assert.Equal(t, "O|1|SID||^^^ABO", string(lines[2]))
+	assert.Equal(t, "R|1||A", string(lines[3]))
+	assert.Equal(t, "L|1|N", string(lines[4]))
+	// Teardown
+	teardown()
+}
@@ -4,8 +4,13 @@ import (
 	"github.com/blutspende/bloodlab-common/encoding"
 	"github.com/blutspende/bloodlab-common/timezone"
 	"github.com/krendel52/go-astm/v3"
+	"github.com/krendel52/go-astm/v3/enums/notation"
 	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"github.com/krendel52/go-astm/v3/models/messageformat/biorad"
 	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+	"github.com/krendel52/go-astm/v3/models/messageformat/yumizen"
+	"github.com/krendel52/go-astm/v3/streamcodec"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/text/encoding/charmap"
 	"testing"
@@ -173,6 +178,30 @@ func TestCustomDelimiters(t *testing.T) {
 	assert.Equal(t, "Test", message.Patient.FirstName)
 }
 
+func TestUnmarshalMarshalLegacyDelimitersUsesConfiguration(t *testing.T) {
+	// Arrange
+	messageString := "H|\\^&|||Sender\n"
+	messageString += "P|1||PID||Last^First\n"
+	messageString += "L|1|N\n"
+	var message MessageCustomDelimiterTest
+	config.Notation = notation.Short
+	// Act
+	err := astm.Unmarshal([]byte(messageString), &message, config)
+	config.Delimiters = astmmodels.Delimiters{Field: "/", Repeat: "!", Component: "*", Escape: "%"}
+	lines, errMarshal := astm.Marshal(message, config)
+	// Assert
+	assert.Nil(t, err)
+	// The legacy Delimiters field is filled by unmarshal
+	assert.Equal(t, "|", message.Header.Delimiters.Field)
+	// But only the configured delimiters are used in marshal
+	assert.Nil(t, errMarshal)
+	assert.Equal(t, "H/!*%///Sender", string(lines[0]))
+	assert.Equal(t, "P/1//PID//Last*First", string(lines[1]))
+	assert.Equal(t, "L/1/N", string(lines[2]))
+	// Teardown
+	teardown()
+}
+
 type CompleteOutOfStandardCustomRecord struct {
 	F2           string  `astm:"3"`
 	F3           string  `astm:"4"`
@@ -687,3 +716,388 @@ L|1|N `
 	assert.Nil(t, err)
 	assert.Equal(t, "ABOD|Full&Interp", message.PatientGroups[0].OrderGroups[0].ResultGroups[0].Result.UniversalTestID.ManufacturersTestType)
 }
+
+func TestUnmarshalScientificMessage(t *testing.T) {
+	// Arrange
+	messageString := "H|\\^&|||QC-Analyzer|||||LIS||P|LIS2-A2|20240912070504\n"
+	messageString += "S|1|PHOTO|ANALYZER1^A1|REAGENT-LOT-42|mg/dL|QC^LEVEL1|SERUM||TUBE|QC-0001|GLU|101.5|mg/dL|20240912070000|20240912070400\n"
+	messageString += "C|1|I|Control within range|G\n"
+	messageString += "S|2|PHOTO|ANALYZER1^A1|REAGENT-LOT-42|mg/dL|QC^LEVEL2|SERUM||TUBE|QC-0002|GLU|298.0|mg/dL|20240912070000|20240912070430\n"
+	messageString += "L|1|N\n"
+	var message lis02a2.ScientificMessage
+	// Act
+	err := astm.Unmarshal([]byte(messageString), &message, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, message.ScientificGroups, 2)
+	assert.Equal(t, "QC-0001", message.ScientificGroups[0].Scientific.SpecimenID)
+	assert.Equal(t, "101.5", message.ScientificGroups[0].Scientific.Result)
+	assert.Equal(t, "QC^LEVEL1", message.ScientificGroups[0].Scientific.QualityControl)
+	assert.Len(t, message.ScientificGroups[0].Comments, 1)
+	assert.Equal(t, "Control within range", message.ScientificGroups[0].Comments[0].CommentText)
+	assert.Equal(t, "GLU", message.ScientificGroups[1].Scientific.Analyte)
+	expectedTime := time.Date(2024, 9, 12, 7, 4, 30, 0, config.TimeLocation).UTC()
+	assert.Equal(t, expectedTime, message.ScientificGroups[1].Scientific.ResultDateTime)
+}
+
+func TestUnmarshalQueryRequestInformationStatus(t *testing.T) {
+	// Arrange
+	messageString := "H|\\^&|||LIS|||||Analyzer||P|LIS2-A2|20240912070504\n"
+	messageString += "Q|1|^SAMPLE1||ALL||||||||O\n"
+	messageString += "L|1|N\n"
+	var message lis02a2.QueryMessage
+	// Act
+	err := astm.Unmarshal([]byte(messageString), &message, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, lis02a2.RequestInformationStatusOrdersOnly, message.Queries[0].RequestInformationStatus)
+}
+
+func TestUnmarshalCodedFields(t *testing.T) {
+	// Arrange
+	messageString := "H|\\^&|||Echo|||||LIS||P|LIS2-A2|20060306164429\n"
+	messageString += "P|1|1171984|||Patient^Test||19590422|M\n"
+	messageString += "O|1|0651439A||^^^ABOD Full|S||||||A||||Blood^Patient||||||||||F\n"
+	messageString += "R|1|^^^Anti-A|0^0^0|||HH||F||brentp||20060306164429|M0002\n"
+	messageString += "L|1|N\n"
+	var message lis02a2.ResultMessage
+	config.ValidateEnums = true
+	// Act
+	err := astm.Unmarshal([]byte(messageString), &message, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, lis02a2.ProcessingIDProduction, message.Header.ProcessingID)
+	order := message.PatientGroups[0].OrderGroups[0].Order
+	assert.Equal(t, lis02a2.PriorityStat, order.Priority)
+	assert.Equal(t, lis02a2.ActionCodeAdd, order.ActionCode)
+	assert.Equal(t, lis02a2.ReportTypeFinal, order.ReportType)
+	result := message.PatientGroups[0].OrderGroups[0].ResultGroups[0].Result
+	assert.Equal(t, lis02a2.ResultAbnormalFlagPanicHigh, result.ResultAbnormalFlag)
+	assert.Equal(t, lis02a2.ResultStatusFinal, result.ResultStatus)
+	assert.Equal(t, lis02a2.TerminatorCodeNormal, message.Terminator.TerminatorCode)
+	// Teardown
+	teardown()
+}
+
+func TestUnmarshalInvalidCodedFieldWithValidation(t *testing.T) {
+	// Arrange
+	messageString := "H|\\^&|||Echo|||||LIS||P|LIS2-A2|20060306164429\n"
+	messageString += "P|1|1171984|||Patient^Test||19590422|M\n"
+	messageString += "O|1|0651439A||^^^ABOD Full|Z||||||N||||Blood^Patient\n"
+	messageString += "L|1|N\n"
+	var message lis02a2.OrderMessage
+	config.ValidateEnums = true
+	// Act
+	err := astm.Unmarshal([]byte(messageString), &message, config)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLineParsingInvalidEnumValue)
+	// Teardown
+	teardown()
+}
+
+func TestUnmarshalInvalidCodedFieldWithoutValidation(t *testing.T) {
+	// Arrange
+	messageString := "H|\\^&|||Echo|||||LIS||P|LIS2-A2|20060306164429\n"
+	messageString += "P|1|1171984|||Patient^Test||19590422|M\n"
+	messageString += "O|1|0651439A||^^^ABOD Full|Z||||||N||||Blood^Patient\n"
+	messageString += "L|1|N\n"
+	var message lis02a2.OrderMessage
+	// Act
+	err := astm.Unmarshal([]byte(messageString), &message, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, lis02a2.Priority("Z"), message.PatientOrders[0].Orders[0].Priority)
+	assert.False(t, message.PatientOrders[0].Orders[0].Priority.IsValid())
+}
+
+func TestUnmarshalAndMarshalReferenceRange(t *testing.T) {
+	// Arrange
+	messageString := "H|\\^&|||H550^909YAXH02732^1.2.1.4|||||||Q|LIS2-A2|20240912070504\n"
+	messageString += "P|1\n"
+	messageString += "O|1|PX449L||^^^DIF|R|20240912070343\n"
+	messageString += "R|1|^^^MCV^787-2|78.4|um3|73.5-83.5^REFERENCE_RANGE|N||F||LABOR^^USER|20240912070343\n"
+	messageString += "R|2|^^^CRP|7|mg/L|<5|H||F\n"
+	messageString += "L|1|N\n"
+	var message lis02a2.ResultMessage
+	config.Notation = notation.Short
+	// Act
+	err := astm.Unmarshal([]byte(messageString), &message, config)
+	lines, errMarshal := astm.Marshal(message, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, errMarshal)
+	results := message.PatientGroups[0].OrderGroups[0].ResultGroups
+	assert.Equal(t, "73.5-83.5", results[0].Result.ReferenceRange.Range)
+	assert.Equal(t, "REFERENCE_RANGE", results[0].Result.ReferenceRange.Suffix)
+	flag, err := results[0].Result.EvaluateAbnormalFlag()
+	assert.Nil(t, err)
+	assert.Equal(t, results[0].Result.ResultAbnormalFlag, flag)
+	flag, err = results[1].Result.EvaluateAbnormalFlag()
+	assert.Nil(t, err)
+	assert.Equal(t, lis02a2.ResultAbnormalFlagHigh, flag)
+	assert.Equal(t, "R|1|^^^MCV^787-2|78.4|um3|73.5-83.5^REFERENCE_RANGE|N||F||LABOR|20240912070343", string(lines[4]))
+	assert.Equal(t, "R|2|^^^CRP|7|mg/L|<5|H||F", string(lines[5]))
+	// Teardown
+	teardown()
+}
+
+type YumizenStreamRecord struct {
+	Type     string             `astm:"3"`
+	Group    string             `astm:"4"`
+	Name     string             `astm:"5"`
+	Header   streamcodec.Stream `astm:"6"`
+	Contents streamcodec.Stream `astm:"7"`
+}
+
+func TestUnmarshalAndDecodeManufacturerStream(t *testing.T) {
+	// Arrange
+	messageString := "M|1|MATRIX|LMNE|LMNEResAbs|FLOATLE-stream/deflate:base64^Y2AAggf/XRjgtIMDiAkA|FLOATLE-stream/deflate:base64^Y2AAAQ4nMMXQA6IdgMghPS3IoXrKWsdVH00cIXIN9iA5AA==\n"
+	var message struct {
+		Stream YumizenStreamRecord `astm:"M"`
+	}
+	// Act
+	err := astm.Unmarshal([]byte(messageString), &message, config)
+	header, errHeader := message.Stream.Header.Values()
+	contents, errContents := message.Stream.Contents.Float32()
+	lines, errMarshal := astm.Marshal(message, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, errHeader)
+	assert.Nil(t, errContents)
+	assert.Nil(t, errMarshal)
+	assert.Equal(t, []float64{0, 2047, 0, 2047, 3, 0}, header)
+	assert.Len(t, contents, 12)
+	assert.Equal(t, float32(21.6975), contents[7])
+	assert.Equal(t, messageString, string(lines[0])+"\n")
+}
+
+func TestUnmarshalBioRadBloodGroupResult(t *testing.T) {
+	// Arrange
+	messageString := "H|\\^&|||Bio-Rad|IH v5.2||||||||20220315194227\n"
+	messageString += "P|1||1010868845||Testus^Test||19400607|M\n"
+	messageString += "O|1|1122206642|1122206642^^^\\1122206642^^^|^^^MO10^^28343^|R|20220311103217|20220311103217|||||||||||11||||20220311114103|||P\n"
+	messageString += "R|1|^^^AntiA^MO10^Bloodgroup: A,B,D Confirmation for Patients (DiaClon) (5005)^|40^^|C||||R||lalina^|20220311114103||11|IH-1000|0300768|lalina\n"
+	messageString += "C|1|ID-Diluent 2^^05761.03.12^20240131\\^^^|CAS^5005352062212117030^50053.52.06^20221231^4||\n"
+	messageString += "R|2|^^^Result^MO10^Bloodgroup: A,B,D Confirmation for Patients (DiaClon) (5005)^|A^NEG^NEG^ccee^K-^NEG^^^|C||||S||lalina^|20220311114103||11|IH-1000|0300768|lalina\n"
+	messageString += "C|1|^^^||\n"
+	messageString += "L|1|N\n"
+	var message biorad.ResultMessage
+	// Act
+	err := astm.Unmarshal([]byte(messageString), &message, config)
+	// Assert
+	assert.Nil(t, err)
+	orderGroup := message.PatientGroups[0].OrderGroups[0]
+	reaction := orderGroup.ResultGroups[0]
+	assert.Equal(t, "40", reaction.Result.Value.Value)
+	assert.Equal(t, "IH-1000", reaction.Result.InstrumentType)
+	assert.Equal(t, "0300768", reaction.Result.InstrumentSerialNumber)
+	// Check the reagent and cassette lots
+	assert.Len(t, reaction.Comments[0].Reagents, 2)
+	assert.Equal(t, "ID-Diluent 2", reaction.Comments[0].Reagents[0].Name)
+	assert.Equal(t, "05761.03.12", reaction.Comments[0].Reagents[0].LotNumber)
+	assert.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, config.TimeLocation), reaction.Comments[0].Reagents[0].ExpiryDate)
+	assert.Equal(t, "5005352062212117030", reaction.Comments[0].Cassette.ID)
+	assert.Equal(t, "50053.52.06", reaction.Comments[0].Cassette.LotNumber)
+	assert.Equal(t, time.Date(2022, 12, 31, 0, 0, 0, 0, config.TimeLocation), reaction.Comments[0].Cassette.ExpiryDate)
+	assert.Equal(t, "4", reaction.Comments[0].Cassette.Column)
+	// Check the blood group interpretation
+	bloodGroup, found, err := orderGroup.BloodGroup()
+	assert.Nil(t, err)
+	assert.True(t, found)
+	assert.Equal(t, biorad.ABOGroupA, bloodGroup.ABO)
+	assert.Equal(t, biorad.ReactionNegative, bloodGroup.RhD)
+	assert.Equal(t, biorad.ReactionNegative, bloodGroup.RhDConfirmation)
+	assert.Equal(t, "ccee", bloodGroup.Phenotype.String())
+	assert.Equal(t, biorad.KellNegative, bloodGroup.Kell)
+	assert.True(t, bloodGroup.IsValid())
+}
+
+func TestUnmarshalYumizenReagentLots(t *testing.T) {
+	// Arrange
+	messageString := "H|\\^&|||H550^909YAXH02732^1.2.1.4|||||||Q|LIS2-A2|20240912070504\n"
+	messageString += "P|1\n"
+	messageString += "O|1|PX449L||^^^DIF|R|20240912070343|||||||||CTRL^^CTRLLOW||||||||||F\n"
+	messageString += "M|1|REAGENT|CLEANER\\DILUENT\\LYSE|240415I1(^20240902000000^20241202\\240423H1(^20240905000000^20250305\\240411M11^20240828000000^20241028\n"
+	messageString += "R|1|^^^MCV^787-2|78.4|um3|73.5-83.5^REFERENCE_RANGE|N||F||LABOR^^USER|20240912070343\n"
+	messageString += "L|1\n"
+	var message yumizen.ResultMessage
+	config.EnforceSequenceNumberCheck = false
+	// Act
+	err := astm.Unmarshal([]byte(messageString), &message, config)
+	// Assert
+	assert.Nil(t, err)
+	traceability := message.PatientGroups[0].OrderGroups[0].Traceability
+	assert.Len(t, traceability.ReagentLots, 3)
+	assert.Equal(t, "240415I1(", traceability.ReagentLots[0].LotNumber)
+	assert.Equal(t, time.Date(2024, 9, 2, 0, 0, 0, 0, config.TimeLocation).UTC(), traceability.ReagentLots[0].OpenedAt)
+	assert.Equal(t, time.Date(2024, 12, 2, 0, 0, 0, 0, config.TimeLocation), traceability.ReagentLots[0].ExpiryDate)
+	assert.Equal(t, "240411M11", traceability.ReagentLots[2].LotNumber)
+	assert.Equal(t, "LYSE", traceability.Reagents()[2].Name)
+	assert.Nil(t, traceability.Validate(time.Date(2024, 9, 12, 0, 0, 0, 0, config.TimeLocation)))
+	// Teardown
+	teardown()
+}
+
+type NestedReagent struct {
+	Name  string   `astm:"1"`
+	Lots  []string `astm:"2"`
+	Usage string   `astm:"3"`
+}
+type NestedReagentMessage struct {
+	Header       lis02a2.Header `astm:"H"`
+	Manufacturer struct {
+		SpecimenIDs []lis02a2.StandardUniversalTestID `astm:"3"`
+		Reagents    []NestedReagent                   `astm:"4"`
+	} `astm:"M"`
+	Terminator lis02a2.Terminator `astm:"L"`
+}
+
+func TestUnmarshalMarshalNestedRepeatsAndComponents(t *testing.T) {
+	// Arrange
+	messageString := "H|\\^&|||Sender\n"
+	messageString += "M|1|1122206642^^^\\1122206642^^^|DILUENT^L1~L2^daily\\LYSE^L3^weekly\n"
+	messageString += "L|1|N\n"
+	var message NestedReagentMessage
+	config.Delimiters.SubComponent = "~"
+	config.Notation = notation.Short
+	// Act
+	err := astm.Unmarshal([]byte(messageString), &message, config)
+	lines, errMarshal := astm.Marshal(message, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, message.Manufacturer.SpecimenIDs, 2)
+	assert.Equal(t, "1122206642", message.Manufacturer.SpecimenIDs[1].UniversalTestID)
+	assert.Equal(t, []string{"L1", "L2"}, message.Manufacturer.Reagents[0].Lots)
+	assert.Equal(t, "weekly", message.Manufacturer.Reagents[1].Usage)
+	assert.Nil(t, errMarshal)
+	assert.Equal(t, "M|1|1122206642\\1122206642|DILUENT^L1~L2^daily\\LYSE^L3^weekly", string(lines[1]))
+	// Teardown
+	teardown()
+}
+
+type BioRadOrderSpecimens struct {
+	SpecimenID            string   `astm:"3"`
+	InstrumentSpecimenIDs []string `astm:"4.1"`
+	TestCode              string   `astm:"5.4"`
+}
+type BioRadResultOperators struct {
+	TestType     string   `astm:"3.4"`
+	Performed    []string `astm:"11.1"`
+	Verified     []string `astm:"11.2"`
+	Instrument   string   `astm:"15"`
+	SerialNumber string   `astm:"16"`
+}
+type BioRadComponentArrayMessage struct {
+	Header        lis02a2.Header `astm:"H"`
+	PatientGroups []struct {
+		Patient     lis02a2.Patient `astm:"P"`
+		OrderGroups []struct {
+			Order        BioRadOrderSpecimens `astm:"O"`
+			ResultGroups []struct {
+				Result   BioRadResultOperators `astm:"R"`
+				Comments []biorad.Comment      `astm:"C,optional"`
+			}
+		}
+	}
+	Terminator lis02a2.Terminator `astm:"L"`
+}
+
+func TestUnmarshalBioRadComponentArrays(t *testing.T) {
+	// Arrange
+	messageString := "H|\\^&|||Bio-Rad|IH v5.2||||||||20220315194227\n"
+	messageString += "P|1||1010868845||Testus^Test||19400607|M||||||||||||||||||||||||^\n"
+	messageString += "O|1|1122206642|1122206642^^^\\1122206643^^^|^^^MO10^^28343^|R|20220311103217|20220311103217|||||||||||11||||20220311114103|||P\n"
+	messageString += "R|1|^^^AntiA^MO10^Bloodgroup: A,B,D Confirmation for Patients (DiaClon) (5005)^|40^^|C||||R||lalina^\\supervisor^lalina|20220311114103||11|IH-1000|0300768|lalina\n"
+	messageString += "C|1|ID-Diluent 2^^05761.03.12^20240131\\^^^|CAS^5005352062212117030^50053.52.06^20221231^4||\n"
+	messageString += "L|1|N\n"
+	var message BioRadComponentArrayMessage
+	// Act
+	err := astm.Unmarshal([]byte(messageString), &message, config)
+	// Assert
+	assert.Nil(t, err)
+	order := message.PatientGroups[0].OrderGroups[0].Order
+	assert.Equal(t, "1122206642", order.SpecimenID)
+	assert.Equal(t, []string{"1122206642", "1122206643"}, order.InstrumentSpecimenIDs)
+	assert.Equal(t, "MO10", order.TestCode)
+	result := message.PatientGroups[0].OrderGroups[0].ResultGroups[0].Result
+	assert.Equal(t, "AntiA", result.TestType)
+	assert.Equal(t, []string{"lalina", "supervisor"}, result.Performed)
+	assert.Equal(t, []string{"", "lalina"}, result.Verified)
+	assert.Equal(t, "IH-1000", result.Instrument)
+}
+
+type CustomHeader struct {
+	SenderName string `astm:"5"`
+}
+type CustomHeaderMessage struct {
+	Header     CustomHeader       `astm:"H"`
+	Patient    lis02a2.Patient    `astm:"P"`
+	Terminator lis02a2.Terminator `astm:"L"`
+}
+type CapturingHeader struct {
+	Delimiters astmmodels.Delimiters `astm:"2,delimiters"`
+	SenderName string                `astm:"5"`
+}
+type CapturingHeaderMessage struct {
+	Header     CapturingHeader    `astm:"H"`
+	Patient    lis02a2.Patient    `astm:"P"`
+	Terminator lis02a2.Terminator `astm:"L"`
+}
+
+func TestUnmarshalCustomHeaderWithoutDelimiters(t *testing.T) {
+	// Arrange
+	messageString := "H|\\^&|||Sender\n"
+	messageString += "P|1||PID||Last^First\n"
+	messageString += "L|1|N\n"
+	var message CustomHeaderMessage
+	// Act
+	err := astm.Unmarshal([]byte(messageString), &message, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "Sender", message.Header.SenderName)
+	assert.Equal(t, "Last", message.Patient.LastName)
+}
+
+func TestUnmarshalMarshalCapturedDelimiters(t *testing.T) {
+	// Arrange
+	messageString := "H/!*%///Sender\n"
+	messageString += "P/1//PID//Last*First\n"
+	messageString += "L/1/N\n"
+	var message CapturingHeaderMessage
+	config.Notation = notation.Short
+	// Act
+	err := astm.Unmarshal([]byte(messageString), &message, config)
+	lines, errMarshal := astm.Marshal(message, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "/", message.Header.Delimiters.Field)
+	assert.Equal(t, "First", message.Patient.FirstName)
+	// Check the marshal uses the captured delimiters
+	assert.Nil(t, errMarshal)
+	assert.Equal(t, "H/!*%///Sender", string(lines[0]))
+	assert.Equal(t, "P/1//PID//Last*First", string(lines[1]))
+	assert.Equal(t, "L/1/N", string(lines[2]))
+	// Teardown
+	teardown()
+}
+
+func TestUnmarshalMalformedInputReturnsError(t *testing.T) {
+	// Arrange
+	messages := []string{
+		"H|",
+		"H|\\",
+		"\\|",
+		"H\n",
+	}
+	// Act & Assert
+	for _, messageString := range messages {
+		var message lis02a2.QueryMessage
+		assert.NotPanics(t, func() {
+			err := astm.Unmarshal([]byte(messageString), &message, config)
+			assert.NotNil(t, err, messageString)
+			assert.NotErrorIs(t, err, errmsg.ErrUnmarshalUnexpectedFailure, messageString)
+		})
+	}
+	// Teardown
+	teardown()
+}
@@ -0,0 +1,63 @@
+package e2e
+
+import (
+	"testing"
+	"time"
+
+	"github.com/krendel52/go-astm/v3"
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateOrderMessage(t *testing.T) {
+	// Arrange
+	msg := lis02a2.OrderMessage{
+		PatientOrders: []lis02a2.PatientOrder{
+			{
+				Patient: lis02a2.Patient{LabAssignedPatientID: "Mate"},
+				Orders: []lis02a2.Order{
+					{SpecimenID: "Samplecode1", Priority: lis02a2.PriorityRoutine},
+					{SpecimenID: "Samplecode1", Priority: lis02a2.PriorityStat},
+				},
+			},
+			{
+				Patient: lis02a2.Patient{LabAssignedPatientID: "Stephan"},
+				Orders: []lis02a2.Order{
+					{SpecimenID: "Samplecode2", RequestedOrderDateTime: time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)},
+				},
+			},
+		},
+	}
+	// Act
+	report, err := astm.Validate(msg, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, report.IsValid())
+}
+
+func TestValidateOrderMessageReportsAllIssues(t *testing.T) {
+	// Arrange
+	msg := lis02a2.OrderMessage{
+		PatientOrders: []lis02a2.PatientOrder{
+			{
+				Patient: lis02a2.Patient{LabAssignedPatientID: "Mate|Stephan"},
+				Orders: []lis02a2.Order{
+					{SpecimenID: "Samplecode1", Priority: "X"},
+					{SpecimenID: "Samplecode2", RequestedOrderDateTime: time.Date(1, 1, 1, 0, 0, 1, 0, time.UTC)},
+				},
+			},
+		},
+	}
+	// Act
+	report, err := astm.Validate(msg, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, report.Issues, 3)
+	assert.ErrorIs(t, report.Issues[0], errmsg.ErrValidationUnescapedDelimiter)
+	assert.Equal(t, "PatientOrders[0].Patient.LabAssignedPatientID", report.Issues[0].Path)
+	assert.ErrorIs(t, report.Issues[1], errmsg.ErrValidationInvalidEnumValue)
+	assert.Equal(t, "PatientOrders[0].Orders[0].Priority", report.Issues[1].Path)
+	assert.ErrorIs(t, report.Issues[2], errmsg.ErrValidationDateOutOfRange)
+	assert.Equal(t, "PatientOrders[0].Orders[1].RequestedOrderDateTime", report.Issues[2].Path)
+}
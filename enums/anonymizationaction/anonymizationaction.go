@@ -0,0 +1,5 @@
+package anonymizationaction
+
+const Pseudonymize string = "PSEUDONYMIZE"
+const ShiftDate string = "SHIFTDATE"
+const Clear string = "CLEAR"
@@ -0,0 +1,5 @@
+package hl7messagetype
+
+const ORUR01 string = "ORU^R01"
+const OULR22 string = "OUL^R22"
+const OMLO21 string = "OML^O21"
@@ -0,0 +1,4 @@
+package jsonkeys
+
+const FieldNames string = "FIELDNAMES"
+const Positions string = "POSITIONS"
@@ -0,0 +1,5 @@
+package lis1arole
+
+// The instrument has priority in a line contention, the computer system yields
+const Instrument string = "INSTRUMENT"
+const Computer string = "COMPUTER"
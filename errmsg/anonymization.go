@@ -0,0 +1,10 @@
+package errmsg
+
+import "errors"
+
+// Anonymization
+var (
+	ErrAnonymizationMissingKey    = errors.New("anonymization key is missing")
+	ErrAnonymizationInvalidRule   = errors.New("invalid anonymization rule")
+	ErrAnonymizationUnknownAction = errors.New("unknown anonymization action")
+)
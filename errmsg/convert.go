@@ -0,0 +1,12 @@
+package errmsg
+
+import "errors"
+
+// HL7Conversion
+var (
+	ErrHL7ConversionInvalidMessage         = errors.New("invalid hl7 message")
+	ErrHL7ConversionInvalidPosition        = errors.New("invalid hl7 mapping position")
+	ErrHL7ConversionUnsupportedMessageType = errors.New("unsupported hl7 message type")
+	ErrHL7ConversionMultipleMessages       = errors.New("only a single astm message can be converted")
+	ErrHL7ConversionInvalidTimestamp       = errors.New("invalid hl7 timestamp")
+)
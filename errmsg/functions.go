@@ -16,10 +16,21 @@ var (
 	ErrAnnotationParsingInvalidAstmAttribute         = errors.New("invalid astm attribute")
 	ErrAnnotationParsingInvalidAstmAttributeFormat   = errors.New("invalid astm attribute format")
 	ErrAnnotationParsingInvalidInputStruct           = errors.New("invalid input struct")
-	ErrAnnotationParsingIllegalComponentArray        = errors.New("component array is not allowed")
+	ErrAnnotationParsingIllegalComponentArray        = errors.New("component array is only allowed as a slice of simple values")
 	ErrAnnotationParsingIllegalComponentSubstructure = errors.New("component substructure is not allowed")
 )
 
+// AnnotationLinting
+var (
+	ErrAnnotationLintingInvalidType            = errors.New("type is not a struct")
+	ErrAnnotationLintingDuplicateFieldPos      = errors.New("duplicate field position")
+	ErrAnnotationLintingComponentCollision     = errors.New("component position collides with a plain field")
+	ErrAnnotationLintingReservedFieldPos       = errors.New("field position 1 and 2 are reserved")
+	ErrAnnotationLintingInvalidPosition        = errors.New("field and component positions start at 1")
+	ErrAnnotationLintingLengthOnNonFloat       = errors.New("length attribute on a non-float field")
+	ErrAnnotationLintingInvalidDelimitersField = errors.New("delimiters attribute is only allowed on a Delimiters field at position 2 of the header")
+)
+
 // LineParsing
 var (
 	ErrLineParsingEmptyInput                  = errors.New("empty input")
@@ -33,6 +44,10 @@ var (
 	ErrLineParsingInvalidDateFormat           = errors.New("invalid date format")
 	ErrLineParsingUnsupportedDataType         = errors.New("unsupported data type")
 	ErrLineParsingReservedFieldPosReference   = errors.New("field position 1 and 2 are reserved")
+	ErrLineParsingInvalidEnumValue            = errors.New("invalid enum value")
+	ErrLineParsingNestingTooDeep              = errors.New("nesting too deep: no delimiter left for the nesting level")
+	ErrLineParsingInvalidCharset              = errors.New("characters not allowed by the charset")
+	ErrLineParsingUnsupportedCharset          = errors.New("unsupported charset")
 )
 
 // StructureParsing
@@ -42,10 +57,41 @@ var (
 	ErrStructureParsingLineTypeNameMismatch = errors.New("line type name mismatch")
 )
 
+// Unmarshalling
+var (
+	ErrUnmarshalUnexpectedFailure = errors.New("unexpected failure while unmarshalling")
+)
+
 // LineBuilding
 var (
 	ErrLineBuildingInvalidDateFormat           = errors.New("invalid date format")
 	ErrLineBuildingUsupportedDataType          = errors.New("unsupported data type")
 	ErrLineBuildingReservedFieldPosReference   = errors.New("field position 1 and 2 are reserved")
 	ErrLineBuildingInvalidLengthAttributeValue = errors.New("invalid length attribute value")
+	ErrLineBuildingNestingTooDeep              = errors.New("nesting too deep: no delimiter left for the nesting level")
+	ErrLineBuildingInvalidMaxlenAttributeValue = errors.New("invalid maxlen attribute value")
+	ErrLineBuildingMaxLengthExceeded           = errors.New("max length exceeded")
+	ErrLineBuildingInvalidCharset              = errors.New("characters not allowed by the charset")
+	ErrLineBuildingUnsupportedCharset          = errors.New("unsupported charset")
+	ErrLineBuildingMissingRecordType           = errors.New("record type missing")
+)
+
+// StructureValidation
+var (
+	ErrValidationRequiredFieldMissing   = errors.New("required field missing")
+	ErrValidationInvalidEnumValue       = errors.New("invalid enum value")
+	ErrValidationUnescapedDelimiter     = errors.New("delimiter in value without output escaping")
+	ErrValidationSequenceNumberMismatch = errors.New("non-contiguous sequence number")
+	ErrValidationDateOutOfRange         = errors.New("date out of range")
+)
+
+// JSONConversion
+var (
+	ErrJSONConversionUnknownKey   = errors.New("unknown json key")
+	ErrJSONConversionInvalidValue = errors.New("invalid json value")
+)
+
+// MessageDiff
+var (
+	ErrDiffInvalidIgnoredPosition = errors.New("invalid ignored position")
 )
@@ -0,0 +1,20 @@
+package errmsg
+
+import "errors"
+
+// LIS1AFraming
+var (
+	ErrLIS1AInvalidFrame              = errors.New("invalid lis1-a frame")
+	ErrLIS1AChecksumMismatch          = errors.New("lis1-a frame checksum mismatch")
+	ErrLIS1AInvalidFrameTextCharacter = errors.New("invalid character in lis1-a frame text")
+)
+
+// LIS1AProtocol
+var (
+	ErrLIS1ATimeout             = errors.New("lis1-a timeout")
+	ErrLIS1ABusy                = errors.New("lis1-a receiver is busy")
+	ErrLIS1AContention          = errors.New("lis1-a line contention")
+	ErrLIS1ARetransmissionLimit = errors.New("lis1-a retransmission limit reached")
+	ErrLIS1AUnexpectedCharacter = errors.New("unexpected lis1-a character")
+	ErrLIS1ATransferInterrupted = errors.New("lis1-a transfer interrupted")
+)
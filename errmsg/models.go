@@ -0,0 +1,36 @@
+package errmsg
+
+import "errors"
+
+// ReferenceRange
+var (
+	ErrReferenceRangeInvalidBounds   = errors.New("invalid reference range bounds")
+	ErrReferenceRangeNonNumericValue = errors.New("non-numeric value for numeric reference range")
+	ErrReferenceRangeMissingValue    = errors.New("missing value to evaluate")
+)
+
+// StreamCodec
+var (
+	ErrStreamCodecInvalidDescriptor      = errors.New("invalid stream descriptor")
+	ErrStreamCodecUnsupportedDataType    = errors.New("unsupported stream data type")
+	ErrStreamCodecUnsupportedCompression = errors.New("unsupported stream compression")
+	ErrStreamCodecUnsupportedEncoding    = errors.New("unsupported stream encoding")
+	ErrStreamCodecDecodingError          = errors.New("stream decoding error")
+	ErrStreamCodecInvalidDataLength      = errors.New("invalid stream data length")
+	ErrStreamCodecDataTypeMismatch       = errors.New("stream data type mismatch")
+)
+
+// BioRad
+var (
+	ErrBioRadNotInterpretationResult = errors.New("result is not a blood group interpretation")
+	ErrBioRadInvalidABOGroup         = errors.New("invalid ABO blood group")
+	ErrBioRadInvalidReaction         = errors.New("invalid reaction value")
+	ErrBioRadInvalidRhPhenotype      = errors.New("invalid Rh phenotype")
+	ErrBioRadInvalidKell             = errors.New("invalid Kell value")
+)
+
+// Yumizen
+var (
+	ErrYumizenReagentLotCountMismatch = errors.New("reagent names and lots count mismatch")
+	ErrYumizenReagentLotExpired       = errors.New("reagent lot expired")
+)
@@ -0,0 +1,11 @@
+package errmsg
+
+import "errors"
+
+// Profiles
+var (
+	ErrProfileInvalid                = errors.New("profile name and sender name pattern are required")
+	ErrProfileAlreadyRegistered      = errors.New("profile already registered")
+	ErrProfileNotFound               = errors.New("no matching profile found")
+	ErrProfileUnsupportedMessageType = errors.New("message type not supported by profile")
+)
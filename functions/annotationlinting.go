@@ -0,0 +1,127 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"github.com/krendel52/go-astm/v3/constants"
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"reflect"
+)
+
+// LintStruct walks a message structure recursively and collects all the annotation mistakes
+// Every problem is wrapped with the location, e.g. invalid astm attribute: "lis02a2.Order.Priority"
+func LintStruct(structType reflect.Type) (issues []error) {
+	structType = derefType(structType)
+	if structType.Kind() != reflect.Struct {
+		return []error{fmt.Errorf("%w: %q", errmsg.ErrAnnotationLintingInvalidType, structType.String())}
+	}
+	return lintStruct(structType, structType.String(), 0)
+}
+
+func lintStruct(structType reflect.Type, path string, depth int) (issues []error) {
+	// Check for maximum depth
+	if depth >= constants.MaxDepth {
+		return []error{fmt.Errorf("%w: %q", errmsg.ErrStructureParsingMaxDepthReached, path)}
+	}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldPath := path + "." + field.Name
+		structAnnotation, err := ParseAstmStructAnnotation(field)
+		if err != nil {
+			issues = append(issues, fmt.Errorf("%w: %q", err, fieldPath))
+			continue
+		}
+		fieldType := field.Type
+		if structAnnotation.IsArray {
+			fieldType = fieldType.Elem()
+		}
+		fieldType = derefType(fieldType)
+		if fieldType.Kind() != reflect.Struct {
+			issues = append(issues, fmt.Errorf("%w: %q", errmsg.ErrAnnotationLintingInvalidType, fieldPath))
+			continue
+		}
+		if structAnnotation.IsComposite {
+			// Composite: recursively check the inner structure
+			issues = append(issues, lintStruct(fieldType, fieldPath, depth+1)...)
+			continue
+		}
+		issues = append(issues, lintFields(fieldType, fieldPath, structAnnotation.StructName, depth+1)...)
+	}
+	return issues
+}
+
+// Check the field annotations of a record or a substructure (with empty record name)
+func lintFields(structType reflect.Type, path string, recordName string, depth int) (issues []error) {
+	// Check for maximum depth
+	if depth >= constants.MaxDepth {
+		return []error{fmt.Errorf("%w: %q", errmsg.ErrStructureParsingMaxDepthReached, path)}
+	}
+	isSubstructure := recordName == ""
+	// Keep track of the used positions to find the collisions
+	plainFields := make(map[int]bool)
+	componentFields := make(map[int]map[int]bool)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldPath := path + "." + field.Name
+		annotation, err := ParseAstmFieldAnnotation(field)
+		if err != nil {
+			if !errors.Is(err, errmsg.ErrAnnotationParsingMissingAstmAnnotation) {
+				issues = append(issues, fmt.Errorf("%w: %q", err, fieldPath))
+			}
+			continue
+		}
+		// The delimiters can only be captured in a Delimiters field at the position 2 of the header
+		if _, exists := annotation.Attributes[constants.AttributeDelimiters]; exists {
+			if recordName != "H" || annotation.FieldPos != 2 || annotation.IsComponent || field.Type != reflect.TypeOf(astmmodels.Delimiters{}) {
+				issues = append(issues, fmt.Errorf("%w: %q", errmsg.ErrAnnotationLintingInvalidDelimitersField, fieldPath))
+			}
+			continue
+		}
+		// Check the positions
+		switch {
+		case annotation.FieldPos < 1 || (annotation.IsComponent && annotation.ComponentPos < 1):
+			issues = append(issues, fmt.Errorf("%w: %q", errmsg.ErrAnnotationLintingInvalidPosition, fieldPath))
+		case !isSubstructure && annotation.FieldPos < 3:
+			issues = append(issues, fmt.Errorf("%w: %q", errmsg.ErrAnnotationLintingReservedFieldPos, fieldPath))
+		case annotation.IsComponent:
+			if plainFields[annotation.FieldPos] {
+				issues = append(issues, fmt.Errorf("%w: %q", errmsg.ErrAnnotationLintingComponentCollision, fieldPath))
+			} else if componentFields[annotation.FieldPos][annotation.ComponentPos] {
+				issues = append(issues, fmt.Errorf("%w: %q", errmsg.ErrAnnotationLintingDuplicateFieldPos, fieldPath))
+			}
+			if componentFields[annotation.FieldPos] == nil {
+				componentFields[annotation.FieldPos] = make(map[int]bool)
+			}
+			componentFields[annotation.FieldPos][annotation.ComponentPos] = true
+		default:
+			if plainFields[annotation.FieldPos] {
+				issues = append(issues, fmt.Errorf("%w: %q", errmsg.ErrAnnotationLintingDuplicateFieldPos, fieldPath))
+			} else if componentFields[annotation.FieldPos] != nil {
+				issues = append(issues, fmt.Errorf("%w: %q", errmsg.ErrAnnotationLintingComponentCollision, fieldPath))
+			}
+			plainFields[annotation.FieldPos] = true
+		}
+		// Find the value type inside the pointers and (nested) arrays
+		valueType := derefType(field.Type)
+		for isNestedArrayType(valueType) {
+			valueType = derefType(valueType.Elem())
+		}
+		// Check the attributes that only apply to some types
+		if _, exists := annotation.Attributes[constants.AttributeLength]; exists && valueType.Kind() != reflect.Float32 && valueType.Kind() != reflect.Float64 {
+			issues = append(issues, fmt.Errorf("%w: %q", errmsg.ErrAnnotationLintingLengthOnNonFloat, fieldPath))
+		}
+		// Substructures are checked recursively
+		if isNestedSubstructureType(valueType) {
+			issues = append(issues, lintFields(valueType, fieldPath, "", depth+1)...)
+		}
+	}
+	return issues
+}
+
+func derefType(valueType reflect.Type) reflect.Type {
+	for valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+	return valueType
+}
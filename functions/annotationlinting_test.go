@@ -0,0 +1,68 @@
+package functions
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintStruct_Valid(t *testing.T) {
+	// Act
+	issues := LintStruct(reflect.TypeOf(SubnameMessage{}))
+	// Assert
+	assert.Empty(t, issues)
+}
+
+func TestLintStruct_Pointer(t *testing.T) {
+	// Act
+	issues := LintStruct(reflect.TypeOf(&CompositeArrayAndSingleRecordMessage{}))
+	// Assert
+	assert.Empty(t, issues)
+}
+
+func TestLintStruct_NotAStruct(t *testing.T) {
+	// Act
+	issues := LintStruct(reflect.TypeOf(""))
+	// Assert
+	assert.Len(t, issues, 1)
+	assert.ErrorIs(t, issues[0], errmsg.ErrAnnotationLintingInvalidType)
+}
+
+func TestLintStruct_Substructure(t *testing.T) {
+	// Act
+	issues := LintStruct(reflect.TypeOf(LintedMessage{}))
+	// Assert
+	assert.Len(t, issues, 3)
+	assert.ErrorIs(t, issues[0], errmsg.ErrAnnotationLintingDuplicateFieldPos)
+	assert.EqualError(t, issues[0], `duplicate field position: "functions.LintedMessage.Records.Field.Second"`)
+	assert.ErrorIs(t, issues[1], errmsg.ErrAnnotationLintingInvalidPosition)
+	assert.ErrorIs(t, issues[2], errmsg.ErrAnnotationLintingInvalidType)
+	assert.EqualError(t, issues[2], `type is not a struct: "functions.LintedMessage.Invalid"`)
+}
+
+func TestLintStruct_IllegalFixedSizeComponentArray(t *testing.T) {
+	// Arrange
+	type Message struct {
+		Record IllegalFixedSizeComponentArrayRecord `astm:"R"`
+	}
+	// Act
+	issues := LintStruct(reflect.TypeOf(Message{}))
+	// Assert
+	assert.Len(t, issues, 1)
+	assert.ErrorIs(t, issues[0], errmsg.ErrAnnotationParsingIllegalComponentArray)
+	assert.EqualError(t, issues[0], `component array is only allowed as a slice of simple values: "functions.Message.Record.Codes"`)
+}
+
+func TestLintStruct_IllegalComponentSubstructure(t *testing.T) {
+	// Arrange
+	type Message struct {
+		Record IllegalComponentSubstructure `astm:"R"`
+	}
+	// Act
+	issues := LintStruct(reflect.TypeOf(Message{}))
+	// Assert
+	assert.Len(t, issues, 1)
+	assert.ErrorIs(t, issues[0], errmsg.ErrAnnotationParsingIllegalComponentSubstructure)
+}
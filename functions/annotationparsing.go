@@ -35,8 +35,8 @@ func ParseAstmFieldAnnotation(input reflect.StructField) (result models.AstmFiel
 	}
 	result.IsSubstructure = checkType.Kind() == reflect.Struct && checkType != reflect.TypeOf(time.Time{})
 
-	// Check illegal combinations
-	if result.IsComponent && result.IsArray {
+	// Check illegal combinations (component arrays are allowed, but only as slices of simple values)
+	if result.IsComponent && result.IsArray && (input.Type.Kind() != reflect.Slice || isNestedArrayType(input.Type.Elem())) {
 		return models.AstmFieldAnnotation{}, errmsg.ErrAnnotationParsingIllegalComponentArray
 	}
 	if result.IsComponent && result.IsSubstructure {
@@ -58,6 +58,13 @@ func parseAstmFieldAnnotationString(input string) (result models.AstmFieldAnnota
 		constants.AttributeRequired,
 		constants.AttributeLongdate,
 		constants.AttributeLength,
+		constants.AttributeOmitempty,
+		constants.AttributeDefault,
+		constants.AttributeMaxlen,
+		constants.AttributeTrim,
+		constants.AttributeUpper,
+		constants.AttributeCharset,
+		constants.AttributeDelimiters,
 	})
 	if err != nil {
 		return models.AstmFieldAnnotation{}, err
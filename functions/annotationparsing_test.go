@@ -81,6 +81,29 @@ func TestParseAstmFieldAnnotationString_Complex(t *testing.T) {
 	assert.Contains(t, result.Attributes, constants.AttributeLength)
 	assert.Equal(t, "4", result.Attributes[constants.AttributeLength])
 }
+func TestParseAstmFieldAnnotationString_OmitemptyDefault(t *testing.T) {
+	// Arrange
+	input := "5,omitempty,default:N"
+	// Act
+	result, err := parseAstmFieldAnnotationString(input)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 5, result.FieldPos)
+	assert.Contains(t, result.Attributes, constants.AttributeOmitempty)
+	assert.Equal(t, "N", result.Attributes[constants.AttributeDefault])
+}
+func TestParseAstmFieldAnnotationString_StringConstraints(t *testing.T) {
+	// Arrange
+	input := "3,trim,upper,maxlen:20,charset:ascii"
+	// Act
+	result, err := parseAstmFieldAnnotationString(input)
+	// Assert
+	assert.Nil(t, err)
+	assert.Contains(t, result.Attributes, constants.AttributeTrim)
+	assert.Contains(t, result.Attributes, constants.AttributeUpper)
+	assert.Equal(t, "20", result.Attributes[constants.AttributeMaxlen])
+	assert.Equal(t, "ascii", result.Attributes[constants.AttributeCharset])
+}
 func TestParseAstmFieldAnnotationString_InvalidAttribute(t *testing.T) {
 	// Arrange
 	input := "4.1,something"
@@ -215,6 +238,20 @@ func TestParseAstmFieldAnnotation_SubstructureArray(t *testing.T) {
 	assert.Equal(t, true, result.IsSubstructure)
 	assert.Empty(t, result.Attributes)
 }
+func TestParseAstmFieldAnnotation_ComponentArray(t *testing.T) {
+	// Arrange
+	var input ComponentArray
+	field, _ := reflect.TypeOf(input).FieldByName("ComponentArray")
+	// Act
+	result, err := ParseAstmFieldAnnotation(field)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 3, result.FieldPos)
+	assert.Equal(t, 1, result.ComponentPos)
+	assert.Equal(t, true, result.IsArray)
+	assert.Equal(t, true, result.IsComponent)
+	assert.Equal(t, false, result.IsSubstructure)
+}
 func TestParseAstmFieldAnnotation_IllegalComponentArray(t *testing.T) {
 	// Arrange
 	var input IllegalComponentArray
@@ -224,6 +261,15 @@ func TestParseAstmFieldAnnotation_IllegalComponentArray(t *testing.T) {
 	// Assert
 	assert.EqualError(t, err, errmsg.ErrAnnotationParsingIllegalComponentArray.Error())
 }
+func TestParseAstmFieldAnnotation_IllegalFixedSizeComponentArray(t *testing.T) {
+	// Arrange
+	var input IllegalFixedSizeComponentArray
+	field, _ := reflect.TypeOf(input).FieldByName("ComponentArray")
+	// Act
+	_, err := ParseAstmFieldAnnotation(field)
+	// Assert
+	assert.EqualError(t, err, errmsg.ErrAnnotationParsingIllegalComponentArray.Error())
+}
 func TestParseAstmFieldAnnotation_IllegalComponentSubstructure(t *testing.T) {
 	// Arrange
 	var input IllegalComponentSubstructure
@@ -62,9 +62,18 @@ type Substructure struct {
 	FirstComponent  string `astm:"1"`
 	SecondComponent string `astm:"2"`
 }
-type IllegalComponentArray struct {
+type ComponentArray struct {
 	ComponentArray []string `astm:"3.1"`
 }
+type IllegalComponentArray struct {
+	ComponentArray [][]string `astm:"3.1"`
+}
+type IllegalFixedSizeComponentArray struct {
+	ComponentArray [2]string `astm:"3.1"`
+}
+type IllegalFixedSizeComponentArrayRecord struct {
+	Codes [2]string `astm:"3.1"`
+}
 type IllegalComponentSubstructure struct {
 	ComponentSubstructure Substructure `astm:"3.1"`
 }
@@ -88,6 +97,18 @@ type TooManyStructNameAttributeValues struct {
 type SubnameAttribute struct {
 	Record Line `astm:"R,subname:SUBNAME"`
 }
+type DuplicateSubstructureField struct {
+	First  string `astm:"1"`
+	Second string `astm:"1"`
+	Third  string `astm:"0"`
+}
+type LintedSubstructureRecord struct {
+	Field DuplicateSubstructureField `astm:"3"`
+}
+type LintedMessage struct {
+	Records []LintedSubstructureRecord `astm:"R"`
+	Invalid string
+}
 
 // Single line records
 type ThreeFieldRecord struct {
@@ -150,6 +171,16 @@ type HeaderDelimiterChange struct {
 	Comp1      string   `astm:"5.1"`
 	Comp2      string   `astm:"5.2"`
 }
+type HeaderWithoutDelimiters struct {
+	First string `astm:"3"`
+}
+type HeaderAnnotatedDelimiters struct {
+	Captured astmmodels.Delimiters `astm:"2,delimiters"`
+	First    string                `astm:"3"`
+}
+type HeaderInvalidDelimiters struct {
+	Captured string `astm:"2,delimiters"`
+}
 type RequiredFieldRecord struct {
 	First  string `astm:"3"`
 	Second string `astm:"4,required"`
@@ -182,6 +213,15 @@ type EnumString string
 type EnumRecord struct {
 	Enum EnumString `astm:"3"`
 }
+type ValidatedEnumString string
+
+func (e ValidatedEnumString) IsValid() bool {
+	return e == "valid"
+}
+
+type ValidatedEnumRecord struct {
+	Enum ValidatedEnumString `astm:"3"`
+}
 type ReservedFieldRecord struct {
 	TypeName  string `astm:"1"`
 	SeqNumber string `astm:"2"`
@@ -200,6 +240,11 @@ type SubstructureRecord struct {
 	Second SubstructureField `astm:"4"`
 	Third  string            `astm:"5"`
 }
+type OmitemptySubstructureRecord struct {
+	First  string            `astm:"3"`
+	Second SubstructureField `astm:"4,omitempty"`
+	Third  string            `astm:"5"`
+}
 type SubstructureArrayRecord struct {
 	First  string              `astm:"3"`
 	Second []SubstructureField `astm:"4"`
@@ -214,6 +259,79 @@ type SparseSubstructureRecord struct {
 	First  string                  `astm:"3"`
 	Second SparseSubstructureField `astm:"4"`
 }
+type NestedArrayField struct {
+	Name   string   `astm:"1"`
+	Values []string `astm:"2"`
+	Unit   string   `astm:"3"`
+}
+type NestedArraySubstructureRecord struct {
+	First  string           `astm:"3"`
+	Second NestedArrayField `astm:"4"`
+}
+type NestedArraySubstructureArrayRecord struct {
+	First []NestedArrayField `astm:"3"`
+}
+type NestedSubstructureField struct {
+	Name  string            `astm:"1"`
+	Inner SubstructureField `astm:"2"`
+}
+type NestedSubstructureRecord struct {
+	First NestedSubstructureField `astm:"3"`
+}
+type NestedArrayOfArraysRecord struct {
+	First [][]int `astm:"3"`
+}
+type ComponentArrayRecord struct {
+	IDs      []string `astm:"3.1"`
+	Counts   []int    `astm:"3.3"`
+	Operator string   `astm:"4.1"`
+	Verifier string   `astm:"4.2"`
+	Sites    []string `astm:"4.3"`
+}
+type FixedSizeArrayRecord struct {
+	Values     [2]string                  `astm:"3"`
+	Structures [2]SubstructureField       `astm:"4"`
+	Nested     [2][]int                   `astm:"5"`
+	Defaults   [2]string                  `astm:"6,default:A\\B\\C"`
+	Components [2]SparseSubstructureField `astm:"7"`
+}
+type OmitemptyDefaultRecord struct {
+	Int        int       `astm:"3,omitempty"`
+	Float      float64   `astm:"4,omitempty"`
+	Time       time.Time `astm:"5,omitempty,longdate"`
+	IntDefault int       `astm:"6,default:1"`
+	Status     string    `astm:"7,default:F"`
+	Component  string    `astm:"8.2,default:X"`
+	Array      []string  `astm:"9,default:A\\B"`
+	Plain      int       `astm:"10"`
+}
+type StringConstraintRecord struct {
+	Trimmed string `astm:"3,trim"`
+	Upper   string `astm:"4,trim,upper"`
+	Limited string `astm:"5,maxlen:5"`
+	ASCII   string `astm:"6,charset:ascii"`
+}
+type UnsupportedCharsetRecord struct {
+	First string `astm:"3,charset:latin"`
+}
+type InvalidMaxlenRecord struct {
+	First string `astm:"3,maxlen:five"`
+}
+type ValidatedRecord struct {
+	ID      string              `astm:"3,required"`
+	Name    string              `astm:"4.1,required"`
+	Status  ValidatedEnumString `astm:"5"`
+	Date    time.Time           `astm:"6"`
+	Comment string              `astm:"7"`
+	Items   []SubstructureField `astm:"8"`
+	Default string              `astm:"9,required,default:N"`
+}
+type PreservedRecord struct {
+	Padded string    `astm:"3,trim"`
+	Date   time.Time `astm:"4"`
+	Float  float64   `astm:"5"`
+	Raw    astmmodels.RawRecord
+}
 type TimeRecord struct {
 	Time time.Time `astm:"3,longdate"`
 }
@@ -290,6 +408,17 @@ type OptionalAtTheEndMessage struct {
 	First    SimpleRecord `astm:"F"`
 	Optional SimpleRecord `astm:"O,optional"`
 }
+type ValidatedMessage struct {
+	Records    []ValidatedRecord `astm:"R"`
+	Terminator SimpleRecord      `astm:"L"`
+}
+type SequenceGapGroup struct {
+	Comments []SimpleRecord `astm:"C,optional"`
+	Order    SimpleRecord   `astm:"O"`
+}
+type SequenceGapMessage struct {
+	Groups []SequenceGapGroup
+}
 type SubnameMessage struct {
 	Record1 SubnameRecordType1 `astm:"R,subname:FIRST"`
 	Record2 SubnameRecordType2 `astm:"R,subname:SECOND"`
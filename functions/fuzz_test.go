@@ -0,0 +1,82 @@
+package functions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/krendel52/go-astm/v3/enums/lineseparator"
+)
+
+func FuzzSliceLines(f *testing.F) {
+	// Seed the corpus with the examples and mixed line breaks
+	examples, err := filepath.Glob("../examples/*/*.astm")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, example := range examples {
+		data, err := os.ReadFile(example)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(string(data), true)
+	}
+	for _, seed := range []string{"", " ", "\r", "\n\r\n", "H|\\^&\r\nL|1|N\n", "a\rb\nc"} {
+		f.Add(seed, true)
+		f.Add(seed, false)
+	}
+	f.Fuzz(func(t *testing.T, input string, autoDetect bool) {
+		teardown()
+		config.AutoDetectLineSeparator = autoDetect
+		lines, err := SliceLines(input, config)
+		if err != nil {
+			return
+		}
+		for _, line := range lines {
+			// The lines are trimmed and not empty, with auto-detect the line breaks are removed
+			if strings.Trim(line, " ") == "" || strings.HasPrefix(line, " ") || strings.HasSuffix(line, " ") {
+				t.Fatalf("untrimmed or empty line: %q", line)
+			}
+			if autoDetect && strings.ContainsAny(line, lineseparator.CR+lineseparator.LF) {
+				t.Fatalf("line break in line: %q", line)
+			}
+		}
+	})
+}
+
+func FuzzParseLine(f *testing.F) {
+	// Seed the corpus with records of component arrays, fixed size arrays and defaults
+	for _, seed := range []string{
+		"T|1|id1^^1\\id2^^\\^^3|op1^ver1^site1\\op2^ver2^site2",
+		"T|1|a^b\\c^d",
+		"T|1|v1\\v2\\v3|a^b^c\\d|1~2\\3||x^^y^^^z\\w",
+		"T|1|1|2.5||||first^|",
+		"T|1|",
+		"T|1||||5|C|^Y|Z",
+		"T|1|r1^v1~v2\\r2^v3",
+		"T",
+	} {
+		f.Add(seed, "")
+		f.Add(seed, "~")
+	}
+	f.Fuzz(func(t *testing.T, input string, subComponent string) {
+		teardown()
+		config.Delimiters.SubComponent = subComponent
+		// Parsing must not panic for any input, but return an error
+		for _, target := range []interface{}{
+			&ComponentArrayRecord{},
+			&FixedSizeArrayRecord{},
+			&OmitemptyDefaultRecord{},
+			&NestedArraySubstructureArrayRecord{},
+			&StringConstraintRecord{},
+		} {
+			_, _ = ParseLine(input, target, createStructAnnotation("T"), 1, config)
+		}
+		// Fixed size component arrays are rejected before any value is set
+		nameOk, err := ParseLine(input, &IllegalFixedSizeComponentArrayRecord{}, createStructAnnotation("T"), 1, config)
+		if nameOk && err == nil {
+			t.Fatalf("fixed size component array accepted: %q", input)
+		}
+	})
+}
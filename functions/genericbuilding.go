@@ -0,0 +1,44 @@
+package functions
+
+import (
+	notationconst "github.com/krendel52/go-astm/v3/enums/notation"
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"strings"
+)
+
+// BuildGenericLine joins a schema-less record into a line with the delimiters of the configuration
+// The values are always escaped, as they are unescaped by ParseGenericLine
+func BuildGenericLine(record astmmodels.GenericRecord, config *astmmodels.Configuration) (result string, err error) {
+	if record.Type == "" {
+		return "", errmsg.ErrLineBuildingMissingRecordType
+	}
+	// The record type and the header delimiters are not taken from the fields
+	fields := make([]string, max(len(record.Fields), 1))
+	fields[0] = record.Type
+	for i := 1; i < len(record.Fields); i++ {
+		repeats := make([]string, len(record.Fields[i]))
+		for j, repeat := range record.Fields[i] {
+			components := make([]string, len(repeat))
+			for k, component := range repeat {
+				components[k] = buildStringEscapeChars(component, config)
+			}
+			repeats[j] = strings.Join(components, config.Delimiters.Component)
+		}
+		fields[i] = strings.Join(repeats, config.Delimiters.Repeat)
+	}
+	if record.Type == "H" {
+		delimiters := config.Delimiters.Repeat + config.Delimiters.Component + config.Delimiters.Escape
+		if len(fields) < 2 {
+			fields = append(fields, delimiters)
+		}
+		fields[1] = delimiters
+	}
+	// In short notation the empty fields at the end are not included
+	if config.Notation == notationconst.Short {
+		for len(fields) > 2 && fields[len(fields)-1] == "" {
+			fields = fields[:len(fields)-1]
+		}
+	}
+	return strings.Join(fields, config.Delimiters.Field), nil
+}
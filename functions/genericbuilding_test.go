@@ -0,0 +1,52 @@
+package functions
+
+import (
+	"github.com/krendel52/go-astm/v3/enums/notation"
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBuildGenericLine_Record(t *testing.T) {
+	// Arrange
+	record := astmmodels.GenericRecord{
+		Type: "R",
+		Fields: []astmmodels.GenericField{
+			{{"R"}},
+			{{"1"}},
+			{{"", "", "", "A^B"}, {"C"}},
+			nil,
+			{{"va|ue"}},
+			nil,
+		},
+	}
+	// Act
+	result, err := BuildGenericLine(record, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "R|1|^^^A&^B\\C||va&|ue|", result)
+}
+
+func TestBuildGenericLine_HeaderUsesConfiguredDelimiters(t *testing.T) {
+	// Arrange
+	record := astmmodels.GenericRecord{
+		Type:   "H",
+		Fields: []astmmodels.GenericField{{{"H"}}, {{"!*$"}}, nil, nil, {{"Sender", "1.0"}}, nil},
+	}
+	config.Notation = notation.Short
+	// Act
+	result, err := BuildGenericLine(record, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "H|\\^&|||Sender^1.0", result)
+	// Teardown
+	teardown()
+}
+
+func TestBuildGenericLine_MissingRecordType(t *testing.T) {
+	// Act
+	_, err := BuildGenericLine(astmmodels.GenericRecord{}, config)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLineBuildingMissingRecordType)
+}
@@ -0,0 +1,63 @@
+package functions
+
+import (
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"strings"
+)
+
+// ParseGenericLine splits a line into the schema-less field, repeat and component layout without any target structure
+// Like in ParseLine the header overrides the delimiters of the configuration
+func ParseGenericLine(inputLine string, config *astmmodels.Configuration) (record astmmodels.GenericRecord, err error) {
+	inputLine = strings.Trim(inputLine, " ")
+	// Check for input line length
+	if len(inputLine) == 0 {
+		return record, errmsg.ErrLineParsingEmptyInput
+	}
+
+	var inputFields []string
+	// Handle header special case: the delimiters are kept as a single field
+	if inputLine[0] == 'H' {
+		if len(inputLine) < 5 {
+			return record, errmsg.ErrLineParsingHeaderTooShort
+		}
+		config.Delimiters.Field = string(inputLine[1])
+		config.Delimiters.Repeat = string(inputLine[2])
+		config.Delimiters.Component = string(inputLine[3])
+		config.Delimiters.Escape = string(inputLine[4])
+		record.Fields = []astmmodels.GenericField{{{inputLine[0:1]}}, {{inputLine[2:5]}}}
+		if len(inputLine) > 5 {
+			inputFields = splitFields(inputLine[6:], config)
+		}
+	} else {
+		inputFields = splitFields(inputLine, config)
+	}
+
+	// Split the fields into repeats and components
+	for _, inputField := range inputFields {
+		var field astmmodels.GenericField
+		for _, repeat := range splitGenericValues(inputField, config.Delimiters.Repeat, config) {
+			var components []string
+			for _, component := range splitGenericValues(repeat, config.Delimiters.Component, config) {
+				components = append(components, filterStringEscapeChars(component, config.Delimiters.Escape))
+			}
+			field = append(field, components)
+		}
+		record.Fields = append(record.Fields, field)
+	}
+	// Check for the mandatory fields (the record type and the sequence number)
+	if len(record.Fields) < 2 || len(record.Fields[0]) == 0 || len(record.Fields[0][0]) == 0 || record.Fields[0][0][0] == "" {
+		return record, errmsg.ErrLineParsingMandatoryInputFieldsMissing
+	}
+	record.Type = record.Fields[0][0][0]
+	return record, nil
+}
+
+// Split a value keeping the empty elements (an empty value still has a single empty element)
+func splitGenericValues(input string, delimiter string, config *astmmodels.Configuration) []string {
+	values := splitStringWithEscape(input, delimiter, config.Delimiters.Escape)
+	if strings.Join(values, delimiter) != input {
+		values = append(values, "")
+	}
+	return values
+}
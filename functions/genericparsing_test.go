@@ -0,0 +1,56 @@
+package functions
+
+import (
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseGenericLine_Record(t *testing.T) {
+	// Arrange
+	input := "R|1|^^^A&^B^C\\^^^D||va&|ue|"
+	// Act
+	record, err := ParseGenericLine(input, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "R", record.Type)
+	assert.Len(t, record.Fields, 6)
+	assert.Equal(t, astmmodels.GenericField{{"", "", "", "A^B", "C"}, {"", "", "", "D"}}, record.Fields[2])
+	assert.Nil(t, record.Fields[3])
+	assert.Equal(t, astmmodels.GenericField{{"va|ue"}}, record.Fields[4])
+	assert.Nil(t, record.Fields[5])
+	// Check the positional access
+	assert.Equal(t, "D", record.Value(3, 2, 4))
+	assert.Equal(t, "", record.Value(3, 3, 1))
+	assert.Equal(t, "", record.Value(9, 1, 1))
+}
+
+func TestParseGenericLine_HeaderOverridesDelimiters(t *testing.T) {
+	// Arrange
+	input := "H/!*$///Sender*1.0"
+	// Act
+	record, err := ParseGenericLine(input, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "H", record.Type)
+	assert.Equal(t, "!*$", record.Value(2, 1, 1))
+	assert.Equal(t, astmmodels.GenericField{{"Sender", "1.0"}}, record.Fields[4])
+	assert.Equal(t, "/", config.Delimiters.Field)
+	// Teardown
+	teardown()
+}
+
+func TestParseGenericLine_MandatoryFieldsMissing(t *testing.T) {
+	// Act
+	_, err := ParseGenericLine("|1|value", config)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLineParsingMandatoryInputFieldsMissing)
+}
+
+func TestParseGenericLine_RecordTypeOnlyDelimiters(t *testing.T) {
+	// Act
+	_, err := ParseGenericLine("\\|", config)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLineParsingMandatoryInputFieldsMissing)
+}
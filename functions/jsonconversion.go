@@ -0,0 +1,427 @@
+package functions
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/krendel52/go-astm/v3/constants"
+	"github.com/krendel52/go-astm/v3/enums/jsonkeys"
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// StructToJSON converts an annotated message structure to JSON
+// The message structure levels are keyed by the Go field names, the record fields by the Go field names or by their
+// ASTM positions (e.g. "4.1") depending on JSONKeys. The fields without a position (e.g. Raw) keep the Go field name.
+// Strings are kept as they are, and the times are converted to the configured time zone (RFC 3339).
+func StructToJSON(sourceStruct interface{}, config *astmmodels.Configuration) (result []byte, err error) {
+	sourceValue := reflect.Indirect(reflect.ValueOf(sourceStruct))
+	if sourceValue.Kind() != reflect.Struct {
+		return nil, errmsg.ErrAnnotationParsingInvalidInputStruct
+	}
+	object, err := messageToJSON(sourceValue, "", 0, config)
+	if err != nil {
+		return nil, err
+	}
+	result, err = marshalJSONValue(object)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// JSONToStruct fills an annotated message structure from JSON produced by StructToJSON (with the same JSONKeys)
+// Unknown keys are errors, missing keys and null values leave the fields empty
+func JSONToStruct(jsonData []byte, targetStruct interface{}, config *astmmodels.Configuration) (err error) {
+	targetValue := reflect.ValueOf(targetStruct)
+	if targetValue.Kind() != reflect.Ptr || targetValue.IsNil() || targetValue.Elem().Kind() != reflect.Struct {
+		return errmsg.ErrAnnotationParsingInvalidInputStruct
+	}
+	// Numbers are decoded as json.Number to keep the precision of the integers
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+	var input interface{}
+	err = decoder.Decode(&input)
+	if err != nil {
+		return err
+	}
+	return messageFromJSON(input, targetValue.Elem(), "", 0, config)
+}
+
+// Ordered JSON object, so the fields keep the order of the structure
+type jsonMember struct {
+	key   string
+	value interface{}
+}
+type jsonObject []jsonMember
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, member := range o {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		key, err := marshalJSONValue(member.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := marshalJSONValue(member.value)
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(key)
+		buffer.WriteByte(':')
+		buffer.Write(value)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+// Marshal a value without escaping the HTML characters, so the escape delimiter (&) and the qualified values (e.g. <0.5) stay readable
+func marshalJSONValue(value interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(value)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buffer.Bytes(), "\n"), nil
+}
+
+func messageToJSON(structValue reflect.Value, path string, depth int, config *astmmodels.Configuration) (object jsonObject, err error) {
+	// Check for maximum depth
+	if depth >= constants.MaxDepth {
+		return nil, errmsg.ErrStructureParsingMaxDepthReached
+	}
+	object = jsonObject{}
+	for i := 0; i < structValue.NumField(); i++ {
+		field := structValue.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		structAnnotation, err := ParseAstmStructAnnotation(field)
+		if err != nil {
+			return nil, err
+		}
+		fieldPath := joinPath(path, field.Name)
+		var value interface{}
+		if structAnnotation.IsArray {
+			if structValue.Field(i).Kind() == reflect.Slice && structValue.Field(i).IsNil() {
+				object = append(object, jsonMember{key: field.Name, value: nil})
+				continue
+			}
+			items := make([]interface{}, structValue.Field(i).Len())
+			for j := range items {
+				items[j], err = messageElementToJSON(structValue.Field(i).Index(j), structAnnotation, fmt.Sprintf("%s[%d]", fieldPath, j), depth, config)
+				if err != nil {
+					return nil, err
+				}
+			}
+			value = items
+		} else {
+			value, err = messageElementToJSON(structValue.Field(i), structAnnotation, fieldPath, depth, config)
+			if err != nil {
+				return nil, err
+			}
+		}
+		object = append(object, jsonMember{key: field.Name, value: value})
+	}
+	return object, nil
+}
+
+func messageElementToJSON(value reflect.Value, structAnnotation models.AstmStructAnnotation, path string, depth int, config *astmmodels.Configuration) (result interface{}, err error) {
+	if structAnnotation.IsComposite {
+		// Composite: recursively convert the inner message structure
+		return messageToJSON(value, path, depth+1, config)
+	}
+	// Record: the fields are converted by their values
+	return valueToJSON(value, path, depth+1, config)
+}
+
+func valueToJSON(value reflect.Value, path string, depth int, config *astmmodels.Configuration) (result interface{}, err error) {
+	// Check for maximum depth
+	if depth >= constants.MaxDepth {
+		return nil, errmsg.ErrStructureParsingMaxDepthReached
+	}
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return nil, nil
+		}
+		return valueToJSON(value.Elem(), path, depth, config)
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return nil, nil
+		}
+		items := make([]interface{}, value.Len())
+		for i := range items {
+			items[i], err = valueToJSON(value.Index(i), fmt.Sprintf("%s[%d]", path, i), depth+1, config)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	case reflect.Struct:
+		if value.Type() == reflect.TypeOf(time.Time{}) {
+			timeValue := value.Interface().(time.Time)
+			if timeValue.IsZero() {
+				return nil, nil
+			}
+			return timeValue.In(config.TimeLocation).Format(time.RFC3339), nil
+		}
+		object := jsonObject{}
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			// The original line is only included if it was captured
+			if rawRecord, ok := value.Field(i).Interface().(astmmodels.RawRecord); ok && rawRecord.Line == "" {
+				continue
+			}
+			key, err := jsonKey(field, config)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %q", err, joinPath(path, field.Name))
+			}
+			fieldValue, err := valueToJSON(value.Field(i), joinPath(path, field.Name), depth+1, config)
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, jsonMember{key: key, value: fieldValue})
+		}
+		return object, nil
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Bool:
+		return value.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return value.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), nil
+	}
+	return nil, fmt.Errorf("%w: %q", errmsg.ErrLineBuildingUsupportedDataType, path)
+}
+
+func messageFromJSON(input interface{}, structValue reflect.Value, path string, depth int, config *astmmodels.Configuration) (err error) {
+	// Check for maximum depth
+	if depth >= constants.MaxDepth {
+		return errmsg.ErrStructureParsingMaxDepthReached
+	}
+	object, ok := input.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%w: %q", errmsg.ErrJSONConversionInvalidValue, path)
+	}
+	knownKeys := make(map[string]bool)
+	for i := 0; i < structValue.NumField(); i++ {
+		field := structValue.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		structAnnotation, err := ParseAstmStructAnnotation(field)
+		if err != nil {
+			return err
+		}
+		knownKeys[field.Name] = true
+		fieldPath := joinPath(path, field.Name)
+		fieldInput, exists := object[field.Name]
+		if !exists || fieldInput == nil {
+			continue
+		}
+		fieldValue := structValue.Field(i)
+		if structAnnotation.IsArray {
+			items, ok := fieldInput.([]interface{})
+			if !ok {
+				return fmt.Errorf("%w: %q", errmsg.ErrJSONConversionInvalidValue, fieldPath)
+			}
+			if fieldValue.Kind() == reflect.Slice {
+				fieldValue.Set(reflect.MakeSlice(fieldValue.Type(), len(items), len(items)))
+			}
+			for j := 0; j < len(items) && j < fieldValue.Len(); j++ {
+				err = messageElementFromJSON(items[j], fieldValue.Index(j), structAnnotation, fmt.Sprintf("%s[%d]", fieldPath, j), depth, config)
+				if err != nil {
+					return err
+				}
+			}
+		} else {
+			err = messageElementFromJSON(fieldInput, fieldValue, structAnnotation, fieldPath, depth, config)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return checkUnknownKeys(object, knownKeys, path)
+}
+
+func messageElementFromJSON(input interface{}, value reflect.Value, structAnnotation models.AstmStructAnnotation, path string, depth int, config *astmmodels.Configuration) (err error) {
+	if structAnnotation.IsComposite {
+		// Composite: recursively fill the inner message structure
+		return messageFromJSON(input, value, path, depth+1, config)
+	}
+	// Record: the fields are filled by their values
+	return valueFromJSON(input, value, path, depth+1, config)
+}
+
+func valueFromJSON(input interface{}, value reflect.Value, path string, depth int, config *astmmodels.Configuration) (err error) {
+	// Check for maximum depth
+	if depth >= constants.MaxDepth {
+		return errmsg.ErrStructureParsingMaxDepthReached
+	}
+	// Null is the empty value
+	if input == nil {
+		value.Set(reflect.Zero(value.Type()))
+		return nil
+	}
+	invalidValue := fmt.Errorf("%w: %q", errmsg.ErrJSONConversionInvalidValue, path)
+	switch value.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(value.Type().Elem())
+		err = valueFromJSON(input, elem.Elem(), path, depth, config)
+		if err != nil {
+			return err
+		}
+		value.Set(elem)
+		return nil
+	case reflect.Slice, reflect.Array:
+		items, ok := input.([]interface{})
+		if !ok {
+			return invalidValue
+		}
+		if value.Kind() == reflect.Slice {
+			value.Set(reflect.MakeSlice(value.Type(), len(items), len(items)))
+		}
+		for i := 0; i < len(items) && i < value.Len(); i++ {
+			err = valueFromJSON(items[i], value.Index(i), fmt.Sprintf("%s[%d]", path, i), depth+1, config)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		if value.Type() == reflect.TypeOf(time.Time{}) {
+			text, ok := input.(string)
+			if !ok {
+				return invalidValue
+			}
+			timeValue, err := time.Parse(time.RFC3339, text)
+			if err != nil {
+				return invalidValue
+			}
+			value.Set(reflect.ValueOf(timeValue.In(config.TimeLocation)))
+			return nil
+		}
+		object, ok := input.(map[string]interface{})
+		if !ok {
+			return invalidValue
+		}
+		knownKeys := make(map[string]bool)
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			key, err := jsonKey(field, config)
+			if err != nil {
+				return fmt.Errorf("%w: %q", err, joinPath(path, field.Name))
+			}
+			knownKeys[key] = true
+			if fieldInput, exists := object[key]; exists {
+				err = valueFromJSON(fieldInput, value.Field(i), joinPath(path, key), depth+1, config)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return checkUnknownKeys(object, knownKeys, path)
+	case reflect.String:
+		text, ok := input.(string)
+		if !ok {
+			return invalidValue
+		}
+		value.SetString(text)
+		return nil
+	case reflect.Bool:
+		boolean, ok := input.(bool)
+		if !ok {
+			return invalidValue
+		}
+		value.SetBool(boolean)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, ok := input.(json.Number)
+		if !ok {
+			return invalidValue
+		}
+		integer, err := strconv.ParseInt(number.String(), 10, value.Type().Bits())
+		if err != nil {
+			return invalidValue
+		}
+		value.SetInt(integer)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, ok := input.(json.Number)
+		if !ok {
+			return invalidValue
+		}
+		integer, err := strconv.ParseUint(number.String(), 10, value.Type().Bits())
+		if err != nil {
+			return invalidValue
+		}
+		value.SetUint(integer)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		number, ok := input.(json.Number)
+		if !ok {
+			return invalidValue
+		}
+		float, err := strconv.ParseFloat(number.String(), value.Type().Bits())
+		if err != nil {
+			return invalidValue
+		}
+		value.SetFloat(float)
+		return nil
+	}
+	return fmt.Errorf("%w: %q", errmsg.ErrLineParsingUnsupportedDataType, path)
+}
+
+// Key of a struct field: the ASTM position (e.g. "4" or "4.1") if JSONKeys is positions and the field has one, the Go field name otherwise
+func jsonKey(field reflect.StructField, config *astmmodels.Configuration) (key string, err error) {
+	if config.JSONKeys != jsonkeys.Positions {
+		return field.Name, nil
+	}
+	annotation, err := ParseAstmFieldAnnotation(field)
+	if err != nil {
+		if errors.Is(err, errmsg.ErrAnnotationParsingMissingAstmAnnotation) {
+			return field.Name, nil
+		}
+		return "", err
+	}
+	if annotation.IsComponent {
+		return fmt.Sprintf("%d.%d", annotation.FieldPos, annotation.ComponentPos), nil
+	}
+	return strconv.Itoa(annotation.FieldPos), nil
+}
+
+func checkUnknownKeys(object map[string]interface{}, knownKeys map[string]bool, path string) error {
+	// Sorted for a deterministic error
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !knownKeys[key] {
+			return fmt.Errorf("%w: %q", errmsg.ErrJSONConversionUnknownKey, joinPath(path, key))
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package functions
 
 import (
 	"errors"
+	"fmt"
 	"github.com/krendel52/go-astm/v3/constants"
 	notationconst "github.com/krendel52/go-astm/v3/enums/notation"
 	"github.com/krendel52/go-astm/v3/errmsg"
@@ -32,6 +33,17 @@ func BuildLine(sourceStruct interface{}, lineTypeName string, sequenceNumber int
 	fieldMap[1] = lineTypeName
 	// If it's a header, add the other delimiters
 	if lineTypeName == "H" {
+		// Delimiters captured in the header (e.g. by unmarshal) are used for the whole message
+		if delimiters, found := capturedDelimiters(sourceStruct); found {
+			config.Delimiters = delimiters
+		}
+		// In preservation mode the delimiters of the original header are kept
+		if rawLine, found := getRawRecord(sourceStruct, config); found && len(rawLine) >= 5 && rawLine[0] == 'H' {
+			config.Delimiters.Field = string(rawLine[1])
+			config.Delimiters.Repeat = string(rawLine[2])
+			config.Delimiters.Component = string(rawLine[3])
+			config.Delimiters.Escape = string(rawLine[4])
+		}
 		fieldMap[2] = config.Delimiters.Repeat +
 			config.Delimiters.Component +
 			config.Delimiters.Escape
@@ -53,42 +65,25 @@ func BuildLine(sourceStruct interface{}, lineTypeName string, sequenceNumber int
 			}
 		}
 
+		// The delimiters field of the header is written from the configuration
+		if _, exists := sourceFieldAnnotation.Attributes[constants.AttributeDelimiters]; exists {
+			continue
+		}
+
 		// Check for fieldPos not being lower than 3 (first 2 are reserved for line name and sequence number)
 		if sourceFieldAnnotation.FieldPos < 3 {
 			return "", errmsg.ErrLineBuildingReservedFieldPosReference
 		}
 
 		fieldValueString := ""
-		// If the field is an array, iterate over its elements and use the Repeat delimiter
-		if sourceFieldAnnotation.IsArray {
-			for j := 0; j < sourceValues[i].Len(); j++ {
-				elementValue := sourceValues[i].Index(j)
-				convertedValue := ""
-				if sourceFieldAnnotation.IsSubstructure {
-					// If the field is a substructure use buildSubstructure to process it
-					convertedValue, err = buildSubstructure(elementValue.Interface(), config)
-					if err != nil {
-						return "", err
-					}
-				} else {
-					// Simple field, convert it directly
-					convertedValue, err = convertField(elementValue, sourceFieldAnnotation, config)
-					if err != nil {
-						return "", err
-					}
-				}
-				fieldValueString += convertedValue
-				if j < sourceValues[i].Len()-1 {
-					fieldValueString += config.Delimiters.Repeat
-				}
-			}
-		} else if sourceFieldAnnotation.IsComponent {
+		if sourceFieldAnnotation.IsComponent {
 			if slices.Contains(processedComponentFields, sourceFieldAnnotation.FieldPos) {
 				// If the field is already processed, skip it
 				continue
 			}
-			// Create a map to store the component values indexed by ComponentPos
-			componentMap := make(map[int]string)
+			// Create maps to store the component values of each repeat indexed by ComponentPos
+			// Component arrays spread over the repeats, the single components go to the first repeat
+			componentMaps := []map[int]string{make(map[int]string)}
 			// Iterate over the whole inputFields of the targetStruct struct to find the components anywhere
 			for j := 0; j < sourceTypesLength; j++ {
 				// Parse the targetStruct field targetFieldAnnotation
@@ -102,32 +97,53 @@ func BuildLine(sourceStruct interface{}, lineTypeName string, sequenceNumber int
 					}
 				}
 				// If the field number is the same as the sourceFieldAnnotation, process it
-				if currentFieldAnnotation.FieldPos == sourceFieldAnnotation.FieldPos {
+				if currentFieldAnnotation.FieldPos != sourceFieldAnnotation.FieldPos {
+					continue
+				}
+				if currentFieldAnnotation.IsArray {
+					// Convert each element into the component of its repeat
+					for k := 0; k < sourceValues[j].Len(); k++ {
+						if k >= len(componentMaps) {
+							componentMaps = append(componentMaps, make(map[int]string))
+						}
+						componentValue, err := convertField(sourceValues[j].Index(k), currentFieldAnnotation, config)
+						if err != nil {
+							return "", err
+						}
+						componentMaps[k][currentFieldAnnotation.ComponentPos] = applyDefault(componentValue, currentFieldAnnotation)
+					}
+				} else {
 					// Convert current component
 					componentValue, err := convertField(sourceValues[j], currentFieldAnnotation, config)
 					if err != nil {
 						return "", err
 					}
-					// Store the value in the component map
-					componentMap[currentFieldAnnotation.ComponentPos] = componentValue
+					// Store the value in the component map of the first repeat
+					componentMaps[0][currentFieldAnnotation.ComponentPos] = applyDefault(componentValue, currentFieldAnnotation)
 				}
 			}
 			// Construct the result into the fieldValueString
-			fieldValueString = constructResult(componentMap, config.Delimiters.Component, config.Notation)
+			repeats := make([]string, len(componentMaps))
+			for k, componentMap := range componentMaps {
+				repeats[k] = constructResult(componentMap, config.Delimiters.Component, config.Notation)
+			}
+			fieldValueString = strings.Join(repeats, config.Delimiters.Repeat)
 			// Mark the field as processed
 			processedComponentFields = append(processedComponentFields, sourceFieldAnnotation.FieldPos)
-		} else if sourceFieldAnnotation.IsSubstructure {
-			// If the field is a substructure use buildSubstructure to process it
-			fieldValueString, err = buildSubstructure(sourceValues[i].Interface(), config)
+		} else if sourceFieldAnnotation.IsArray || sourceFieldAnnotation.IsSubstructure {
+			// If the field is an array or a substructure use buildNestedValue to process it (with any nesting inside)
+			fieldValueString, err = buildNestedValue(sourceValues[i], sourceFieldAnnotation, 0, config)
 			if err != nil {
 				return "", err
 			}
+			fieldValueString = applyDefault(fieldValueString, sourceFieldAnnotation)
 		} else {
 			// If the field is not an array, convert it directly
 			fieldValueString, err = convertField(sourceValues[i], sourceFieldAnnotation, config)
 			if err != nil {
 				return "", err
 			}
+			fieldValueString = applyDefault(fieldValueString, sourceFieldAnnotation)
 		}
 
 		// Store the field value in the map using FieldPos as the key
@@ -137,10 +153,47 @@ func BuildLine(sourceStruct interface{}, lineTypeName string, sequenceNumber int
 	// Construct the result string based on the field map
 	result = constructResult(fieldMap, config.Delimiters.Field, config.Notation)
 
+	// In preservation mode the fields that did not change are taken from the original line
+	if rawLine, found := getRawRecord(sourceStruct, config); found {
+		result = preserveRawLine(sourceStruct, lineTypeName, sequenceNumber, result, rawLine, config)
+	}
+
 	return result, nil
 }
 
-func buildSubstructure(sourceStruct interface{}, config *astmmodels.Configuration) (result string, err error) {
+func buildNestedValue(sourceValue reflect.Value, annotation models.AstmFieldAnnotation, used nestingLevel, config *astmmodels.Configuration) (result string, err error) {
+	switch {
+	case isNestedArrayType(sourceValue.Type()):
+		// Join the elements built on the next level with the next free delimiter
+		level, delimiter, ok := nextNestingLevel(arrayNestingOrder, used, config)
+		if !ok {
+			return "", errmsg.ErrLineBuildingNestingTooDeep
+		}
+		elements := make([]string, sourceValue.Len())
+		for j := 0; j < sourceValue.Len(); j++ {
+			elements[j], err = buildNestedValue(sourceValue.Index(j), annotation, used|level, config)
+			if err != nil {
+				return "", err
+			}
+		}
+		return strings.Join(elements, delimiter), nil
+	case isNestedSubstructureType(sourceValue.Type()):
+		// A substructure without any values is written as an empty field with the omitempty attribute
+		if _, exists := annotation.Attributes[constants.AttributeOmitempty]; exists && sourceValue.IsZero() {
+			return "", nil
+		}
+		// Use buildSubstructure with the next free delimiter to process it
+		level, delimiter, ok := nextNestingLevel(substructureNestingOrder, used, config)
+		if !ok {
+			return "", errmsg.ErrLineBuildingNestingTooDeep
+		}
+		return buildSubstructure(sourceValue.Interface(), delimiter, used|level, config)
+	}
+	// Simple value, convert it directly
+	return convertField(sourceValue, annotation, config)
+}
+
+func buildSubstructure(sourceStruct interface{}, delimiter string, used nestingLevel, config *astmmodels.Configuration) (result string, err error) {
 	// Process the target structure
 	sourceTypes, sourceValues, sourceTypesLength, err := ProcessStructReflection(sourceStruct)
 	if err != nil {
@@ -162,22 +215,30 @@ func buildSubstructure(sourceStruct interface{}, config *astmmodels.Configuratio
 				return "", err
 			}
 		}
-		// Convert the component directly
-		componentValueString, err := convertField(sourceValues[i], sourceFieldAnnotation, config)
+		// Convert the component (arrays and substructures are processed on the next nesting level)
+		componentValueString, err := buildNestedValue(sourceValues[i], sourceFieldAnnotation, used, config)
 		if err != nil {
 			return "", err
 		}
 		// Store the component value in the map using FieldPos as the key
-		componentMap[sourceFieldAnnotation.FieldPos] = componentValueString
+		componentMap[sourceFieldAnnotation.FieldPos] = applyDefault(componentValueString, sourceFieldAnnotation)
 	}
 
 	// Construct the result string
-	result = constructResult(componentMap, config.Delimiters.Component, config.Notation)
+	result = constructResult(componentMap, delimiter, config.Notation)
 
 	// Return result with no error
 	return result, nil
 }
 
+// Use the value of the default attribute (if any) for an empty field
+func applyDefault(value string, annotation models.AstmFieldAnnotation) string {
+	if defaultValue, exists := annotation.Attributes[constants.AttributeDefault]; exists && value == "" {
+		return defaultValue
+	}
+	return value
+}
+
 func constructResult(fieldMap map[int]string, delimiter string, notation string) (result string) {
 	// Determine how many fields to include by finding the biggest index
 	lastIndex := 0
@@ -211,14 +272,24 @@ func convertField(field reflect.Value, annotation models.AstmFieldAnnotation, co
 		}
 		field = field.Elem()
 	}
+	// Zero values are written as empty fields with the omitempty attribute
+	if _, exists := annotation.Attributes[constants.AttributeOmitempty]; exists && field.IsZero() {
+		return "", nil
+	}
+	// Zero values are replaced with the value of the default attribute (by applyDefault), as in unmarshal
+	if _, exists := annotation.Attributes[constants.AttributeDefault]; exists && field.IsZero() {
+		return "", nil
+	}
 	// Format the result as a string based on the field type
 	switch field.Kind() {
 	case reflect.String:
 		if field.Type().ConvertibleTo(reflect.TypeOf("")) {
+			result, err = constrainString(field.String(), annotation, config)
+			if err != nil {
+				return "", err
+			}
 			if config.EscapeOutputStrings {
-				result = buildStringEscapeChars(field.String(), config)
-			} else {
-				result = field.String()
+				result = buildStringEscapeChars(result, config)
 			}
 		} else {
 			return "", errmsg.ErrLineBuildingUsupportedDataType
@@ -278,10 +349,45 @@ func buildStringEscapeChars(input string, config *astmmodels.Configuration) stri
 		if inputRunes[i] == rune(config.Delimiters.Field[0]) ||
 			inputRunes[i] == rune(config.Delimiters.Repeat[0]) ||
 			inputRunes[i] == rune(config.Delimiters.Component[0]) ||
-			inputRunes[i] == rune(config.Delimiters.Escape[0]) {
+			inputRunes[i] == rune(config.Delimiters.Escape[0]) ||
+			(config.Delimiters.SubComponent != "" && inputRunes[i] == rune(config.Delimiters.SubComponent[0])) {
 			builder.WriteRune(rune(config.Delimiters.Escape[0]))
 		}
 		builder.WriteRune(inputRunes[i])
 	}
 	return builder.String()
 }
+
+// Apply the string constraint attributes (trim, upper, charset, maxlen) to an output string
+func constrainString(value string, annotation models.AstmFieldAnnotation, config *astmmodels.Configuration) (result string, err error) {
+	result = normalizeString(value, annotation)
+	// Check the allowed characters
+	valid, supported := checkCharset(result, annotation)
+	if !supported {
+		return "", fmt.Errorf("%w: %q", errmsg.ErrLineBuildingUnsupportedCharset, annotation.Attributes[constants.AttributeCharset])
+	}
+	if !valid {
+		return "", fmt.Errorf("%w: field %s", errmsg.ErrLineBuildingInvalidCharset, annotationPosition(annotation))
+	}
+	// Check the maximum length (in characters), truncate if it is enabled
+	maxlenValue, exists := annotation.Attributes[constants.AttributeMaxlen]
+	if !exists {
+		return result, nil
+	}
+	maxlen, err := strconv.Atoi(maxlenValue)
+	if err != nil || maxlen < 0 {
+		return "", fmt.Errorf("%w: %q", errmsg.ErrLineBuildingInvalidMaxlenAttributeValue, maxlenValue)
+	}
+	runes := []rune(result)
+	if len(runes) <= maxlen {
+		return result, nil
+	}
+	if !config.TruncateLongStrings {
+		return "", fmt.Errorf("%w: field %s has %d characters (max %d)", errmsg.ErrLineBuildingMaxLengthExceeded, annotationPosition(annotation), len(runes), maxlen)
+	}
+	truncated := string(runes[:maxlen])
+	if config.WarningHandler != nil {
+		config.WarningHandler(fmt.Errorf("%w: field %s truncated from %d to %d characters", errmsg.ErrLineBuildingMaxLengthExceeded, annotationPosition(annotation), len(runes), maxlen))
+	}
+	return truncated, nil
+}
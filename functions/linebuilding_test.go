@@ -3,6 +3,7 @@ package functions
 import (
 	"github.com/krendel52/go-astm/v3/enums/notation"
 	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
 	"github.com/stretchr/testify/assert"
 	"testing"
 	"time"
@@ -258,6 +259,34 @@ func TestBuildLine_HeaderRecordCustomDelimiters(t *testing.T) {
 	// Teardown
 	teardown()
 }
+func TestBuildLine_HeaderCapturedDelimiters(t *testing.T) {
+	// Arrange
+	source := HeaderAnnotatedDelimiters{
+		Captured: astmmodels.Delimiters{Field: "/", Repeat: "!", Component: "*", Escape: "%"},
+		First:    "first",
+	}
+	// Act
+	result, err := BuildLine(source, "H", 0, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "H/!*%/first", result)
+	// Check the captured delimiters are used for the rest of the message
+	assert.Equal(t, "/", config.Delimiters.Field)
+	// Teardown
+	teardown()
+}
+func TestBuildLine_HeaderIncompleteCapturedDelimiters(t *testing.T) {
+	// Arrange
+	source := HeaderAnnotatedDelimiters{
+		Captured: astmmodels.Delimiters{Field: "/"},
+		First:    "first",
+	}
+	// Act
+	result, err := BuildLine(source, "H", 0, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "H|\\^&|first", result)
+}
 func TestBuildLine_HeaderDelimiterChange(t *testing.T) {
 	// Arrange
 	source := HeaderDelimiterChange{
@@ -387,6 +416,43 @@ func TestBuildLine_SubstructureRecordMissingData(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "T|1||firstComponent^^|", result)
 }
+func TestBuildLine_SubstructureRecordEmptySubstructure(t *testing.T) {
+	// Arrange
+	source := SubstructureRecord{
+		First: "first",
+		Third: "third",
+	}
+	// Act
+	result, err := BuildLine(source, "T", 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "T|1|first|^^|third", result)
+}
+func TestBuildLine_OmitemptySubstructureRecordEmptySubstructure(t *testing.T) {
+	// Arrange
+	source := OmitemptySubstructureRecord{
+		First: "first",
+		Third: "third",
+	}
+	// Act
+	result, err := BuildLine(source, "T", 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "T|1|first||third", result)
+}
+func TestBuildLine_OmitemptySubstructureRecordPartialSubstructure(t *testing.T) {
+	// Arrange
+	source := OmitemptySubstructureRecord{
+		Second: SubstructureField{
+			SecondComponent: "secondComponent",
+		},
+	}
+	// Act
+	result, err := BuildLine(source, "T", 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "T|1||^secondComponent^|", result)
+}
 func TestBuildLine_SubstructureRecordMissingDataShortNotation(t *testing.T) {
 	// Arrange
 	source := SubstructureRecord{
@@ -443,6 +509,74 @@ func TestBuildLine_SubstructureArrayRecord(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "T|1|first|r1c1^r1c2^r1c3\\r2c1^r2c2^r2c3|third", result)
 }
+func TestBuildLine_NestedArraySubstructureRecord(t *testing.T) {
+	// Arrange
+	source := NestedArraySubstructureRecord{
+		First: "first",
+		Second: NestedArrayField{
+			Name:   "name",
+			Values: []string{"v1", "v2", "v3"},
+			Unit:   "unit",
+		},
+	}
+	// Act
+	result, err := BuildLine(source, "T", 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "T|1|first|name^v1\\v2\\v3^unit", result)
+}
+func TestBuildLine_NestedArraySubstructureArrayRecord(t *testing.T) {
+	// Arrange
+	source := NestedArraySubstructureArrayRecord{
+		First: []NestedArrayField{
+			{Name: "r1", Values: []string{"v1", "v2"}, Unit: "unit1"},
+			{Name: "r2", Values: []string{"v3"}, Unit: "unit2"},
+		},
+	}
+	config.Delimiters.SubComponent = "~"
+	// Act
+	result, err := BuildLine(source, "T", 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "T|1|r1^v1~v2^unit1\\r2^v3^unit2", result)
+	// Teardown
+	teardown()
+}
+func TestBuildLine_NestedTooDeepWithoutSubComponentDelimiter(t *testing.T) {
+	// Arrange
+	source := NestedArraySubstructureArrayRecord{
+		First: []NestedArrayField{{Name: "r1", Values: []string{"v1", "v2"}}},
+	}
+	// Act
+	_, err := BuildLine(source, "T", 1, config)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLineBuildingNestingTooDeep)
+}
+func TestBuildLine_NestedSubstructureRecord(t *testing.T) {
+	// Arrange
+	source := NestedSubstructureRecord{
+		First: NestedSubstructureField{
+			Name:  "name",
+			Inner: SubstructureField{FirstComponent: "c1", SecondComponent: "c2", ThirdComponent: "c3"},
+		},
+	}
+	// Act
+	result, err := BuildLine(source, "T", 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "T|1|name^c1\\c2\\c3", result)
+}
+func TestBuildLine_NestedArrayOfArraysRecord(t *testing.T) {
+	// Arrange
+	source := NestedArrayOfArraysRecord{
+		First: [][]int{{1, 2}, {3, 4, 5}},
+	}
+	// Act
+	result, err := BuildLine(source, "T", 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "T|1|1^2\\3^4^5", result)
+}
 
 func TestBuildLine_TimeLineTimeZone(t *testing.T) {
 	// Arrange
@@ -607,3 +741,135 @@ func TestBuildLine_EscapedCharsNoEscape(t *testing.T) {
 	// Teardown
 	teardown()
 }
+
+func TestBuildLine_ComponentArrayRecord(t *testing.T) {
+	// Arrange
+	source := ComponentArrayRecord{
+		IDs:      []string{"id1", "id2"},
+		Counts:   []int{1, 2, 3},
+		Operator: "op1",
+		Verifier: "ver1",
+		Sites:    []string{"site1", "site2"},
+	}
+	// Act
+	result, err := BuildLine(source, "T", 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "T|1|id1^^1\\id2^^2\\^^3|op1^ver1^site1\\^^site2", result)
+}
+
+func TestBuildLine_OmitemptyDefaultAttributes(t *testing.T) {
+	// Arrange
+	source := OmitemptyDefaultRecord{}
+	// Act
+	result, err := BuildLine(source, "T", 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "T|1||||1|F|^X|A\\B|0", result)
+}
+func TestBuildLine_DefaultAttributeRoundTrip(t *testing.T) {
+	// Arrange
+	var target OmitemptyDefaultRecord
+	_, errParse := ParseLine("T|1|", &target, createStructAnnotation("T"), 1, config)
+	// Act
+	result, err := BuildLine(target, "T", 1, config)
+	// Assert
+	assert.Nil(t, errParse)
+	assert.Nil(t, err)
+	// The zero values are written with the default values, as they were filled in unmarshal
+	assert.Equal(t, "T|1||||1|F|^X|A\\B|0", result)
+}
+func TestBuildLine_OmitemptyDefaultAttributesWithValues(t *testing.T) {
+	// Arrange
+	source := OmitemptyDefaultRecord{
+		Int:        3,
+		Float:      1.5,
+		Time:       time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		IntDefault: 2,
+		Status:     "C",
+		Component:  "Y",
+		Array:      []string{"Z"},
+	}
+	config.TimeLocation = time.UTC
+	// Act
+	result, err := BuildLine(source, "T", 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "T|1|3|1.500|20240102030405|2|C|^Y|Z|0", result)
+	// Teardown
+	teardown()
+}
+
+func TestBuildLine_StringConstraintAttributes(t *testing.T) {
+	// Arrange
+	source := StringConstraintRecord{
+		Trimmed: "  ID  ",
+		Upper:   "neg",
+		Limited: "SHORT",
+		ASCII:   "abc",
+	}
+	// Act
+	result, err := BuildLine(source, "T", 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "T|1|ID|NEG|SHORT|abc", result)
+}
+func TestBuildLine_MaxLengthExceeded(t *testing.T) {
+	// Arrange
+	source := StringConstraintRecord{Limited: "TOOLONG"}
+	// Act
+	_, err := BuildLine(source, "T", 1, config)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLineBuildingMaxLengthExceeded)
+	// The value is not reported, only its position and length
+	assert.NotContains(t, err.Error(), "TOOLONG")
+	assert.Contains(t, err.Error(), "field 5 has 7 characters (max 5)")
+}
+func TestBuildLine_MaxLengthTruncated(t *testing.T) {
+	// Arrange
+	source := StringConstraintRecord{Limited: "TOOLONG"}
+	var warnings []error
+	config.TruncateLongStrings = true
+	config.WarningHandler = func(warning error) {
+		warnings = append(warnings, warning)
+	}
+	// Act
+	result, err := BuildLine(source, "T", 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "T|1|||TOOLO|", result)
+	assert.Len(t, warnings, 1)
+	assert.ErrorIs(t, warnings[0], errmsg.ErrLineBuildingMaxLengthExceeded)
+	assert.NotContains(t, warnings[0].Error(), "TOOL")
+	// Teardown
+	teardown()
+}
+func TestBuildLine_MaxLengthTruncatedWithoutWarningHandler(t *testing.T) {
+	// Arrange
+	source := StringConstraintRecord{Limited: "ÄÖÜÄÖÜ"}
+	config.TruncateLongStrings = true
+	// Act
+	result, err := BuildLine(source, "T", 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "T|1|||ÄÖÜÄÖ|", result)
+	// Teardown
+	teardown()
+}
+func TestBuildLine_CharsetViolation(t *testing.T) {
+	// Arrange
+	source := StringConstraintRecord{ASCII: "Müller"}
+	// Act
+	_, err := BuildLine(source, "T", 1, config)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLineBuildingInvalidCharset)
+	assert.NotContains(t, err.Error(), "Müller")
+}
+func TestBuildLine_InvalidMaxlenAttributeValue(t *testing.T) {
+	// Arrange
+	source := InvalidMaxlenRecord{First: "value"}
+	// Act
+	_, err := BuildLine(source, "T", 1, config)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLineBuildingInvalidMaxlenAttributeValue)
+}
@@ -15,6 +15,9 @@ import (
 )
 
 func ParseLine(inputLine string, targetStruct interface{}, recordAnnotation models.AstmStructAnnotation, sequenceNumber int, config *astmmodels.Configuration) (nameOk bool, err error) {
+	// Keep the original line (with any padding) for the round-trip preservation
+	rawLine := inputLine
+	inputLine = strings.Trim(inputLine, " ")
 	// Check for input line length
 	if len(inputLine) == 0 {
 		return false, errmsg.ErrLineParsingEmptyInput
@@ -42,8 +45,10 @@ func ParseLine(inputLine string, targetStruct interface{}, recordAnnotation mode
 
 		// Place the fix segment into the inputFields
 		inputFields = []string{inputLine[0:1], inputLine[1:5]}
-		// Add the rest of the inputLine split by the field delimiter
-		inputFields = append(inputFields, splitStringWithEscape(inputLine[6:], config.Delimiters.Field, config.Delimiters.Escape)...)
+		// Add the rest of the inputLine split by the field delimiter (a bare header has no more fields)
+		if len(inputLine) > 5 {
+			inputFields = append(inputFields, splitStringWithEscape(inputLine[6:], config.Delimiters.Field, config.Delimiters.Escape)...)
+		}
 	} else {
 		// Split the input with the field delimiter
 		inputFields = splitStringWithEscape(inputLine, config.Delimiters.Field, config.Delimiters.Escape)
@@ -80,6 +85,8 @@ func ParseLine(inputLine string, targetStruct interface{}, recordAnnotation mode
 	if err != nil {
 		return true, err
 	}
+	// Fields with component arrays are split into repeats before the components
+	repeatedFields := componentArrayFields(targetTypes)
 
 	// Iterate over the inputFields of the targetStruct struct
 	for i, targetType := range targetTypes {
@@ -94,6 +101,11 @@ func ParseLine(inputLine string, targetStruct interface{}, recordAnnotation mode
 			}
 		}
 
+		// The delimiters field of the header is set from the header line
+		if _, exists := targetFieldAnnotation.Attributes[constants.AttributeDelimiters]; exists {
+			continue
+		}
+
 		// Check for fieldPos not being lower than 3 (first 2 are reserved for line name and sequence number)
 		if targetFieldAnnotation.FieldPos < 3 {
 			return true, errmsg.ErrLineParsingReservedFieldPosReference
@@ -101,62 +113,74 @@ func ParseLine(inputLine string, targetStruct interface{}, recordAnnotation mode
 
 		// Not enough inputFields or empty inputField
 		if len(inputFields) < targetFieldAnnotation.FieldPos || inputFields[targetFieldAnnotation.FieldPos-1] == "" {
-			// If the field is required it's an error, otherwise skip it
+			// If the field is required it's an error, otherwise use the default or skip it
 			if _, exists := targetFieldAnnotation.Attributes[constants.AttributeRequired]; exists {
 				return true, errmsg.ErrLineParsingRequiredInputFieldMissing
-			} else {
-				continue
 			}
+			err = parseDefaultValue(targetValues[i], targetFieldAnnotation, 0, config)
+			if err != nil {
+				return true, err
+			}
+			continue
 		}
 		// Save the current inputField
 		inputField := inputFields[targetFieldAnnotation.FieldPos-1]
 
-		if targetFieldAnnotation.IsArray {
-			// |rep1\rep2\rep3|
-			// Field is an array
+		if targetFieldAnnotation.IsComponent && targetFieldAnnotation.IsArray {
+			// |r1c1^r1c2\r2c1^r2c2|
+			// Field is a component array: each repeat of the field contributes its component
 			repeats := splitStringWithEscape(inputField, config.Delimiters.Repeat, config.Delimiters.Escape)
-			arrayType := reflect.SliceOf(targetValues[i].Type().Elem())
-			arrayValue := reflect.MakeSlice(arrayType, len(repeats), len(repeats))
+			arrayValue := reflect.MakeSlice(targetValues[i].Type(), len(repeats), len(repeats))
 			for j, repeat := range repeats {
-				if targetFieldAnnotation.IsSubstructure {
-					// |comp1^comp2^comp3\comp1^comp2^comp3\comp1^comp2^comp3|
-					// Substructures (with components) in the array: use parseSubstructure
-					err = parseSubstructure(repeat, arrayValue.Index(j).Addr().Interface(), config)
-					if err != nil {
-						return true, err
-					}
-				} else {
-					// |value1\value2\value3|
-					// Simple values in the array
-					err = setField(repeat, arrayValue.Index(j), targetFieldAnnotation, config)
-					if err != nil {
-						return true, err
-					}
+				components := splitStringWithEscape(repeat, config.Delimiters.Component, config.Delimiters.Escape)
+				// Missing or empty components get the default value or are left empty
+				component := ""
+				if len(components) >= targetFieldAnnotation.ComponentPos {
+					component = components[targetFieldAnnotation.ComponentPos-1]
+				}
+				if component == "" {
+					component = targetFieldAnnotation.Attributes[constants.AttributeDefault]
+				}
+				if component == "" {
+					continue
+				}
+				err = setField(component, arrayValue.Index(j), targetFieldAnnotation, config)
+				if err != nil {
+					return true, err
 				}
-
 			}
 			targetValues[i].Set(arrayValue)
+		} else if targetFieldAnnotation.IsArray || targetFieldAnnotation.IsSubstructure {
+			// |rep1\rep2\rep3| or |comp1^comp2^comp3| (with any nesting inside)
+			// Field is an array or a substructure: use parseNestedValue to process it
+			err = parseNestedValue(inputField, targetValues[i], targetFieldAnnotation, 0, config)
+			if err != nil {
+				return true, err
+			}
 		} else if targetFieldAnnotation.IsComponent {
 			// |comp1^comp2^comp3|
-			// Field is a component
+			// Field is a component (of the first repeat if the field has component arrays)
+			if repeatedFields[targetFieldAnnotation.FieldPos] {
+				inputField = splitStringWithEscape(inputField, config.Delimiters.Repeat, config.Delimiters.Escape)[0]
+			}
 			components := splitStringWithEscape(inputField, config.Delimiters.Component, config.Delimiters.Escape)
 			// Not enough components in the inputField
 			if len(components) < targetFieldAnnotation.ComponentPos {
-				// Error if the component is required, skip otherwise
+				// Error if the component is required, use the default or skip otherwise
 				if _, exists := targetFieldAnnotation.Attributes[constants.AttributeRequired]; exists {
 					return true, errmsg.ErrLineParsingInputComponentsMissing
-				} else {
-					continue
 				}
+				err = parseDefaultValue(targetValues[i], targetFieldAnnotation, 0, config)
+				if err != nil {
+					return true, err
+				}
+				continue
 			}
-			err = setField(components[targetFieldAnnotation.ComponentPos-1], targetValues[i], targetFieldAnnotation, config)
-			if err != nil {
-				return true, err
+			component := components[targetFieldAnnotation.ComponentPos-1]
+			if defaultValue, exists := targetFieldAnnotation.Attributes[constants.AttributeDefault]; exists && component == "" {
+				component = defaultValue
 			}
-		} else if targetFieldAnnotation.IsSubstructure {
-			// |comp1^comp2^comp3|
-			// If the field is a substructure use parseSubstructure to process it
-			err = parseSubstructure(inputField, targetValues[i].Addr().Interface(), config)
+			err = setField(component, targetValues[i], targetFieldAnnotation, config)
 			if err != nil {
 				return true, err
 			}
@@ -171,13 +195,72 @@ func ParseLine(inputLine string, targetStruct interface{}, recordAnnotation mode
 		// Note: this could be a place to produce warnings about lost data
 		// if i == targetFieldCount-1 && len(inputFields) > targetFieldAnnotation.FieldPos
 	}
+	// Keep the original line for the round-trip preservation
+	setRawRecord(targetStruct, rawLine, config)
 	// Return no error if everything went well
 	return true, nil
 }
 
-func parseSubstructure(inputString string, targetStruct interface{}, config *astmmodels.Configuration) (err error) {
-	// Split the input with the field delimiter
-	inputFields := splitStringWithEscape(inputString, config.Delimiters.Component, config.Delimiters.Escape)
+func parseNestedValue(inputString string, targetValue reflect.Value, annotation models.AstmFieldAnnotation, used nestingLevel, config *astmmodels.Configuration) (err error) {
+	switch {
+	case isNestedArrayType(targetValue.Type()):
+		// |rep1\rep2\rep3|
+		// Split the repeats with the next free delimiter and process each element on the next level
+		level, delimiter, ok := nextNestingLevel(arrayNestingOrder, used, config)
+		if !ok {
+			return errmsg.ErrLineParsingNestingTooDeep
+		}
+		repeats := splitStringWithEscape(inputString, delimiter, config.Delimiters.Escape)
+		// Fixed size arrays keep their length, the repeats beyond it are ignored
+		arrayValue := reflect.New(targetValue.Type()).Elem()
+		if targetValue.Kind() == reflect.Slice {
+			arrayValue = reflect.MakeSlice(targetValue.Type(), len(repeats), len(repeats))
+		} else if len(repeats) > arrayValue.Len() {
+			repeats = repeats[:arrayValue.Len()]
+		}
+		for j, repeat := range repeats {
+			err = parseNestedValue(repeat, arrayValue.Index(j), annotation, used|level, config)
+			if err != nil {
+				return err
+			}
+		}
+		targetValue.Set(arrayValue)
+		return nil
+	case isNestedSubstructureType(targetValue.Type()):
+		// |comp1^comp2^comp3|
+		// Split the components with the next free delimiter and use parseSubstructure to process them
+		level, delimiter, ok := nextNestingLevel(substructureNestingOrder, used, config)
+		if !ok {
+			return errmsg.ErrLineParsingNestingTooDeep
+		}
+		return parseSubstructure(inputString, targetValue.Addr().Interface(), delimiter, used|level, config)
+	}
+	// Simple value
+	return setField(inputString, targetValue, annotation, config)
+}
+
+// Set the value of the default attribute (if any) for an empty field
+func parseDefaultValue(targetValue reflect.Value, annotation models.AstmFieldAnnotation, used nestingLevel, config *astmmodels.Configuration) (err error) {
+	defaultValue, exists := annotation.Attributes[constants.AttributeDefault]
+	if !exists {
+		return nil
+	}
+	if annotation.IsComponent && targetValue.Kind() == reflect.Slice {
+		// Component arrays get a single repeat with the default
+		arrayValue := reflect.MakeSlice(targetValue.Type(), 1, 1)
+		err = setField(defaultValue, arrayValue.Index(0), annotation, config)
+		if err != nil {
+			return err
+		}
+		targetValue.Set(arrayValue)
+		return nil
+	}
+	return parseNestedValue(defaultValue, targetValue, annotation, used, config)
+}
+
+func parseSubstructure(inputString string, targetStruct interface{}, delimiter string, used nestingLevel, config *astmmodels.Configuration) (err error) {
+	// Split the input with the delimiter of the nesting level
+	inputFields := splitStringWithEscape(inputString, delimiter, config.Delimiters.Escape)
 
 	// Process the target structure
 	targetTypes, targetValues, _, err := ProcessStructReflection(targetStruct)
@@ -200,18 +283,21 @@ func parseSubstructure(inputString string, targetStruct interface{}, config *ast
 
 		// Not enough inputFields or empty inputField
 		if len(inputFields) < targetFieldAnnotation.FieldPos || inputFields[targetFieldAnnotation.FieldPos-1] == "" {
-			// If the field is required it's an error, otherwise skip it
+			// If the field is required it's an error, otherwise use the default or skip it
 			if _, exists := targetFieldAnnotation.Attributes[constants.AttributeRequired]; exists {
 				return errmsg.ErrLineParsingRequiredInputFieldMissing
-			} else {
-				continue
 			}
+			err = parseDefaultValue(targetValues[i], targetFieldAnnotation, used, config)
+			if err != nil {
+				return err
+			}
+			continue
 		}
 		// Save the current inputField
 		inputField := inputFields[targetFieldAnnotation.FieldPos-1]
 
-		// Set field is value
-		err = setField(inputField, targetValues[i], targetFieldAnnotation, config)
+		// Set field value (arrays and substructures are processed on the next nesting level)
+		err = parseNestedValue(inputField, targetValues[i], targetFieldAnnotation, used, config)
 		if err != nil {
 			return err
 		}
@@ -230,12 +316,24 @@ func setField(value string, field reflect.Value, annotation models.AstmFieldAnno
 	// Set the field value
 	switch field.Kind() {
 	case reflect.String:
-		escaped := filterStringEscapeChars(value, config.Delimiters.Escape)
+		escaped := normalizeString(filterStringEscapeChars(value, config.Delimiters.Escape), annotation)
+		// Check the allowed characters
+		valid, supported := checkCharset(escaped, annotation)
+		if !supported {
+			return fmt.Errorf("%w: %q", errmsg.ErrLineParsingUnsupportedCharset, annotation.Attributes[constants.AttributeCharset])
+		}
+		if !valid {
+			return fmt.Errorf("%w: field %s", errmsg.ErrLineParsingInvalidCharset, annotationPosition(annotation))
+		}
 		if field.Type().ConvertibleTo(reflect.TypeOf("")) {
 			field.Set(reflect.ValueOf(escaped).Convert(field.Type()))
 		} else {
 			field.Set(reflect.ValueOf(escaped))
 		}
+		// Check the enum value if validation is enabled and the type supports it
+		if enum, ok := field.Interface().(astmmodels.ValidatableEnum); ok && config.ValidateEnums && !enum.IsValid() {
+			return fmt.Errorf("%w: %q", errmsg.ErrLineParsingInvalidEnumValue, escaped)
+		}
 		return nil
 	case reflect.Int:
 		num, err := strconv.Atoi(value)
@@ -294,10 +392,23 @@ func setField(value string, field reflect.Value, annotation models.AstmFieldAnno
 	return errmsg.ErrLineParsingUnsupportedDataType
 }
 
+// Rune never found in the input, used if no escape delimiter is given
+const noEscapeRune = rune(-1)
+
 func splitStringWithEscape(input, delimiter, escape string) []string {
+	// Without a delimiter there is nothing to split
+	if delimiter == "" {
+		if input == "" {
+			return nil
+		}
+		return []string{input}
+	}
 	var result []string
 	delimiterRune := rune(delimiter[0])
-	escapeRune := rune(escape[0])
+	escapeRune := noEscapeRune
+	if escape != "" {
+		escapeRune = rune(escape[0])
+	}
 	inputRunes := []rune(input)
 	start := 0
 	for i := 0; i < len(inputRunes); i++ {
@@ -328,6 +439,9 @@ func splitStringWithEscape(input, delimiter, escape string) []string {
 }
 
 func filterStringEscapeChars(input string, escape string) string {
+	if escape == "" {
+		return input
+	}
 	var builder strings.Builder
 	escapeRune := rune(escape[0])
 	inputRunes := []rune(input)
@@ -364,27 +478,15 @@ func setDelimiters(targetStruct interface{}, delimiters astmmodels.Delimiters) e
 		return fmt.Errorf("targetStruct must point to a struct")
 	}
 
-	field := v.FieldByName("Delimiters")
-
-	// Check field existence
-	if !field.IsValid() {
-		return fmt.Errorf("field Delimiters not found")
+	// Find the delimiters field, it is optional
+	field, found, err := findDelimitersField(v, true)
+	if err != nil || !found {
+		return err
 	}
 
 	// Check settable
 	if !field.CanSet() {
-		return fmt.Errorf("field Delimiters cannot be set")
-	}
-
-	// Check type
-	expectedType := reflect.TypeOf(astmmodels.Delimiters{})
-
-	if field.Type() != expectedType {
-		return fmt.Errorf(
-			"invalid field type: expected %v, got %v",
-			expectedType,
-			field.Type(),
-		)
+		return fmt.Errorf("delimiters field cannot be set")
 	}
 
 	field.Set(reflect.ValueOf(delimiters))
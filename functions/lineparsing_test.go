@@ -1,10 +1,13 @@
 package functions
 
 import (
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/krendel52/go-astm/v3/constants"
 	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models"
 	"github.com/krendel52/go-astm/v3/models/astmmodels"
 	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
 	"github.com/stretchr/testify/assert"
@@ -133,6 +136,66 @@ func TestParseLine_HeaderDelimiterChange(t *testing.T) {
 	teardown()
 }
 
+func TestParseLine_HeaderWithoutDelimiters(t *testing.T) {
+	// Arrange
+	input := "H|\\^&|first"
+	target := HeaderWithoutDelimiters{}
+	// Act
+	nameOk, err := ParseLine(input, &target, createStructAnnotation("H"), 0, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, nameOk)
+	assert.Equal(t, "first", target.First)
+}
+
+func TestParseLine_BareHeader(t *testing.T) {
+	// Arrange
+	input := "H|\\^&"
+	target := HeaderRecord{}
+	// Act
+	nameOk, err := ParseLine(input, &target, createStructAnnotation("H"), 0, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, nameOk)
+	assert.Equal(t, "", target.First)
+}
+
+func TestParseLine_HeaderTooShort(t *testing.T) {
+	// Arrange
+	target := HeaderRecord{}
+	// Act
+	_, err := ParseLine("H|\\^", &target, createStructAnnotation("H"), 0, config)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLineParsingHeaderTooShort)
+}
+
+func TestParseLine_HeaderAnnotatedDelimiters(t *testing.T) {
+	// Arrange
+	input := "H/!*%/first"
+	target := HeaderAnnotatedDelimiters{}
+	// Act
+	_, err := ParseLine(input, &target, createStructAnnotation("H"), 0, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "/", target.Captured.Field)
+	assert.Equal(t, "!", target.Captured.Repeat)
+	assert.Equal(t, "*", target.Captured.Component)
+	assert.Equal(t, "%", target.Captured.Escape)
+	assert.Equal(t, "first", target.First)
+	// Teardown
+	teardown()
+}
+
+func TestParseLine_HeaderInvalidDelimitersField(t *testing.T) {
+	// Arrange
+	input := "H|\\^&"
+	target := HeaderInvalidDelimiters{}
+	// Act
+	_, err := ParseLine(input, &target, createStructAnnotation("H"), 0, config)
+	// Assert
+	assert.NotNil(t, err)
+}
+
 func TestParseLine_MissingData(t *testing.T) {
 	// Arrange
 	input := "T|1|first||third"
@@ -196,6 +259,47 @@ func TestParseLine_EnumRecord(t *testing.T) {
 	assert.Equal(t, EnumString("enum"), target.Enum)
 }
 
+func TestParseLine_ValidatedEnumRecordValidationDisabled(t *testing.T) {
+	// Arrange
+	input := "T|1|invalid"
+	target := ValidatedEnumRecord{}
+	// Act
+	nameOk, err := ParseLine(input, &target, createStructAnnotation("T"), 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, nameOk)
+	assert.Equal(t, ValidatedEnumString("invalid"), target.Enum)
+}
+
+func TestParseLine_ValidatedEnumRecordValid(t *testing.T) {
+	// Arrange
+	input := "T|1|valid"
+	target := ValidatedEnumRecord{}
+	config.ValidateEnums = true
+	// Act
+	nameOk, err := ParseLine(input, &target, createStructAnnotation("T"), 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, nameOk)
+	assert.Equal(t, ValidatedEnumString("valid"), target.Enum)
+	// Teardown
+	teardown()
+}
+
+func TestParseLine_ValidatedEnumRecordInvalid(t *testing.T) {
+	// Arrange
+	input := "T|1|invalid"
+	target := ValidatedEnumRecord{}
+	config.ValidateEnums = true
+	// Act
+	nameOk, err := ParseLine(input, &target, createStructAnnotation("T"), 1, config)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLineParsingInvalidEnumValue)
+	assert.True(t, nameOk)
+	// Teardown
+	teardown()
+}
+
 func TestParseLine_RecordTypeNameMismatch(t *testing.T) {
 	// Arrange
 	input := "W|1|first|second|third"
@@ -368,6 +472,74 @@ func TestParseLine_SubstructureArrayRecord(t *testing.T) {
 	assert.Equal(t, "third", target.Third)
 }
 
+func TestParseLine_NestedArraySubstructureRecord(t *testing.T) {
+	// Arrange
+	input := "T|1|first|name^v1\\v2\\v3^unit"
+	target := NestedArraySubstructureRecord{}
+	// Act
+	_, err := ParseLine(input, &target, createStructAnnotation("T"), 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "first", target.First)
+	assert.Equal(t, "name", target.Second.Name)
+	assert.Equal(t, []string{"v1", "v2", "v3"}, target.Second.Values)
+	assert.Equal(t, "unit", target.Second.Unit)
+}
+
+func TestParseLine_NestedArraySubstructureArrayRecord(t *testing.T) {
+	// Arrange
+	input := "T|1|r1^v1~v2^unit1\\r2^v3^unit2"
+	target := NestedArraySubstructureArrayRecord{}
+	config.Delimiters.SubComponent = "~"
+	// Act
+	_, err := ParseLine(input, &target, createStructAnnotation("T"), 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, target.First, 2)
+	assert.Equal(t, "r1", target.First[0].Name)
+	assert.Equal(t, []string{"v1", "v2"}, target.First[0].Values)
+	assert.Equal(t, "unit1", target.First[0].Unit)
+	assert.Equal(t, []string{"v3"}, target.First[1].Values)
+	assert.Equal(t, "unit2", target.First[1].Unit)
+	// Teardown
+	teardown()
+}
+
+func TestParseLine_NestedTooDeepWithoutSubComponentDelimiter(t *testing.T) {
+	// Arrange
+	input := "T|1|r1^v1~v2^unit1\\r2^v3^unit2"
+	target := NestedArraySubstructureArrayRecord{}
+	// Act
+	_, err := ParseLine(input, &target, createStructAnnotation("T"), 1, config)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLineParsingNestingTooDeep)
+}
+
+func TestParseLine_NestedSubstructureRecord(t *testing.T) {
+	// Arrange
+	input := "T|1|name^c1\\c2\\c3"
+	target := NestedSubstructureRecord{}
+	// Act
+	_, err := ParseLine(input, &target, createStructAnnotation("T"), 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "name", target.First.Name)
+	assert.Equal(t, "c1", target.First.Inner.FirstComponent)
+	assert.Equal(t, "c2", target.First.Inner.SecondComponent)
+	assert.Equal(t, "c3", target.First.Inner.ThirdComponent)
+}
+
+func TestParseLine_NestedArrayOfArraysRecord(t *testing.T) {
+	// Arrange
+	input := "T|1|1^2\\3^4^5"
+	target := NestedArrayOfArraysRecord{}
+	// Act
+	_, err := ParseLine(input, &target, createStructAnnotation("T"), 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, [][]int{{1, 2}, {3, 4, 5}}, target.First)
+}
+
 func TestParseLine_TimeLineTimeZone(t *testing.T) {
 	// Arrange
 	input := "T|1|20060306164429"
@@ -505,6 +677,22 @@ func TestSplitStringWithEscape_Unicode(t *testing.T) {
 	assert.Equal(t, "third", result[2])
 }
 
+func TestSplitStringWithEscape_NoDelimiter(t *testing.T) {
+	// Act
+	result := splitStringWithEscape("first|second", "", config.Delimiters.Escape)
+	empty := splitStringWithEscape("", "", config.Delimiters.Escape)
+	// Assert
+	assert.Equal(t, []string{"first|second"}, result)
+	assert.Len(t, empty, 0)
+}
+
+func TestSplitStringWithEscape_NoEscapeDelimiter(t *testing.T) {
+	// Act
+	result := splitStringWithEscape("first&|second", config.Delimiters.Field, "")
+	// Assert
+	assert.Equal(t, []string{"first&", "second"}, result)
+}
+
 func TestFilterEscapeChars_Delimiters(t *testing.T) {
 	// Arrange
 	input := "escaped&| and&^ and&&"
@@ -550,3 +738,142 @@ func TestFilterEscapeChars_Unicode(t *testing.T) {
 	// Assert
 	assert.Equal(t, "őáúäö|", result)
 }
+
+func TestParseLine_FixedSizeArrayRecord(t *testing.T) {
+	// Arrange
+	input := "T|1|v1\\v2\\v3"
+	target := struct {
+		First [2]string `astm:"3"`
+	}{}
+	// Act
+	_, err := ParseLine(input, &target, createStructAnnotation("T"), 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, [2]string{"v1", "v2"}, target.First)
+}
+
+func TestParseLine_ComponentArrayRecord(t *testing.T) {
+	// Arrange
+	input := "T|1|id1^^1\\id2^^\\^^3|op1^ver1^site1\\op2^ver2^site2"
+	target := ComponentArrayRecord{}
+	// Act
+	_, err := ParseLine(input, &target, createStructAnnotation("T"), 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"id1", "id2", ""}, target.IDs)
+	assert.Equal(t, []int{1, 0, 3}, target.Counts)
+	// Check the single components read from the first repeat of the repeated field
+	assert.Equal(t, "op1", target.Operator)
+	assert.Equal(t, "ver1", target.Verifier)
+	assert.Equal(t, []string{"site1", "site2"}, target.Sites)
+}
+
+func TestParseLine_IllegalFixedSizeComponentArrayRecord(t *testing.T) {
+	// Arrange
+	input := "T|1|a^b\\c^d"
+	target := IllegalFixedSizeComponentArrayRecord{}
+	// Act
+	_, err := ParseLine(input, &target, createStructAnnotation("T"), 1, config)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrAnnotationParsingIllegalComponentArray)
+}
+
+func TestParseLine_DefaultAttribute(t *testing.T) {
+	// Arrange
+	input := "T|1|1|2.5||||first^|"
+	target := OmitemptyDefaultRecord{}
+	// Act
+	_, err := ParseLine(input, &target, createStructAnnotation("T"), 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 1, target.Int)
+	assert.Equal(t, 2.5, target.Float)
+	assert.Equal(t, 1, target.IntDefault)
+	assert.Equal(t, "F", target.Status)
+	assert.Equal(t, "X", target.Component)
+	assert.Equal(t, []string{"A", "B"}, target.Array)
+	assert.Equal(t, 0, target.Plain)
+}
+
+func TestParseLine_DefaultAttributeFixedSizeComponentArray(t *testing.T) {
+	// Arrange
+	input := "T|1|"
+	target := struct {
+		Codes [2]string `astm:"3.1,default:X"`
+	}{}
+	// Act
+	_, err := ParseLine(input, &target, createStructAnnotation("T"), 1, config)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrAnnotationParsingIllegalComponentArray)
+}
+
+func TestParseDefaultValue_FixedSizeArray(t *testing.T) {
+	// Arrange
+	var target [2]string
+	annotation := models.AstmFieldAnnotation{
+		FieldPos:     3,
+		ComponentPos: 1,
+		IsComponent:  true,
+		IsArray:      true,
+		Attributes:   map[string]string{constants.AttributeDefault: "X"},
+	}
+	// Act
+	err := parseDefaultValue(reflect.ValueOf(&target).Elem(), annotation, 0, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, [2]string{"X", ""}, target)
+}
+
+func TestParseLine_DefaultAttributeNotUsedForValues(t *testing.T) {
+	// Arrange
+	input := "T|1||||5|C|^Y|Z"
+	target := OmitemptyDefaultRecord{}
+	// Act
+	_, err := ParseLine(input, &target, createStructAnnotation("T"), 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 5, target.IntDefault)
+	assert.Equal(t, "C", target.Status)
+	assert.Equal(t, "Y", target.Component)
+	assert.Equal(t, []string{"Z"}, target.Array)
+}
+
+func TestParseLine_StringConstraintAttributes(t *testing.T) {
+	// Arrange
+	input := "T|1|  TEST-27-026-5-1  57  | neg |TOOLONGVALUE|abc"
+	target := StringConstraintRecord{}
+	// Act
+	_, err := ParseLine(input, &target, createStructAnnotation("T"), 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "TEST-27-026-5-1  57", target.Trimmed)
+	assert.Equal(t, "NEG", target.Upper)
+	assert.Equal(t, "TOOLONGVALUE", target.Limited)
+	assert.Equal(t, "abc", target.ASCII)
+}
+func TestParseLine_CharsetViolation(t *testing.T) {
+	// Arrange
+	input := "T|1||||Müller"
+	target := StringConstraintRecord{}
+	// Act
+	_, err := ParseLine(input, &target, createStructAnnotation("T"), 1, config)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLineParsingInvalidCharset)
+	assert.NotContains(t, err.Error(), "Müller")
+}
+func TestParseLine_UnsupportedCharset(t *testing.T) {
+	// Arrange
+	input := "T|1|value"
+	target := UnsupportedCharsetRecord{}
+	// Act
+	_, err := ParseLine(input, &target, createStructAnnotation("T"), 1, config)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLineParsingUnsupportedCharset)
+}
+
+func TestFilterEscapeChars_NoEscapeDelimiter(t *testing.T) {
+	// Act
+	result := filterStringEscapeChars("first&|second", "")
+	// Assert
+	assert.Equal(t, "first&|second", result)
+}
@@ -0,0 +1,137 @@
+package functions
+
+import (
+	"github.com/krendel52/go-astm/v3/models"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"reflect"
+	"strings"
+)
+
+// Find the field storing the original line of a record (any field of astmmodels.RawRecord type)
+func findRawRecordField(structValue reflect.Value) (field reflect.Value, found bool) {
+	rawRecordType := reflect.TypeOf(astmmodels.RawRecord{})
+	for i := 0; i < structValue.NumField(); i++ {
+		if structValue.Type().Field(i).Type == rawRecordType {
+			return structValue.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// Store the original line in the record (only in preservation mode)
+func setRawRecord(targetStruct interface{}, inputLine string, config *astmmodels.Configuration) {
+	if !config.PreserveRawRecords {
+		return
+	}
+	structValue := reflect.Indirect(reflect.ValueOf(targetStruct))
+	if structValue.Kind() != reflect.Struct {
+		return
+	}
+	if field, found := findRawRecordField(structValue); found && field.CanSet() {
+		field.Set(reflect.ValueOf(astmmodels.RawRecord{Line: inputLine}))
+	}
+}
+
+// Get the original line of the record (only in preservation mode)
+func getRawRecord(sourceStruct interface{}, config *astmmodels.Configuration) (rawLine string, found bool) {
+	if !config.PreserveRawRecords {
+		return "", false
+	}
+	structValue := reflect.Indirect(reflect.ValueOf(sourceStruct))
+	if structValue.Kind() != reflect.Struct {
+		return "", false
+	}
+	field, found := findRawRecordField(structValue)
+	if !found {
+		return "", false
+	}
+	rawLine = field.Interface().(astmmodels.RawRecord).Line
+	return rawLine, rawLine != ""
+}
+
+// Merge the built line into the original one: the fields that did not change keep their original text
+// (e.g. padding, date and float formats, trailing empty fields), only the changed ones are re-rendered
+// (without more trailing empty components than the original field)
+func preserveRawLine(sourceStruct interface{}, lineTypeName string, sequenceNumber int, builtLine string, rawLine string, config *astmmodels.Configuration) string {
+	// Parse the original line into a new record to know how it is built without any changes
+	// Note: copies of the configuration are used, as header lines override the delimiters
+	parseConfig := *config
+	parseConfig.EnforceSequenceNumberCheck = false
+	parseConfig.PreserveRawRecords = false
+	original := reflect.New(reflect.Indirect(reflect.ValueOf(sourceStruct)).Type())
+	nameOk, err := ParseLine(rawLine, original.Interface(), models.AstmStructAnnotation{StructName: lineTypeName}, sequenceNumber, &parseConfig)
+	if err != nil || !nameOk {
+		// The original line does not belong to this record anymore
+		return builtLine
+	}
+	buildConfig := *config
+	buildConfig.PreserveRawRecords = false
+	originalLine, err := BuildLine(original.Interface(), lineTypeName, sequenceNumber, &buildConfig)
+	if err != nil {
+		return builtLine
+	}
+
+	// Compare the built fields with the original ones
+	rawFields := splitLineFields(rawLine, config)
+	builtFields := splitLineFields(builtLine, config)
+	originalFields := splitLineFields(originalLine, config)
+	merged := make([]string, max(len(rawFields), len(builtFields)))
+	for i := range merged {
+		if fieldAt(builtFields, i) == fieldAt(originalFields, i) {
+			merged[i] = fieldAt(rawFields, i)
+		} else if lineTypeName == "H" && i == 1 {
+			// The header delimiters are not split into components
+			merged[i] = fieldAt(builtFields, i)
+		} else {
+			merged[i] = trimComponents(fieldAt(builtFields, i), fieldAt(rawFields, i), config)
+		}
+	}
+	// Fields beyond the original line are only kept if they have a value
+	for len(merged) > len(rawFields) && merged[len(merged)-1] == "" {
+		merged = merged[:len(merged)-1]
+	}
+	return strings.Join(merged, config.Delimiters.Field)
+}
+
+// Drop the trailing empty components of a re-rendered field beyond the component count of the original field
+// (e.g. a changed value 7.5 stays 7.5 instead of 7.5^^ in standard notation)
+func trimComponents(builtField string, rawField string, config *astmmodels.Configuration) string {
+	rawRepeats := splitGenericValues(rawField, config.Delimiters.Repeat, config)
+	builtRepeats := splitGenericValues(builtField, config.Delimiters.Repeat, config)
+	for i, repeat := range builtRepeats {
+		components := splitGenericValues(repeat, config.Delimiters.Component, config)
+		rawCount := len(splitGenericValues(fieldAt(rawRepeats, i), config.Delimiters.Component, config))
+		for len(components) > rawCount && components[len(components)-1] == "" {
+			components = components[:len(components)-1]
+		}
+		builtRepeats[i] = strings.Join(components, config.Delimiters.Component)
+	}
+	return strings.Join(builtRepeats, config.Delimiters.Repeat)
+}
+
+// Split a line into its fields, keeping the trailing empty fields (the header delimiters are a single field)
+func splitLineFields(line string, config *astmmodels.Configuration) (fields []string) {
+	if len(line) >= 5 && line[0] == 'H' {
+		fields = []string{line[0:1], line[2:5]}
+		if len(line) > 5 {
+			fields = append(fields, splitFields(line[6:], config)...)
+		}
+		return fields
+	}
+	return splitFields(line, config)
+}
+func splitFields(input string, config *astmmodels.Configuration) (fields []string) {
+	fields = splitStringWithEscape(input, config.Delimiters.Field, config.Delimiters.Escape)
+	// The splitting drops the last field if it is empty
+	if strings.Join(fields, config.Delimiters.Field) != input {
+		fields = append(fields, "")
+	}
+	return fields
+}
+
+func fieldAt(fields []string, index int) string {
+	if index < len(fields) {
+		return fields[index]
+	}
+	return ""
+}
@@ -0,0 +1,107 @@
+package functions
+
+import (
+	"testing"
+
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLine_CapturesRawRecord(t *testing.T) {
+	// Arrange
+	input := "T|1| padded |220311103217|1.5||"
+	target := PreservedRecord{}
+	config.PreserveRawRecords = true
+	// Act
+	_, err := ParseLine(input, &target, createStructAnnotation("T"), 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "padded", target.Padded)
+	assert.Equal(t, input, target.Raw.Line)
+	// Teardown
+	teardown()
+}
+
+func TestParseLine_RawRecordNotCapturedByDefault(t *testing.T) {
+	// Arrange
+	input := "T|1|value"
+	target := PreservedRecord{}
+	// Act
+	_, err := ParseLine(input, &target, createStructAnnotation("T"), 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "", target.Raw.Line)
+}
+
+func TestBuildLine_PreservesUnchangedFields(t *testing.T) {
+	// Arrange
+	input := "T|1| padded |220311103217|1.5||"
+	source := PreservedRecord{}
+	config.PreserveRawRecords = true
+	_, err := ParseLine(input, &source, createStructAnnotation("T"), 1, config)
+	// Act
+	unchanged, errUnchanged := BuildLine(source, "T", 1, config)
+	source.Float = 2.25
+	changed, errChanged := BuildLine(source, "T", 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, errUnchanged)
+	assert.Nil(t, errChanged)
+	assert.Equal(t, input, unchanged)
+	assert.Equal(t, "T|1| padded |220311103217|2.250||", changed)
+	// Teardown
+	teardown()
+}
+
+func TestBuildLine_PreservationAddsFieldsBeyondTheOriginal(t *testing.T) {
+	// Arrange
+	source := PreservedRecord{
+		Padded: "value",
+		Float:  1,
+		Raw:    astmmodels.RawRecord{Line: "T|1|value"},
+	}
+	config.PreserveRawRecords = true
+	// Act
+	result, err := BuildLine(source, "T", 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "T|1|value||1.000", result)
+	// Teardown
+	teardown()
+}
+
+func TestBuildLine_PreservationIgnoresForeignRawLine(t *testing.T) {
+	// Arrange
+	source := PreservedRecord{
+		Padded: "value",
+		Raw:    astmmodels.RawRecord{Line: "X|1|other"},
+	}
+	config.PreserveRawRecords = true
+	// Act
+	result, err := BuildLine(source, "T", 1, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "T|1|value||0.000", result)
+	// Teardown
+	teardown()
+}
+
+func TestSplitLineFields(t *testing.T) {
+	// Act
+	header := splitLineFields("H|\\^&|||Sender|", config)
+	record := splitLineFields("R|1|a&|b||", config)
+	// Assert
+	assert.Equal(t, []string{"H", "\\^&", "", "", "Sender", ""}, header)
+	assert.Equal(t, []string{"R", "1", "a&|b", "", ""}, record)
+}
+
+func TestTrimComponents(t *testing.T) {
+	// Act
+	value := trimComponents("7.5^^", "7.410", config)
+	repeats := trimComponents("a^^\\b^^\\c^^", "x^y\\z", config)
+	kept := trimComponents("a^^", "x^^", config)
+	// Assert
+	assert.Equal(t, "7.5", value)
+	assert.Equal(t, "a^\\b\\c", repeats)
+	assert.Equal(t, "a^^", kept)
+}
@@ -54,8 +54,13 @@ func SliceLines(input string, config *astmmodels.Configuration) (output []string
 	}
 
 	for i := range lines {
-		lines[i] = strings.Trim(lines[i], " ")
-		if lines[i] != "" {
+		// In preservation mode the trailing spaces are kept for the original lines
+		if config.PreserveRawRecords {
+			lines[i] = strings.TrimLeft(lines[i], " ")
+		} else {
+			lines[i] = strings.Trim(lines[i], " ")
+		}
+		if strings.Trim(lines[i], " ") != "" {
 			output = append(output, lines[i])
 		}
 	}
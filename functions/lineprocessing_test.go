@@ -152,3 +152,15 @@ func TestBuildLines_ExplicitLFCR(t *testing.T) {
 	// Teardown
 	teardown()
 }
+func TestSliceLines_PreserveTrailingSpaces(t *testing.T) {
+	// Arrange
+	input := "  first  \n   \nsecond "
+	config.PreserveRawRecords = true
+	// Act
+	lines, err := SliceLines(input, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"first  ", "second "}, lines)
+	// Teardown
+	teardown()
+}
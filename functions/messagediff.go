@@ -0,0 +1,181 @@
+package functions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+)
+
+// Alignment key of a record: the record type, its sequence number and its occurrence (for the repeated sequence numbers of nested records)
+type diffRecordKey struct {
+	recordType string
+	sequence   string
+	occurrence int
+}
+
+// Position ignored by the diff, field 0 ignores the whole record type, component 0 the whole field
+type diffIgnoredPosition struct {
+	recordType string
+	field      int
+	component  int
+}
+
+// DiffRecords compares the records of two messages field by field and component by component
+// The records are aligned by their type and sequence number, the ignored positions are "R" (whole record type), "R-13" (field) or "R-4.1" (component)
+func DiffRecords(recordsA []astmmodels.GenericRecord, recordsB []astmmodels.GenericRecord, ignoredPositions []string) (report astmmodels.DiffReport, err error) {
+	ignored, err := parseDiffIgnoredPositions(ignoredPositions)
+	if err != nil {
+		return astmmodels.DiffReport{}, err
+	}
+	keysA, keysB := diffRecordKeys(recordsA), diffRecordKeys(recordsB)
+	indexesB := make(map[diffRecordKey]int, len(keysB))
+	for i, key := range keysB {
+		indexesB[key] = i
+	}
+	matchedB := make([]bool, len(recordsB))
+	// Compare the records of the first message with their counterpart, or report them as removed
+	for i, recordA := range recordsA {
+		if isDiffIgnored(ignored, recordA.Type, 0, 0) {
+			continue
+		}
+		j, exists := indexesB[keysA[i]]
+		if !exists {
+			report.Differences = append(report.Differences, astmmodels.Difference{Record: keysA[i].label(), Old: diffRecordText(recordA)})
+			continue
+		}
+		matchedB[j] = true
+		report.Differences = append(report.Differences, diffFields(keysA[i].label(), recordA, recordsB[j], ignored)...)
+	}
+	// The records of the second message without counterpart were added
+	for j, recordB := range recordsB {
+		if !matchedB[j] && !isDiffIgnored(ignored, recordB.Type, 0, 0) {
+			report.Differences = append(report.Differences, astmmodels.Difference{Record: keysB[j].label(), New: diffRecordText(recordB)})
+		}
+	}
+	// Return the report and no error if everything went well
+	return report, nil
+}
+
+func diffRecordKeys(records []astmmodels.GenericRecord) []diffRecordKey {
+	keys := make([]diffRecordKey, len(records))
+	occurrences := make(map[diffRecordKey]int)
+	for i, record := range records {
+		key := diffRecordKey{recordType: record.Type}
+		// The second field of the header is the delimiters
+		if record.Type != "H" {
+			key.sequence = record.Value(2, 1, 1)
+		}
+		occurrences[key]++
+		key.occurrence = occurrences[key]
+		keys[i] = key
+	}
+	return keys
+}
+
+func (k diffRecordKey) label() string {
+	label := k.recordType
+	if k.sequence != "" {
+		label += "|" + k.sequence
+	}
+	if k.occurrence > 1 {
+		label += "#" + strconv.Itoa(k.occurrence)
+	}
+	return label
+}
+
+// Compare the fields of two records from the third one (the record type and the sequence number or the delimiters are not compared)
+func diffFields(label string, recordA astmmodels.GenericRecord, recordB astmmodels.GenericRecord, ignored []diffIgnoredPosition) (differences []astmmodels.Difference) {
+	for fieldPos := 3; fieldPos <= max(len(recordA.Fields), len(recordB.Fields)); fieldPos++ {
+		fieldA, fieldB := diffField(recordA, fieldPos), diffField(recordB, fieldPos)
+		repeatCount := max(len(fieldA), len(fieldB))
+		for repeatPos := 1; repeatPos <= repeatCount; repeatPos++ {
+			componentCount := max(diffRepeatLength(fieldA, repeatPos), diffRepeatLength(fieldB, repeatPos))
+			for componentPos := 1; componentPos <= componentCount; componentPos++ {
+				if isDiffIgnored(ignored, recordA.Type, fieldPos, componentPos) {
+					continue
+				}
+				valueA := recordA.Value(fieldPos, repeatPos, componentPos)
+				valueB := recordB.Value(fieldPos, repeatPos, componentPos)
+				if valueA == valueB {
+					continue
+				}
+				// The repeat is only included if the field has more than one
+				position := fmt.Sprintf("%d.%d", fieldPos, componentPos)
+				if repeatCount > 1 {
+					position = fmt.Sprintf("%d[%d].%d", fieldPos, repeatPos, componentPos)
+				}
+				differences = append(differences, astmmodels.Difference{Record: label, Field: position, Old: valueA, New: valueB})
+			}
+		}
+	}
+	return differences
+}
+
+func diffField(record astmmodels.GenericRecord, fieldPos int) astmmodels.GenericField {
+	if fieldPos > len(record.Fields) {
+		return nil
+	}
+	return record.Fields[fieldPos-1]
+}
+
+func diffRepeatLength(field astmmodels.GenericField, repeatPos int) int {
+	if repeatPos > len(field) {
+		return 0
+	}
+	return len(field[repeatPos-1])
+}
+
+// Text of a whole record for the added and removed records (the values are joined with the default delimiters, without escaping)
+func diffRecordText(record astmmodels.GenericRecord) string {
+	fields := make([]string, len(record.Fields))
+	for i, field := range record.Fields {
+		repeats := make([]string, len(field))
+		for j, repeat := range field {
+			repeats[j] = strings.Join(repeat, astmmodels.DefaultDelimiters.Component)
+		}
+		fields[i] = strings.Join(repeats, astmmodels.DefaultDelimiters.Repeat)
+	}
+	return strings.Join(fields, astmmodels.DefaultDelimiters.Field)
+}
+
+func parseDiffIgnoredPositions(positions []string) (ignored []diffIgnoredPosition, err error) {
+	for _, position := range positions {
+		recordType, fieldPosition, hasField := strings.Cut(strings.TrimSpace(position), "-")
+		if recordType == "" {
+			return nil, fmt.Errorf("%w: %q", errmsg.ErrDiffInvalidIgnoredPosition, position)
+		}
+		ignoredPosition := diffIgnoredPosition{recordType: recordType}
+		if hasField {
+			fieldPart, componentPart, hasComponent := strings.Cut(fieldPosition, ".")
+			ignoredPosition.field, err = strconv.Atoi(fieldPart)
+			if err != nil || ignoredPosition.field < 1 {
+				return nil, fmt.Errorf("%w: %q", errmsg.ErrDiffInvalidIgnoredPosition, position)
+			}
+			if hasComponent {
+				ignoredPosition.component, err = strconv.Atoi(componentPart)
+				if err != nil || ignoredPosition.component < 1 {
+					return nil, fmt.Errorf("%w: %q", errmsg.ErrDiffInvalidIgnoredPosition, position)
+				}
+			}
+		}
+		ignored = append(ignored, ignoredPosition)
+	}
+	return ignored, nil
+}
+
+// Check if a position is ignored (field 0 checks the whole record type)
+func isDiffIgnored(ignored []diffIgnoredPosition, recordType string, fieldPos int, componentPos int) bool {
+	for _, position := range ignored {
+		if position.recordType != recordType {
+			continue
+		}
+		if position.field == 0 ||
+			(position.field == fieldPos && (position.component == 0 || position.component == componentPos)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package functions
+
+import (
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func parseDiffRecords(t *testing.T, lines ...string) (records []astmmodels.GenericRecord) {
+	for _, line := range lines {
+		record, err := ParseGenericLine(line, config)
+		assert.Nil(t, err)
+		records = append(records, record)
+	}
+	teardown()
+	return records
+}
+
+func TestDiffRecords_Equal(t *testing.T) {
+	// Arrange
+	recordsA := parseDiffRecords(t, "H|\\^&|||Sender", "R|1|^^^TSH|2.35|mU/l||||||", "L|1|N")
+	// The trailing empty fields and components do not make a difference
+	recordsB := parseDiffRecords(t, "H|\\^&|||Sender", "R|1|^^^TSH|2.35^|mU/l", "L|1|N")
+	// Act
+	report, err := DiffRecords(recordsA, recordsB, nil)
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, report.IsEqual())
+}
+
+func TestDiffRecords_FieldsAndComponents(t *testing.T) {
+	// Arrange
+	recordsA := parseDiffRecords(t, "R|3|^^^TSH|7.41|mU/l|A\\B")
+	recordsB := parseDiffRecords(t, "R|3|^^^FT4|7.4|mU/l|A\\C")
+	// Act
+	report, err := DiffRecords(recordsA, recordsB, nil)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []astmmodels.Difference{
+		{Record: "R|3", Field: "3.4", Old: "TSH", New: "FT4"},
+		{Record: "R|3", Field: "4.1", Old: "7.41", New: "7.4"},
+		{Record: "R|3", Field: "6[2].1", Old: "B", New: "C"},
+	}, report.Differences)
+	assert.Equal(t, "R|3 field 4.1: 7.41 → 7.4", report.Differences[1].String())
+}
+
+func TestDiffRecords_AlignsBySequenceNumber(t *testing.T) {
+	// Arrange
+	recordsA := parseDiffRecords(t, "O|1|S1", "R|1|^^^TSH|1.0", "R|2|^^^FT4|2.0", "O|2|S2", "R|1|^^^TSH|3.0")
+	recordsB := parseDiffRecords(t, "O|1|S1", "R|2|^^^FT4|2.0", "O|2|S2", "R|1|^^^TSH|3.5", "R|2|^^^FT4|4.0")
+	// Act
+	report, err := DiffRecords(recordsA, recordsB, nil)
+	// Assert
+	assert.Nil(t, err)
+	// The repeated sequence numbers of the nested records are aligned by their occurrence
+	assert.Equal(t, []astmmodels.Difference{
+		{Record: "R|1", Field: "4.1", Old: "1.0", New: "3.5"},
+		{Record: "R|1#2", Old: "R|1|^^^TSH|3.0"},
+		{Record: "R|2#2", New: "R|2|^^^FT4|4.0"},
+	}, report.Differences)
+	assert.Equal(t, "R|1#2 removed: R|1|^^^TSH|3.0", report.Differences[1].String())
+	assert.Equal(t, "R|2#2 added: R|2|^^^FT4|4.0", report.Differences[2].String())
+}
+
+func TestDiffRecords_IgnoredPositions(t *testing.T) {
+	// Arrange
+	recordsA := parseDiffRecords(t, "H|\\^&|||Sender^1.0|||||||||20240101120000", "M|1|Firmware|1.0", "R|1|^^^TSH|7.41^7.40|mU/l")
+	recordsB := parseDiffRecords(t, "H|\\^&|||Sender^1.1|||||||||20240202120000", "R|1|^^^TSH|7.41^7.39|mU/L")
+	// Act
+	report, err := DiffRecords(recordsA, recordsB, []string{"H-14", "M", "R-4.2", "R-5"})
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []astmmodels.Difference{{Record: "H", Field: "5.2", Old: "1.0", New: "1.1"}}, report.Differences)
+}
+
+func TestDiffRecords_InvalidIgnoredPosition(t *testing.T) {
+	// Arrange
+	positions := []string{"", "-4", "R-", "R-x", "R-0", "R-4.0", "R-4.x"}
+	for _, position := range positions {
+		// Act
+		_, err := DiffRecords(nil, nil, []string{position})
+		// Assert
+		assert.ErrorIs(t, err, errmsg.ErrDiffInvalidIgnoredPosition, position)
+	}
+}
@@ -57,6 +57,10 @@ func BuildStruct(sourceStruct interface{}, sequenceNumber int, depth int, config
 				}
 				result = append(result, subResult...)
 			} else {
+				// In preservation mode the empty optional records are not added (they were not in the original message)
+				if _, exists := sourceStructAnnotation.Attributes[constants.AttributeOptional]; exists && config.PreserveRawRecords && sourceValues[i].IsZero() {
+					continue
+				}
 				// Only the first element is inheriting the sequence number
 				seqNum := 1
 				if i == 0 {
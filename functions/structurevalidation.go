@@ -0,0 +1,307 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"github.com/krendel52/go-astm/v3/constants"
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func ValidateStruct(sourceStruct interface{}, config *astmmodels.Configuration) (report astmmodels.ValidationReport, err error) {
+	tracker := newSequenceTracker()
+	report.Issues, err = validateStruct(sourceStruct, "", 1, 0, tracker, config)
+	if err != nil {
+		return astmmodels.ValidationReport{}, err
+	}
+	// Return the report and no error if everything went well
+	return report, nil
+}
+
+// Walks the structure in the same order as BuildStruct, validating every record
+func validateStruct(sourceStruct interface{}, path string, sequenceNumber int, depth int, tracker *sequenceTracker, config *astmmodels.Configuration) (issues []astmmodels.ValidationIssue, err error) {
+	// Check for maximum depth
+	if depth >= constants.MaxDepth {
+		return nil, errmsg.ErrStructureParsingMaxDepthReached
+	}
+
+	// Process the source structure
+	sourceTypes, sourceValues, _, err := ProcessStructReflection(sourceStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	// Iterate over the inputFields of the sourceStruct struct
+	for i, sourceType := range sourceTypes {
+		// Parse the sourceStruct field sourceFieldAnnotation
+		sourceStructAnnotation, err := ParseAstmStructAnnotation(sourceType)
+		if err != nil {
+			return nil, err
+		}
+		sourcePath := joinPath(path, sourceType.Name)
+
+		var subIssues []astmmodels.ValidationIssue
+		if sourceStructAnnotation.IsArray {
+			// Source is an array it is iterated
+			for j := 0; j < sourceValues[i].Len(); j++ {
+				elementPath := fmt.Sprintf("%s[%d]", sourcePath, j)
+				if sourceStructAnnotation.IsComposite {
+					subIssues, err = validateStruct(sourceValues[i].Index(j).Addr().Interface(), elementPath, j+1, depth+1, tracker, config)
+				} else {
+					subIssues, err = validateRecord(sourceValues[i].Index(j), sourceStructAnnotation.StructName, elementPath, j+1, tracker, config)
+				}
+				if err != nil {
+					return nil, err
+				}
+				issues = append(issues, subIssues...)
+			}
+			continue
+		}
+		// Source is a single element
+		if sourceStructAnnotation.IsComposite {
+			subIssues, err = validateStruct(sourceValues[i].Addr().Interface(), sourcePath, sequenceNumber, depth+1, tracker, config)
+		} else {
+			// Only the first element is inheriting the sequence number
+			seqNum := 1
+			if i == 0 {
+				seqNum = sequenceNumber
+			}
+			subIssues, err = validateRecord(sourceValues[i], sourceStructAnnotation.StructName, sourcePath, seqNum, tracker, config)
+		}
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, subIssues...)
+	}
+
+	// Return the issues and no error if everything went well
+	return issues, nil
+}
+
+// Validate a single record: its sequence number, its fields and whether it can be built
+func validateRecord(source reflect.Value, recordName string, path string, sequenceNumber int, tracker *sequenceTracker, config *astmmodels.Configuration) (issues []astmmodels.ValidationIssue, err error) {
+	// Check the sequence number against the record hierarchy
+	if expected, ok := tracker.next(recordName, sequenceNumber); !ok {
+		issues = append(issues, astmmodels.ValidationIssue{
+			Path:   path,
+			Record: recordName,
+			Err:    fmt.Errorf("%w: %d instead of %d", errmsg.ErrValidationSequenceNumberMismatch, sequenceNumber, expected),
+		})
+	}
+	// Check the fields
+	fieldIssues, err := validateFields(source, recordName, path, "", config)
+	if err != nil {
+		return nil, err
+	}
+	issues = append(issues, fieldIssues...)
+	// Any other problem (e.g. maxlen or charset) shows up when building the line
+	if _, err = BuildLine(source.Addr().Interface(), recordName, sequenceNumber, config); err != nil {
+		issues = append(issues, astmmodels.ValidationIssue{
+			Path:   path,
+			Record: recordName,
+			Err:    err,
+		})
+	}
+	return issues, nil
+}
+
+// Validate the annotated fields of a record or a substructure
+func validateFields(source reflect.Value, recordName string, path string, position string, config *astmmodels.Configuration) (issues []astmmodels.ValidationIssue, err error) {
+	sourceTypes, sourceValues, _, err := ProcessStructReflection(source.Addr().Interface())
+	if err != nil {
+		return nil, err
+	}
+	for i, sourceType := range sourceTypes {
+		annotation, err := ParseAstmFieldAnnotation(sourceType)
+		if err != nil {
+			if errors.Is(err, errmsg.ErrAnnotationParsingMissingAstmAnnotation) {
+				// If the annotation is missing, skip this field
+				continue
+			}
+			return nil, err
+		}
+		fieldPath := joinPath(path, sourceType.Name)
+		fieldPosition := strconv.Itoa(annotation.FieldPos)
+		if annotation.IsComponent {
+			fieldPosition += "." + strconv.Itoa(annotation.ComponentPos)
+		}
+		if position != "" {
+			fieldPosition = position + "." + fieldPosition
+		}
+		// Required fields have to produce a value
+		if _, exists := annotation.Attributes[constants.AttributeRequired]; exists && isEmptyValue(sourceValues[i], annotation, config) {
+			issues = append(issues, astmmodels.ValidationIssue{
+				Path:   fieldPath,
+				Record: recordName,
+				Field:  fieldPosition,
+				Err:    errmsg.ErrValidationRequiredFieldMissing,
+			})
+		}
+		valueIssues, err := validateValue(sourceValues[i], recordName, fieldPath, fieldPosition, config)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, valueIssues...)
+	}
+	return issues, nil
+}
+
+// Validate a field value recursively (array elements and substructure fields included)
+func validateValue(value reflect.Value, recordName string, path string, position string, config *astmmodels.Configuration) (issues []astmmodels.ValidationIssue, err error) {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil, nil
+		}
+		value = value.Elem()
+	}
+	newIssue := func(err error) astmmodels.ValidationIssue {
+		return astmmodels.ValidationIssue{Path: path, Record: recordName, Field: position, Err: err}
+	}
+	switch {
+	case isNestedArrayType(value.Type()):
+		for j := 0; j < value.Len(); j++ {
+			elementIssues, err := validateValue(value.Index(j), recordName, fmt.Sprintf("%s[%d]", path, j), position, config)
+			if err != nil {
+				return nil, err
+			}
+			issues = append(issues, elementIssues...)
+		}
+	case isNestedSubstructureType(value.Type()):
+		return validateFields(value, recordName, path, position, config)
+	case value.Type() == reflect.TypeOf(time.Time{}):
+		date := value.Interface().(time.Time)
+		if !date.IsZero() && (date.Year() < constants.ValidationMinYear || date.Year() > constants.ValidationMaxYear) {
+			issues = append(issues, newIssue(fmt.Errorf("%w: %s", errmsg.ErrValidationDateOutOfRange, date.Format(time.RFC3339))))
+		}
+	case value.Kind() == reflect.String:
+		if value.String() == "" {
+			return nil, nil
+		}
+		if enum, ok := value.Interface().(astmmodels.ValidatableEnum); ok && !enum.IsValid() {
+			issues = append(issues, newIssue(fmt.Errorf("%w: %q", errmsg.ErrValidationInvalidEnumValue, value.String())))
+		}
+		if !config.EscapeOutputStrings && containsDelimiter(value.String(), config) {
+			issues = append(issues, newIssue(fmt.Errorf("%w: %q", errmsg.ErrValidationUnescapedDelimiter, value.String())))
+		}
+	}
+	return issues, nil
+}
+
+// Check whether the value would be marshalled as an empty field
+func isEmptyValue(value reflect.Value, annotation models.AstmFieldAnnotation, config *astmmodels.Configuration) bool {
+	if _, exists := annotation.Attributes[constants.AttributeDefault]; exists {
+		return false
+	}
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return true
+		}
+		value = value.Elem()
+	}
+	switch {
+	case isNestedArrayType(value.Type()):
+		for j := 0; j < value.Len(); j++ {
+			if !isEmptyValue(value.Index(j), annotation, config) {
+				return false
+			}
+		}
+		return true
+	case isNestedSubstructureType(value.Type()):
+		sourceTypes, sourceValues, _, err := ProcessStructReflection(value.Addr().Interface())
+		if err != nil {
+			return false
+		}
+		for i, sourceType := range sourceTypes {
+			fieldAnnotation, err := ParseAstmFieldAnnotation(sourceType)
+			if err != nil {
+				continue
+			}
+			if !isEmptyValue(sourceValues[i], fieldAnnotation, config) {
+				return false
+			}
+		}
+		return true
+	default:
+		result, err := convertField(value, annotation, config)
+		return err == nil && result == ""
+	}
+}
+
+// Check whether the value contains any of the configured delimiters
+func containsDelimiter(value string, config *astmmodels.Configuration) bool {
+	delimiters := []string{
+		config.Delimiters.Field,
+		config.Delimiters.Repeat,
+		config.Delimiters.Component,
+		config.Delimiters.Escape,
+		config.Delimiters.SubComponent,
+	}
+	for _, delimiter := range delimiters {
+		if delimiter != "" && strings.Contains(value, delimiter) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// Record hierarchy levels of LIS02-A2, the other records (e.g. C, M) belong to the record before them
+var recordLevels = map[string]int{
+	"H": 0,
+	"P": 1,
+	"Q": 1,
+	"O": 2,
+	"R": 3,
+	"L": 0,
+}
+
+// Tracks the expected sequence numbers: each record type counts from 1 under its parent record
+type sequenceTracker struct {
+	lastLevel int
+	counters  map[int]map[string]int
+}
+
+func newSequenceTracker() *sequenceTracker {
+	return &sequenceTracker{
+		counters: make(map[int]map[string]int),
+	}
+}
+
+// Register the next record and check its sequence number against the expected one
+func (t *sequenceTracker) next(recordName string, sequenceNumber int) (expected int, ok bool) {
+	// A header starts a new message, it has no sequence number
+	if recordName == "H" {
+		t.counters = make(map[int]map[string]int)
+		t.lastLevel = 0
+		return sequenceNumber, true
+	}
+	level, isHierarchical := recordLevels[recordName]
+	if isHierarchical {
+		t.lastLevel = level
+	} else {
+		level = t.lastLevel + 1
+	}
+	// The records below this level start new sequences
+	for counterLevel := range t.counters {
+		if counterLevel > level {
+			delete(t.counters, counterLevel)
+		}
+	}
+	if t.counters[level] == nil {
+		t.counters[level] = make(map[string]int)
+	}
+	t.counters[level][recordName]++
+	expected = t.counters[level][recordName]
+	return expected, expected == sequenceNumber
+}
@@ -0,0 +1,117 @@
+package functions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateStruct_Valid(t *testing.T) {
+	// Arrange
+	source := ValidatedMessage{
+		Records: []ValidatedRecord{
+			{ID: "1", Name: "Name", Status: "valid", Date: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+			{ID: "2", Name: "Other"},
+		},
+	}
+	// Act
+	report, err := ValidateStruct(&source, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, report.IsValid())
+	assert.Nil(t, report.Err())
+}
+
+func TestValidateStruct_CollectsAllIssues(t *testing.T) {
+	// Arrange
+	source := ValidatedMessage{
+		Records: []ValidatedRecord{
+			{ID: "1", Name: "Name", Status: "invalid", Date: time.Date(1850, 1, 2, 0, 0, 0, 0, time.UTC)},
+			{Comment: "first|second", Items: []SubstructureField{{FirstComponent: "a^b"}}},
+		},
+	}
+	// Act
+	report, err := ValidateStruct(&source, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.False(t, report.IsValid())
+	assert.Len(t, report.Issues, 6)
+	// Check the first record
+	assert.ErrorIs(t, report.Issues[0], errmsg.ErrValidationInvalidEnumValue)
+	assert.Equal(t, "Records[0].Status", report.Issues[0].Path)
+	assert.Equal(t, "R", report.Issues[0].Record)
+	assert.Equal(t, "5", report.Issues[0].Field)
+	assert.ErrorIs(t, report.Issues[1], errmsg.ErrValidationDateOutOfRange)
+	// Check the second record
+	assert.ErrorIs(t, report.Issues[2], errmsg.ErrValidationRequiredFieldMissing)
+	assert.Equal(t, "Records[1].ID", report.Issues[2].Path)
+	assert.ErrorIs(t, report.Issues[3], errmsg.ErrValidationRequiredFieldMissing)
+	assert.Equal(t, "4.1", report.Issues[3].Field)
+	assert.ErrorIs(t, report.Issues[4], errmsg.ErrValidationUnescapedDelimiter)
+	assert.Equal(t, "Records[1].Comment", report.Issues[4].Path)
+	assert.ErrorIs(t, report.Issues[5], errmsg.ErrValidationUnescapedDelimiter)
+	assert.Equal(t, "Records[1].Items[0].FirstComponent", report.Issues[5].Path)
+	assert.Equal(t, "8.1", report.Issues[5].Field)
+	assert.ErrorIs(t, report.Err(), errmsg.ErrValidationRequiredFieldMissing)
+}
+
+func TestValidateStruct_EscapedDelimitersAreValid(t *testing.T) {
+	// Arrange
+	source := ValidatedMessage{
+		Records: []ValidatedRecord{{ID: "1", Name: "Name", Comment: "first|second"}},
+	}
+	config.EscapeOutputStrings = true
+	// Act
+	report, err := ValidateStruct(&source, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, report.IsValid())
+	// Teardown
+	teardown()
+}
+
+func TestValidateStruct_BuildingErrors(t *testing.T) {
+	// Arrange
+	source := StringConstraintRecord{Limited: "TOOLONG"}
+	message := struct {
+		Record StringConstraintRecord `astm:"T"`
+	}{Record: source}
+	// Act
+	report, err := ValidateStruct(&message, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, report.Issues, 1)
+	assert.ErrorIs(t, report.Issues[0], errmsg.ErrLineBuildingMaxLengthExceeded)
+	assert.Equal(t, "Record", report.Issues[0].Path)
+}
+
+func TestValidateStruct_NonContiguousSequence(t *testing.T) {
+	// Arrange
+	source := SequenceGapMessage{
+		Groups: []SequenceGapGroup{
+			{Order: SimpleRecord{First: "first"}},
+			{Order: SimpleRecord{First: "second"}},
+		},
+	}
+	// Act
+	report, err := ValidateStruct(&source, config)
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, report.Issues, 1)
+	assert.ErrorIs(t, report.Issues[0], errmsg.ErrValidationSequenceNumberMismatch)
+	assert.Equal(t, "Groups[1].Order", report.Issues[0].Path)
+	assert.EqualError(t, report.Issues[0], "Groups[1].Order (O): non-contiguous sequence number: 1 instead of 2")
+}
+
+func TestValidateStruct_InvalidAnnotation(t *testing.T) {
+	// Arrange
+	source := struct {
+		Record InvalidFieldAttribute `astm:"T"`
+	}{}
+	// Act
+	_, err := ValidateStruct(&source, config)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrAnnotationParsingInvalidAstmAttribute)
+}
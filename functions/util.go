@@ -1,21 +1,33 @@
 package functions
 
 import (
+	"errors"
+	"fmt"
+	"github.com/krendel52/go-astm/v3/constants"
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models"
 	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"reflect"
 	"strings"
+	"time"
 )
 
 func LoadConfiguration(configuration ...astmmodels.Configuration) (config *astmmodels.Configuration, err error) {
 	if len(configuration) > 0 {
 		config = &configuration[0]
 	} else {
-		config = &astmmodels.DefaultConfiguration
+		// Use a copy, so the header delimiters do not override the defaults
+		defaultConfiguration := astmmodels.DefaultConfiguration
+		config = &defaultConfiguration
 	}
 	if config.Delimiters.Field == "" ||
 		config.Delimiters.Repeat == "" ||
 		config.Delimiters.Component == "" ||
 		config.Delimiters.Escape == "" {
+		// The optional subcomponent delimiter is kept
+		subComponent := config.Delimiters.SubComponent
 		config.Delimiters = astmmodels.DefaultDelimiters
+		config.Delimiters.SubComponent = subComponent
 	}
 	config.TimeLocation, err = config.TimeZone.GetLocation()
 	if err != nil {
@@ -42,3 +54,143 @@ func ExtractSignature(lines []string) string {
 	// Return final signature
 	return signature.String()
 }
+
+// Nesting levels inside a field: every array and substructure uses a delimiter not used by its parents
+// Arrays prefer the repeat delimiter, substructures prefer the component delimiter
+type nestingLevel uint8
+
+const (
+	nestingRepeat nestingLevel = 1 << iota
+	nestingComponent
+	nestingSubComponent
+)
+
+var (
+	arrayNestingOrder        = []nestingLevel{nestingRepeat, nestingComponent, nestingSubComponent}
+	substructureNestingOrder = []nestingLevel{nestingComponent, nestingSubComponent, nestingRepeat}
+)
+
+func nextNestingLevel(order []nestingLevel, used nestingLevel, config *astmmodels.Configuration) (level nestingLevel, delimiter string, ok bool) {
+	for _, level = range order {
+		if used&level != 0 {
+			continue
+		}
+		switch level {
+		case nestingRepeat:
+			delimiter = config.Delimiters.Repeat
+		case nestingComponent:
+			delimiter = config.Delimiters.Component
+		case nestingSubComponent:
+			delimiter = config.Delimiters.SubComponent
+		}
+		// The subcomponent delimiter is optional
+		if delimiter != "" {
+			return level, delimiter, true
+		}
+	}
+	return 0, "", false
+}
+
+func isNestedArrayType(valueType reflect.Type) bool {
+	return valueType.Kind() == reflect.Slice || valueType.Kind() == reflect.Array
+}
+func isNestedSubstructureType(valueType reflect.Type) bool {
+	return valueType.Kind() == reflect.Struct && valueType != reflect.TypeOf(time.Time{})
+}
+
+// Collect the positions of the fields that have component arrays (these fields are treated as repeated)
+func componentArrayFields(structFields []reflect.StructField) map[int]bool {
+	result := make(map[int]bool)
+	for _, structField := range structFields {
+		annotation, err := ParseAstmFieldAnnotation(structField)
+		if err == nil && annotation.IsComponent && annotation.IsArray {
+			result[annotation.FieldPos] = true
+		}
+	}
+	return result
+}
+
+// Apply the trim and upper attributes to a string value (used in both directions)
+func normalizeString(value string, annotation models.AstmFieldAnnotation) string {
+	if _, exists := annotation.Attributes[constants.AttributeTrim]; exists {
+		value = strings.TrimSpace(value)
+	}
+	if _, exists := annotation.Attributes[constants.AttributeUpper]; exists {
+		value = strings.ToUpper(value)
+	}
+	return value
+}
+
+// Get the position of a field for error messages (the value itself is never reported, it may contain patient data)
+func annotationPosition(annotation models.AstmFieldAnnotation) string {
+	if annotation.IsComponent {
+		return fmt.Sprintf("%d.%d", annotation.FieldPos, annotation.ComponentPos)
+	}
+	return fmt.Sprintf("%d", annotation.FieldPos)
+}
+
+// Allowed characters of the charsets usable in the charset attribute
+var charsets = map[string]func(r rune) bool{
+	"ascii":     func(r rune) bool { return r <= 0x7F },
+	"printable": func(r rune) bool { return r >= 0x20 && r <= 0x7E },
+}
+
+// Check the value against the charset attribute (if any)
+func checkCharset(value string, annotation models.AstmFieldAnnotation) (valid bool, supported bool) {
+	charset, exists := annotation.Attributes[constants.AttributeCharset]
+	if !exists {
+		return true, true
+	}
+	isAllowed, supported := charsets[charset]
+	if !supported {
+		return false, false
+	}
+	for _, r := range value {
+		if !isAllowed(r) {
+			return false, true
+		}
+	}
+	return true, true
+}
+
+// Find the field capturing the header delimiters: any field annotated with the delimiters attribute,
+// or (with legacy) an unannotated field named Delimiters (as in lis02a2.Header)
+func findDelimitersField(structValue reflect.Value, legacy bool) (field reflect.Value, found bool, err error) {
+	delimitersType := reflect.TypeOf(astmmodels.Delimiters{})
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		structField := structType.Field(i)
+		annotation, err := ParseAstmFieldAnnotation(structField)
+		if err != nil {
+			if legacy && errors.Is(err, errmsg.ErrAnnotationParsingMissingAstmAnnotation) && structField.Name == "Delimiters" && structField.Type == delimitersType {
+				return structValue.Field(i), true, nil
+			}
+			continue
+		}
+		if _, exists := annotation.Attributes[constants.AttributeDelimiters]; exists {
+			if structField.Type != delimitersType {
+				return reflect.Value{}, false, fmt.Errorf("invalid delimiters field type: expected %v, got %v", delimitersType, structField.Type)
+			}
+			return structValue.Field(i), true, nil
+		}
+	}
+	return reflect.Value{}, false, nil
+}
+
+// Get the delimiters captured in a header structure (only if all the mandatory delimiters are set)
+// Only a field with the delimiters attribute is used, the legacy Delimiters field is filled by unmarshal but never overrides the configuration
+func capturedDelimiters(sourceStruct interface{}) (delimiters astmmodels.Delimiters, found bool) {
+	structValue := reflect.Indirect(reflect.ValueOf(sourceStruct))
+	if structValue.Kind() != reflect.Struct {
+		return astmmodels.Delimiters{}, false
+	}
+	field, found, err := findDelimitersField(structValue, false)
+	if err != nil || !found {
+		return astmmodels.Delimiters{}, false
+	}
+	delimiters = field.Interface().(astmmodels.Delimiters)
+	if delimiters.Field == "" || delimiters.Repeat == "" || delimiters.Component == "" || delimiters.Escape == "" {
+		return astmmodels.Delimiters{}, false
+	}
+	return delimiters, true
+}
@@ -0,0 +1,69 @@
+package astm
+
+import (
+	"fmt"
+	"github.com/blutspende/bloodlab-common/encoding"
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/functions"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+)
+
+// UnmarshalGeneric splits a message into records of the schema-less field, repeat and component layout (no structure is needed)
+func UnmarshalGeneric(messageData []byte, configuration ...astmmodels.Configuration) (records []astmmodels.GenericRecord, err error) {
+	// Last resort only: malformed input is rejected with the errors of the parsing, a panic would be a bug
+	// of the library (see the fuzz tests), but it is still returned as an error instead of crashing the caller
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%w: %v", errmsg.ErrUnmarshalUnexpectedFailure, recovered)
+		}
+	}()
+	// Load configuration
+	config, err := functions.LoadConfiguration(configuration...)
+	if err != nil {
+		return nil, err
+	}
+	// Convert encoding to UTF8
+	utf8Data, err := encoding.ConvertFromEncodingToUtf8(messageData, config.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	// Split the message data into lines
+	lines, err := functions.SliceLines(utf8Data, config)
+	if err != nil {
+		return nil, err
+	}
+	// Parse every line on its own
+	for _, line := range lines {
+		record, err := functions.ParseGenericLine(line, config)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	// Return the records and no error if everything went well
+	return records, nil
+}
+
+// MarshalGeneric builds the lines of schema-less records, the counterpart of UnmarshalGeneric
+func MarshalGeneric(records []astmmodels.GenericRecord, configuration ...astmmodels.Configuration) (result [][]byte, err error) {
+	// Load configuration
+	config, err := functions.LoadConfiguration(configuration...)
+	if err != nil {
+		return nil, err
+	}
+	// Build the lines of the records
+	lines := make([]string, len(records))
+	for i, record := range records {
+		lines[i], err = functions.BuildGenericLine(record, config)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Convert UTF8 string array to encoding
+	result, err = encoding.ConvertArrayFromUtf8ToEncoding(lines, config.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	// Return the result and no error if everything went well
+	return result, nil
+}
@@ -0,0 +1,38 @@
+package astm
+
+import (
+	"github.com/krendel52/go-astm/v3/functions"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+)
+
+// ToJSON converts an annotated structure (e.g. filled by Unmarshal) to JSON keyed as set by the JSONKeys configuration
+func ToJSON(sourceStruct interface{}, configuration ...astmmodels.Configuration) (result []byte, err error) {
+	// Load configuration
+	config, err := functions.LoadConfiguration(configuration...)
+	if err != nil {
+		return nil, err
+	}
+	// Convert the source structure
+	result, err = functions.StructToJSON(sourceStruct, config)
+	if err != nil {
+		return nil, err
+	}
+	// Return the result and no error if everything went well
+	return result, nil
+}
+
+// FromJSON fills an annotated structure from JSON produced by ToJSON, so it can be passed to Marshal
+func FromJSON(jsonData []byte, targetStruct interface{}, configuration ...astmmodels.Configuration) (err error) {
+	// Load configuration
+	config, err := functions.LoadConfiguration(configuration...)
+	if err != nil {
+		return err
+	}
+	// Fill the target structure
+	err = functions.JSONToStruct(jsonData, targetStruct, config)
+	if err != nil {
+		return err
+	}
+	// Return nil if everything went well
+	return nil
+}
@@ -0,0 +1,320 @@
+package lis1a
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/krendel52/go-astm/v3/enums/lis1arole"
+	"github.com/krendel52/go-astm/v3/errmsg"
+)
+
+// Maximum number of characters read for a frame before giving up on finding its terminator
+const maxFrameLength = 64 * 1024
+
+// Timers and retry limits of the protocol
+type Timing struct {
+	ReplyTimeout    time.Duration // waiting for the reply to ENQ or a frame
+	ReceiveTimeout  time.Duration // waiting for the next frame or EOT
+	IdleTimeout     time.Duration // waiting for the ENQ of the sender in Receive (0 waits forever)
+	BusyDelay       time.Duration // waiting after a NAK to the ENQ before sending it again
+	ContentionDelay time.Duration // waiting after a line contention before sending the ENQ again
+	MaxAttempts     int           // attempts to send the ENQ or a frame
+}
+
+// Timing defined by the standard for the role (the instrument waits 1 s after a contention, the computer system 20 s)
+func DefaultTiming(role string) Timing {
+	timing := Timing{
+		ReplyTimeout:    15 * time.Second,
+		ReceiveTimeout:  30 * time.Second,
+		BusyDelay:       10 * time.Second,
+		ContentionDelay: time.Second,
+		MaxAttempts:     6,
+	}
+	if role == lis1arole.Computer {
+		timing.ContentionDelay = 20 * time.Second
+	}
+	return timing
+}
+
+// Faults injected into the transfers, e.g. by the instrument simulator to test the error handling of the peer
+// The counters are decreased as the faults are injected, the zero value injects no faults
+type Faults struct {
+	BadChecksums int           // frames sent with a wrong checksum (the peer has to answer them with NAK)
+	NAKs         int           // valid frames answered with NAK (the peer has to send them again)
+	Timeouts     int           // frames left unanswered (the peer has to time out and abort the transfer)
+	DroppedEOTs  int           // transfers not terminated with EOT (the peer has to time out)
+	Contentions  int           // ENQs answered with ENQ instead of ACK (Receive returns ErrLIS1AContention)
+	ReplyDelay   time.Duration // delay before answering ENQs and frames
+}
+
+// Connection sending and receiving messages as lists of records
+// Send and Receive must not be called concurrently, the protocol is half-duplex
+type Conn struct {
+	Role   string // lis1arole, decides who yields in a line contention
+	Timing Timing
+	Faults Faults
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func NewConn(conn net.Conn, role string) *Conn {
+	return &Conn{
+		Role:   role,
+		Timing: DefaultTiming(role),
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+	}
+}
+
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// Send the records of a message: establishment (ENQ), transfer (frames) and termination (EOT)
+// In a line contention the computer system yields with ErrLIS1AContention and has to receive the message of the instrument first
+func (c *Conn) Send(records [][]byte) error {
+	frames, err := BuildFrames(records)
+	if err != nil {
+		return err
+	}
+	// Establishment
+	if err = c.establish(); err != nil {
+		return err
+	}
+	// Transfer
+	for _, frame := range frames {
+		if err = c.sendFrame(frame); err != nil {
+			// Termination after a failed transfer
+			_ = c.write(EOT)
+			return err
+		}
+	}
+	// Termination
+	if c.Faults.DroppedEOTs > 0 {
+		c.Faults.DroppedEOTs--
+		return nil
+	}
+	return c.write(EOT)
+}
+
+func (c *Conn) establish() error {
+	for attempt := 1; ; attempt++ {
+		if err := c.write(ENQ); err != nil {
+			return err
+		}
+		reply, err := c.readReply()
+		if err != nil {
+			_ = c.write(EOT)
+			return err
+		}
+		switch reply {
+		case ACK:
+			return nil
+		case NAK:
+			// The receiver is busy
+			if attempt >= c.Timing.MaxAttempts {
+				return errmsg.ErrLIS1ABusy
+			}
+			time.Sleep(c.Timing.BusyDelay)
+		case ENQ:
+			// Both sides want to send, the instrument has priority
+			if c.Role != lis1arole.Instrument || attempt >= c.Timing.MaxAttempts {
+				return errmsg.ErrLIS1AContention
+			}
+			time.Sleep(c.Timing.ContentionDelay)
+		default:
+			return fmt.Errorf("%w: %q", errmsg.ErrLIS1AUnexpectedCharacter, reply)
+		}
+	}
+}
+
+func (c *Conn) sendFrame(frame Frame) error {
+	for attempt := 1; attempt <= c.Timing.MaxAttempts; attempt++ {
+		data := frame.Bytes()
+		if c.Faults.BadChecksums > 0 {
+			c.Faults.BadChecksums--
+			// Change the last checksum digit, the value stays a valid hex digit
+			data[len(data)-3] ^= 0x01
+		}
+		if err := c.write(data...); err != nil {
+			return err
+		}
+		reply, err := c.readReply()
+		if err != nil {
+			return err
+		}
+		// EOT is a request of the receiver to stop after this transfer, the frame is accepted
+		if reply == ACK || reply == EOT {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: frame %d", errmsg.ErrLIS1ARetransmissionLimit, frame.Number)
+}
+
+// Wait for ACK, NAK, ENQ or EOT, other characters are ignored
+func (c *Conn) readReply() (byte, error) {
+	if err := c.setReadTimeout(c.Timing.ReplyTimeout); err != nil {
+		return 0, err
+	}
+	for {
+		character, err := c.readByte(c.Timing.ReplyTimeout)
+		if err != nil {
+			return 0, err
+		}
+		switch character {
+		case ACK, NAK, ENQ, EOT:
+			return character, nil
+		}
+	}
+}
+
+// Receive the records of a message, the characters before the ENQ of the sender are ignored
+// An interrupted transfer returns the records received until then with ErrLIS1ATransferInterrupted
+func (c *Conn) Receive() (records [][]byte, err error) {
+	// Establishment
+	if err = c.setReadTimeout(c.Timing.IdleTimeout); err != nil {
+		return nil, err
+	}
+	for {
+		character, err := c.readByte(c.Timing.IdleTimeout)
+		if err != nil {
+			return nil, err
+		}
+		if character == ENQ {
+			break
+		}
+	}
+	if c.Faults.Contentions > 0 {
+		c.Faults.Contentions--
+		if err = c.write(ENQ); err != nil {
+			return nil, err
+		}
+		return nil, errmsg.ErrLIS1AContention
+	}
+	if err = c.reply(ACK); err != nil {
+		return nil, err
+	}
+	// Transfer
+	var text []byte
+	lastNumber := -1
+	interrupted := false
+	// The last frame was rejected and not sent again yet
+	rejected := false
+	for {
+		if err = c.setReadTimeout(c.Timing.ReceiveTimeout); err != nil {
+			return records, err
+		}
+		character, err := c.readByte(c.Timing.ReceiveTimeout)
+		if err != nil {
+			return records, err
+		}
+		switch character {
+		case EOT:
+			// Termination, an incomplete record is discarded
+			if interrupted || rejected || len(text) > 0 {
+				return records, errmsg.ErrLIS1ATransferInterrupted
+			}
+			return records, nil
+		case STX:
+			frame, err := c.readFrame()
+			if err != nil && !errors.Is(err, errmsg.ErrLIS1AInvalidFrame) && !errors.Is(err, errmsg.ErrLIS1AChecksumMismatch) {
+				return records, err
+			}
+			if c.Faults.Timeouts > 0 {
+				c.Faults.Timeouts--
+				interrupted = true
+				continue
+			}
+			switch {
+			case err != nil || c.Faults.NAKs > 0:
+				if err == nil {
+					c.Faults.NAKs--
+				}
+				rejected = true
+				err = c.reply(NAK)
+			case frame.Number == lastNumber:
+				// The ACK of the frame was lost, the sender repeated it
+				err = c.reply(ACK)
+			case frame.Number != nextFrameNumber(lastNumber):
+				rejected = true
+				err = c.reply(NAK)
+			default:
+				rejected = false
+				lastNumber = frame.Number
+				text = append(text, frame.Text...)
+				if frame.Last {
+					records = append(records, splitRecords(text)...)
+					text = nil
+				}
+				err = c.reply(ACK)
+			}
+			if err != nil {
+				return records, err
+			}
+		}
+	}
+}
+
+// Number of the frame expected after the last accepted one (-1 if none was accepted yet)
+func nextFrameNumber(lastNumber int) int {
+	if lastNumber == -1 {
+		return 1
+	}
+	return (lastNumber + 1) % 8
+}
+
+// Read the rest of a frame after STX
+func (c *Conn) readFrame() (Frame, error) {
+	data := []byte{STX}
+	for len(data) < maxFrameLength {
+		character, err := c.readByte(c.Timing.ReceiveTimeout)
+		if err != nil {
+			return Frame{}, err
+		}
+		data = append(data, character)
+		if character == ETX || character == ETB {
+			// Checksum, CR and LF
+			for i := 0; i < 4; i++ {
+				character, err = c.readByte(c.Timing.ReceiveTimeout)
+				if err != nil {
+					return Frame{}, err
+				}
+				data = append(data, character)
+			}
+			return ParseFrame(data)
+		}
+	}
+	return Frame{}, fmt.Errorf("%w: no terminator", errmsg.ErrLIS1AInvalidFrame)
+}
+
+func (c *Conn) reply(character byte) error {
+	if c.Faults.ReplyDelay > 0 {
+		time.Sleep(c.Faults.ReplyDelay)
+	}
+	return c.write(character)
+}
+
+func (c *Conn) write(data ...byte) error {
+	_, err := c.conn.Write(data)
+	return err
+}
+
+// Deadline for the following reads, a timeout of 0 means no deadline
+func (c *Conn) setReadTimeout(timeout time.Duration) error {
+	if timeout == 0 {
+		return c.conn.SetReadDeadline(time.Time{})
+	}
+	return c.conn.SetReadDeadline(time.Now().Add(timeout))
+}
+
+func (c *Conn) readByte(timeout time.Duration) (byte, error) {
+	character, err := c.reader.ReadByte()
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return 0, fmt.Errorf("%w: nothing received within %s", errmsg.ErrLIS1ATimeout, timeout)
+	}
+	return character, err
+}
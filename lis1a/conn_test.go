@@ -0,0 +1,244 @@
+package lis1a
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/krendel52/go-astm/v3/enums/lis1arole"
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/stretchr/testify/assert"
+)
+
+var testRecords = [][]byte{
+	[]byte("H|\\^&|||Analyzer"),
+	[]byte("R|1|^^^TSH|7.41|mU/l"),
+	[]byte("L|1|N"),
+}
+
+var testTiming = Timing{
+	ReplyTimeout:    200 * time.Millisecond,
+	ReceiveTimeout:  400 * time.Millisecond,
+	BusyDelay:       10 * time.Millisecond,
+	ContentionDelay: 50 * time.Millisecond,
+	MaxAttempts:     6,
+}
+
+// Connected instrument and computer system over TCP on localhost
+func connPair(t *testing.T) (instrument *Conn, computer *Conn) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	accepted := make(chan net.Conn)
+	go func() {
+		conn, _ := listener.Accept()
+		accepted <- conn
+	}()
+	dialed, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	instrument = NewConn(dialed, lis1arole.Instrument)
+	computer = NewConn(<-accepted, lis1arole.Computer)
+	instrument.Timing = testTiming
+	computer.Timing = testTiming
+	t.Cleanup(func() {
+		instrument.Close()
+		computer.Close()
+	})
+	return instrument, computer
+}
+
+type received struct {
+	records [][]byte
+	err     error
+}
+
+func receiveAsync(conn *Conn) chan received {
+	result := make(chan received, 1)
+	go func() {
+		records, err := conn.Receive()
+		result <- received{records: records, err: err}
+	}()
+	return result
+}
+
+func TestConnSendReceive(t *testing.T) {
+	// Arrange
+	instrument, computer := connPair(t)
+	result := receiveAsync(computer)
+	// Act
+	err := instrument.Send(testRecords)
+	// Assert
+	assert.Nil(t, err)
+	received := <-result
+	assert.Nil(t, received.err)
+	assert.Equal(t, testRecords, received.records)
+}
+
+func TestConnSendReceive_LongRecords(t *testing.T) {
+	// Arrange
+	instrument, computer := connPair(t)
+	var records [][]byte
+	for i := 0; i < 10; i++ {
+		records = append(records, []byte("C|1||"+strings.Repeat("x", 500)))
+	}
+	result := receiveAsync(computer)
+	// Act
+	err := instrument.Send(records)
+	// Assert
+	assert.Nil(t, err)
+	received := <-result
+	assert.Nil(t, received.err)
+	assert.Equal(t, records, received.records)
+}
+
+func TestConnBadChecksumsAreSentAgain(t *testing.T) {
+	// Arrange
+	instrument, computer := connPair(t)
+	instrument.Faults.BadChecksums = 2
+	result := receiveAsync(computer)
+	// Act
+	err := instrument.Send(testRecords)
+	// Assert
+	assert.Nil(t, err)
+	received := <-result
+	assert.Nil(t, received.err)
+	assert.Equal(t, testRecords, received.records)
+	assert.Equal(t, 0, instrument.Faults.BadChecksums)
+}
+
+func TestConnNAKsAreSentAgain(t *testing.T) {
+	// Arrange
+	instrument, computer := connPair(t)
+	instrument.Faults.NAKs = 3
+	result := receiveAsync(instrument)
+	// Act
+	err := computer.Send(testRecords)
+	// Assert
+	assert.Nil(t, err)
+	received := <-result
+	assert.Nil(t, received.err)
+	assert.Equal(t, testRecords, received.records)
+}
+
+func TestConnRetransmissionLimit(t *testing.T) {
+	// Arrange
+	instrument, computer := connPair(t)
+	computer.Faults.NAKs = 6
+	result := receiveAsync(computer)
+	// Act
+	err := instrument.Send(testRecords)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLIS1ARetransmissionLimit)
+	received := <-result
+	assert.ErrorIs(t, received.err, errmsg.ErrLIS1ATransferInterrupted)
+	assert.Empty(t, received.records)
+}
+
+func TestConnUnansweredFrameTimesOut(t *testing.T) {
+	// Arrange
+	instrument, computer := connPair(t)
+	instrument.Faults.Timeouts = 1
+	result := receiveAsync(instrument)
+	// Act
+	err := computer.Send(testRecords)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLIS1ATimeout)
+	received := <-result
+	assert.ErrorIs(t, received.err, errmsg.ErrLIS1ATransferInterrupted)
+}
+
+func TestConnDroppedEOTTimesOut(t *testing.T) {
+	// Arrange
+	instrument, computer := connPair(t)
+	instrument.Faults.DroppedEOTs = 1
+	result := receiveAsync(computer)
+	// Act
+	err := instrument.Send(testRecords)
+	// Assert
+	assert.Nil(t, err)
+	received := <-result
+	assert.ErrorIs(t, received.err, errmsg.ErrLIS1ATimeout)
+	assert.Equal(t, testRecords, received.records)
+}
+
+func TestConnBusyReceiver(t *testing.T) {
+	// Arrange
+	instrument, computer := connPair(t)
+	go func() {
+		// Answer every ENQ with NAK
+		for {
+			character, err := computer.reader.ReadByte()
+			if err != nil {
+				return
+			}
+			if character == ENQ {
+				_ = computer.write(NAK)
+			}
+		}
+	}()
+	// Act
+	err := instrument.Send(testRecords)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLIS1ABusy)
+}
+
+func TestConnContention_ComputerYields(t *testing.T) {
+	// Arrange
+	instrument, computer := connPair(t)
+	instrument.Faults.Contentions = 1
+	result := receiveAsync(instrument)
+	// Act
+	err := computer.Send(testRecords)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLIS1AContention)
+	received := <-result
+	assert.ErrorIs(t, received.err, errmsg.ErrLIS1AContention)
+}
+
+func TestConnContention_InstrumentSendsAgain(t *testing.T) {
+	// Arrange
+	instrument, computer := connPair(t)
+	computer.Faults.Contentions = 1
+	result := make(chan received, 1)
+	go func() {
+		_, err := computer.Receive()
+		// The computer system yields and receives the message of the instrument
+		records, errAgain := computer.Receive()
+		assert.ErrorIs(t, err, errmsg.ErrLIS1AContention)
+		result <- received{records: records, err: errAgain}
+	}()
+	// Act
+	err := instrument.Send(testRecords)
+	// Assert
+	assert.Nil(t, err)
+	received := <-result
+	assert.Nil(t, received.err)
+	assert.Equal(t, testRecords, received.records)
+}
+
+func TestConnReceiveIgnoresNoiseBeforeENQ(t *testing.T) {
+	// Arrange
+	instrument, computer := connPair(t)
+	_ = instrument.write([]byte("noise")...)
+	result := receiveAsync(computer)
+	// Act
+	err := instrument.Send(testRecords)
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, (<-result).err)
+}
+
+func TestConnIdleTimeout(t *testing.T) {
+	// Arrange
+	_, computer := connPair(t)
+	computer.Timing.IdleTimeout = 50 * time.Millisecond
+	// Act
+	_, err := computer.Receive()
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLIS1ATimeout)
+}
@@ -0,0 +1,120 @@
+package lis1a
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/krendel52/go-astm/v3/errmsg"
+)
+
+// Low-level protocol for transferring messages between instruments and computer systems (CLSI LIS1-A, formerly ASTM E1381)
+// The records of a message are sent in frames: STX FN text ETB|ETX C1 C2 CR LF
+
+// Transmission control characters
+const (
+	STX byte = 0x02
+	ETX byte = 0x03
+	EOT byte = 0x04
+	ENQ byte = 0x05
+	ACK byte = 0x06
+	LF  byte = 0x0A
+	CR  byte = 0x0D
+	NAK byte = 0x15
+	ETB byte = 0x17
+)
+
+// Maximum number of text characters in a frame (a frame is at most 247 characters)
+const MaxFrameTextLength = 240
+
+// Characters not allowed in the text of a frame, CR is only allowed as record terminator
+var restrictedCharacters = []byte{0x01, STX, ETX, EOT, ENQ, ACK, LF, 0x10, 0x11, 0x12, 0x13, 0x14, NAK, 0x16, ETB}
+
+type Frame struct {
+	Number int // frame number 0-7, the first frame of a transfer is 1
+	Text   []byte
+	Last   bool // end frame (ETX), intermediate frame (ETB) otherwise
+}
+
+// Encode the frame with its checksum
+func (f Frame) Bytes() []byte {
+	body := f.body()
+	frame := make([]byte, 0, len(body)+5)
+	frame = append(frame, STX)
+	frame = append(frame, body...)
+	frame = append(frame, Checksum(body)...)
+	return append(frame, CR, LF)
+}
+
+// Frame number, text and terminator (the characters covered by the checksum)
+func (f Frame) body() []byte {
+	body := make([]byte, 0, len(f.Text)+2)
+	body = append(body, byte('0'+f.Number%8))
+	body = append(body, f.Text...)
+	if f.Last {
+		return append(body, ETX)
+	}
+	return append(body, ETB)
+}
+
+// Checksum of the characters from the frame number to the terminator: the sum modulo 256 as 2 uppercase hex digits
+func Checksum(body []byte) string {
+	sum := 0
+	for _, character := range body {
+		sum += int(character)
+	}
+	return fmt.Sprintf("%02X", sum%256)
+}
+
+// Split the records of a message into frames, numbered from 1
+// Each record starts in a new frame and is terminated by CR, records longer than a frame are continued in intermediate frames
+func BuildFrames(records [][]byte) ([]Frame, error) {
+	frames := make([]Frame, 0, len(records))
+	number := 1
+	for _, record := range records {
+		if index := bytes.IndexAny(record, string(append(restrictedCharacters, CR))); index >= 0 {
+			return nil, fmt.Errorf("%w: %q", errmsg.ErrLIS1AInvalidFrameTextCharacter, record[index])
+		}
+		text := append(append([]byte(nil), record...), CR)
+		for len(text) > 0 {
+			size := min(len(text), MaxFrameTextLength)
+			frames = append(frames, Frame{Number: number, Text: text[:size], Last: size == len(text)})
+			text = text[size:]
+			number = (number + 1) % 8
+		}
+	}
+	return frames, nil
+}
+
+// Decode a frame from STX to LF and verify its checksum
+func ParseFrame(data []byte) (Frame, error) {
+	if len(data) < 7 || data[0] != STX || data[len(data)-2] != CR || data[len(data)-1] != LF {
+		return Frame{}, fmt.Errorf("%w: %q", errmsg.ErrLIS1AInvalidFrame, data)
+	}
+	body := data[1 : len(data)-4]
+	number := body[0]
+	terminator := body[len(body)-1]
+	if number < '0' || number > '7' || (terminator != ETX && terminator != ETB) {
+		return Frame{}, fmt.Errorf("%w: %q", errmsg.ErrLIS1AInvalidFrame, data)
+	}
+	checksum := strings.ToUpper(string(data[len(data)-4 : len(data)-2]))
+	if expected := Checksum(body); checksum != expected {
+		return Frame{}, fmt.Errorf("%w: expected %s, got %s", errmsg.ErrLIS1AChecksumMismatch, expected, checksum)
+	}
+	return Frame{
+		Number: int(number - '0'),
+		Text:   append([]byte(nil), body[1:len(body)-1]...),
+		Last:   terminator == ETX,
+	}, nil
+}
+
+// Split the received text into records at the CR terminators (empty records are dropped)
+func splitRecords(text []byte) [][]byte {
+	var records [][]byte
+	for _, record := range bytes.Split(text, []byte{CR}) {
+		if len(record) > 0 {
+			records = append(records, record)
+		}
+	}
+	return records
+}
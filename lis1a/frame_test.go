@@ -0,0 +1,105 @@
+package lis1a
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrameBytes(t *testing.T) {
+	// Arrange
+	frame := Frame{Number: 1, Text: []byte("H|\\^&\r"), Last: true}
+	// Act
+	data := frame.Bytes()
+	// Assert
+	assert.Equal(t, "\x021H|\\^&\r\x03E5\r\n", string(data))
+}
+
+func TestFrameBytes_Intermediate(t *testing.T) {
+	// Arrange
+	frame := Frame{Number: 7, Text: []byte("R|1"), Last: false}
+	// Act
+	data := frame.Bytes()
+	// Assert
+	assert.Equal(t, byte('7'), data[1])
+	assert.Equal(t, ETB, data[len(data)-5])
+}
+
+func TestBuildFrames(t *testing.T) {
+	// Arrange
+	records := [][]byte{[]byte("H|\\^&"), []byte("L|1|N")}
+	// Act
+	frames, err := BuildFrames(records)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []Frame{
+		{Number: 1, Text: []byte("H|\\^&\r"), Last: true},
+		{Number: 2, Text: []byte("L|1|N\r"), Last: true},
+	}, frames)
+	assert.Equal(t, "\x022L|1|N\r\x0305\r\n", string(frames[1].Bytes()))
+}
+
+func TestBuildFrames_LongRecordAndFrameNumberWrapAround(t *testing.T) {
+	// Arrange
+	records := [][]byte{
+		[]byte("H|\\^&"),
+		[]byte("C|1||" + strings.Repeat("x", 1000)),
+		[]byte("L|1|N"),
+	}
+	// Act
+	frames, err := BuildFrames(records)
+	// Assert
+	assert.Nil(t, err)
+	// The comment needs 5 frames of at most 240 characters (1006 characters with CR)
+	assert.Len(t, frames, 7)
+	assert.Len(t, frames[1].Text, MaxFrameTextLength)
+	assert.False(t, frames[1].Last)
+	assert.True(t, frames[5].Last)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7}, frameNumbers(frames[:7]))
+	records = append(records, []byte("H|\\^&"))
+	frames, _ = BuildFrames(records)
+	assert.Equal(t, 0, frames[7].Number)
+}
+
+func TestBuildFrames_RestrictedCharacter(t *testing.T) {
+	// Arrange
+	records := [][]byte{[]byte("C|1||a\x02b")}
+	// Act
+	_, err := BuildFrames(records)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLIS1AInvalidFrameTextCharacter)
+}
+
+func TestParseFrame(t *testing.T) {
+	// Act
+	frame, err := ParseFrame([]byte("\x021H|\\^&\r\x03e5\r\n"))
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, Frame{Number: 1, Text: []byte("H|\\^&\r"), Last: true}, frame)
+}
+
+func TestParseFrame_ChecksumMismatch(t *testing.T) {
+	// Act
+	_, err := ParseFrame([]byte("\x021H|\\^&\r\x03E4\r\n"))
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLIS1AChecksumMismatch)
+}
+
+func TestParseFrame_Invalid(t *testing.T) {
+	for _, data := range []string{"", "\x021\x03", "1H\r\x03E5\r\n", "\x029H\r\x03E5\r\n", "\x021H\r\x04E5\r\n"} {
+		// Act
+		_, err := ParseFrame([]byte(data))
+		// Assert
+		assert.ErrorIs(t, err, errmsg.ErrLIS1AInvalidFrame, data)
+	}
+}
+
+func frameNumbers(frames []Frame) []int {
+	numbers := make([]int, len(frames))
+	for i, frame := range frames {
+		numbers[i] = frame.Number
+	}
+	return numbers
+}
@@ -3,6 +3,7 @@ package astmmodels
 import (
 	"github.com/blutspende/bloodlab-common/encoding"
 	"github.com/blutspende/bloodlab-common/timezone"
+	"github.com/krendel52/go-astm/v3/enums/jsonkeys"
 	"github.com/krendel52/go-astm/v3/enums/lineseparator"
 	"github.com/krendel52/go-astm/v3/enums/notation"
 	"time"
@@ -20,6 +21,11 @@ type Configuration struct {
 	RoundLastDecimal           bool
 	KeepShortDateTimeZone      bool
 	EscapeOutputStrings        bool
+	ValidateEnums              bool
+	TruncateLongStrings        bool
+	WarningHandler             func(warning error)
+	PreserveRawRecords         bool
+	JSONKeys                   string
 	Delimiters                 Delimiters
 	TimeLocation               *time.Location
 }
@@ -35,16 +41,23 @@ var DefaultConfiguration = Configuration{
 	RoundLastDecimal:           true,
 	KeepShortDateTimeZone:      true,
 	EscapeOutputStrings:        false,
+	ValidateEnums:              false,
+	TruncateLongStrings:        false,
+	WarningHandler:             nil,
+	PreserveRawRecords:         false,
+	JSONKeys:                   jsonkeys.FieldNames,
 	Delimiters:                 DefaultDelimiters,
 	TimeLocation:               nil,
 }
 
 // Delimiters used in ASTM parsing
+// SubComponent is optional and not part of the header, it is only used for the third nesting level inside a field
 type Delimiters struct {
-	Field     string
-	Repeat    string
-	Component string
-	Escape    string
+	Field        string
+	Repeat       string
+	Component    string
+	Escape       string
+	SubComponent string
 }
 
 var DefaultDelimiters = Delimiters{
@@ -53,3 +66,14 @@ var DefaultDelimiters = Delimiters{
 	Component: `^`,
 	Escape:    `&`,
 }
+
+// Enum types implementing this interface are checked during unmarshal if ValidateEnums is enabled
+type ValidatableEnum interface {
+	IsValid() bool
+}
+
+// Original line of a record, captured by unmarshal and reused by marshal if PreserveRawRecords is enabled
+// A record opts in by having a (not annotated) field of this type
+type RawRecord struct {
+	Line string
+}
@@ -0,0 +1,33 @@
+package astmmodels
+
+import "fmt"
+
+// A single difference between two messages
+// Record is the record type with its sequence number (e.g. R|3), followed by the occurrence if the same one is repeated (e.g. R|1#2)
+// Field is the position of the value (e.g. 4.1, or 4[2].1 for the second repeat), empty if the whole record was added or removed
+// For added and removed records the New and Old values are the record itself
+type Difference struct {
+	Record string
+	Field  string
+	Old    string
+	New    string
+}
+
+func (d Difference) String() string {
+	switch {
+	case d.Field == "" && d.New == "":
+		return fmt.Sprintf("%s removed: %s", d.Record, d.Old)
+	case d.Field == "" && d.Old == "":
+		return fmt.Sprintf("%s added: %s", d.Record, d.New)
+	}
+	return fmt.Sprintf("%s field %s: %s → %s", d.Record, d.Field, d.Old, d.New)
+}
+
+// All the differences between two messages, in the order of the first message (added records at the end)
+type DiffReport struct {
+	Differences []Difference
+}
+
+func (r DiffReport) IsEqual() bool {
+	return len(r.Differences) == 0
+}
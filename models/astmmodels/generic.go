@@ -0,0 +1,27 @@
+package astmmodels
+
+// Schema-less layout of a record: the fields contain repeats, the repeats contain components
+// Fields[0] is the record type and Fields[1] is the sequence number (the delimiters in the header)
+type GenericRecord struct {
+	Type   string         `json:"type"`
+	Fields []GenericField `json:"fields"`
+}
+
+// Repeats of a field, each of them with its components (the values are unescaped)
+type GenericField [][]string
+
+// Value of the field at the given position with its repeat and component (all of them 1-based, missing ones are empty)
+func (r GenericRecord) Value(fieldPos int, repeatPos int, componentPos int) string {
+	if fieldPos < 1 || fieldPos > len(r.Fields) {
+		return ""
+	}
+	field := r.Fields[fieldPos-1]
+	if repeatPos < 1 || repeatPos > len(field) {
+		return ""
+	}
+	repeat := field[repeatPos-1]
+	if componentPos < 1 || componentPos > len(repeat) {
+		return ""
+	}
+	return repeat[componentPos-1]
+}
@@ -0,0 +1,43 @@
+package astmmodels
+
+import (
+	"errors"
+	"fmt"
+)
+
+// A single problem found by the validation of a message structure
+type ValidationIssue struct {
+	Path   string // location in the structure, e.g. PatientGroups[0].Patient.LabAssignedPatientID
+	Record string // record type name, e.g. P
+	Field  string // field position, e.g. 4 or 6.2 (empty for record level issues)
+	Err    error  // the problem, can be checked with errors.Is against the errmsg validation errors
+}
+
+func (i ValidationIssue) Error() string {
+	location := i.Record
+	if i.Field != "" {
+		location += " " + i.Field
+	}
+	return fmt.Sprintf("%s (%s): %s", i.Path, location, i.Err)
+}
+func (i ValidationIssue) Unwrap() error {
+	return i.Err
+}
+
+// All the problems found by the validation of a message structure
+type ValidationReport struct {
+	Issues []ValidationIssue
+}
+
+func (r ValidationReport) IsValid() bool {
+	return len(r.Issues) == 0
+}
+
+// Err returns the issues joined into a single error, or nil if the report is valid
+func (r ValidationReport) Err() error {
+	errs := make([]error, len(r.Issues))
+	for i, issue := range r.Issues {
+		errs[i] = issue
+	}
+	return errors.Join(errs...)
+}
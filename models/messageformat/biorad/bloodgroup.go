@@ -0,0 +1,199 @@
+package biorad
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/krendel52/go-astm/v3/errmsg"
+)
+
+// Manufacturer test type of the interpretation result carrying the blood group
+const InterpretationTestType = "Result"
+
+type ABOGroup string
+
+const (
+	ABOGroupA  ABOGroup = "A"
+	ABOGroupB  ABOGroup = "B"
+	ABOGroupAB ABOGroup = "AB"
+	ABOGroupO  ABOGroup = "O"
+)
+
+type Reaction string
+
+const (
+	ReactionPositive Reaction = "POS"
+	ReactionNegative Reaction = "NEG"
+)
+
+type Kell string
+
+const (
+	KellPositive Kell = "K+"
+	KellNegative Kell = "K-"
+)
+
+// Presence of the C, c, E and e antigens (e.g. CcEe, ccee)
+type RhPhenotype struct {
+	C       bool
+	LittleC bool
+	E       bool
+	LittleE bool
+}
+
+// Structured form of the blood group interpretation result
+// Components not determined by the test are left empty
+type BloodGroup struct {
+	ABO             ABOGroup
+	RhD             Reaction
+	RhDConfirmation Reaction
+	Phenotype       *RhPhenotype
+	Kell            Kell
+	Control         Reaction
+}
+
+func (r Result) IsInterpretation() bool {
+	return r.UniversalTestID.ManufacturersTestType == InterpretationTestType
+}
+
+// Decode the composite value of the interpretation result into the structured blood group
+func (r Result) BloodGroup() (BloodGroup, error) {
+	if !r.IsInterpretation() {
+		return BloodGroup{}, errmsg.ErrBioRadNotInterpretationResult
+	}
+	return ParseBloodGroup(r.Value)
+}
+
+// Find the interpretation result of the order and decode its blood group
+func (g OrderGroup) BloodGroup() (bloodGroup BloodGroup, found bool, err error) {
+	for _, resultGroup := range g.ResultGroups {
+		if resultGroup.Result.IsInterpretation() {
+			bloodGroup, err = resultGroup.Result.BloodGroup()
+			return bloodGroup, true, err
+		}
+	}
+	return BloodGroup{}, false, nil
+}
+
+func ParseBloodGroup(value ResultValue) (bloodGroup BloodGroup, err error) {
+	// ABO group
+	bloodGroup.ABO = ABOGroup(strings.TrimSpace(value.Value))
+	switch bloodGroup.ABO {
+	case "", ABOGroupA, ABOGroupB, ABOGroupAB, ABOGroupO:
+	default:
+		return BloodGroup{}, fmt.Errorf("%w: %q", errmsg.ErrBioRadInvalidABOGroup, value.Value)
+	}
+	// Rh(D) and control reactions
+	if bloodGroup.RhD, err = parseReaction(value.RhD); err != nil {
+		return BloodGroup{}, err
+	}
+	if bloodGroup.RhDConfirmation, err = parseReaction(value.RhDConfirmation); err != nil {
+		return BloodGroup{}, err
+	}
+	if bloodGroup.Control, err = parseReaction(value.Control); err != nil {
+		return BloodGroup{}, err
+	}
+	// Rh phenotype
+	if strings.TrimSpace(value.RhPhenotype) != "" {
+		phenotype, err := ParseRhPhenotype(value.RhPhenotype)
+		if err != nil {
+			return BloodGroup{}, err
+		}
+		bloodGroup.Phenotype = &phenotype
+	}
+	// Kell
+	bloodGroup.Kell = Kell(strings.TrimSpace(value.Kell))
+	switch bloodGroup.Kell {
+	case "", KellPositive, KellNegative:
+	default:
+		return BloodGroup{}, fmt.Errorf("%w: %q", errmsg.ErrBioRadInvalidKell, value.Kell)
+	}
+	return bloodGroup, nil
+}
+
+// Parse the phenotype notation: each antigen is written as C/c and E/e (e.g. CcEe, Ccee, ccEE)
+// The first two characters are the C/c pair, the last two the E/e pair
+func ParseRhPhenotype(value string) (phenotype RhPhenotype, err error) {
+	value = strings.TrimSpace(value)
+	if len(value) != 4 {
+		return RhPhenotype{}, fmt.Errorf("%w: %q", errmsg.ErrBioRadInvalidRhPhenotype, value)
+	}
+	for i, antigen := range value {
+		switch {
+		case i < 2 && antigen == 'C':
+			phenotype.C = true
+		case i < 2 && antigen == 'c':
+			phenotype.LittleC = true
+		case i >= 2 && antigen == 'E':
+			phenotype.E = true
+		case i >= 2 && antigen == 'e':
+			phenotype.LittleE = true
+		default:
+			return RhPhenotype{}, fmt.Errorf("%w: %q", errmsg.ErrBioRadInvalidRhPhenotype, value)
+		}
+	}
+	return phenotype, nil
+}
+
+func (p RhPhenotype) String() string {
+	return antigenPair(p.C, p.LittleC, "C", "c") + antigenPair(p.E, p.LittleE, "E", "e")
+}
+
+// Homozygous antigens are written twice (e.g. cc), heterozygous ones once each (e.g. Cc)
+func antigenPair(upper bool, lower bool, upperName string, lowerName string) string {
+	switch {
+	case upper && lower:
+		return upperName + lowerName
+	case upper:
+		return upperName + upperName
+	case lower:
+		return lowerName + lowerName
+	}
+	return ""
+}
+
+// The result is only valid if the control reaction is negative
+func (b BloodGroup) IsValid() bool {
+	return b.Control != ReactionPositive
+}
+
+// Common notation of the blood group, e.g. A RhD negative -> A NEG
+func (b BloodGroup) String() string {
+	return strings.TrimSpace(string(b.ABO) + " " + string(b.RhD))
+}
+
+func parseReaction(value string) (Reaction, error) {
+	reaction := Reaction(strings.TrimSpace(value))
+	switch reaction {
+	case "", ReactionPositive, ReactionNegative:
+		return reaction, nil
+	}
+	return "", fmt.Errorf("%w: %q", errmsg.ErrBioRadInvalidReaction, value)
+}
+
+// Lot expiry checks: the expiry date is the last day the lot can be used
+
+func (r Reagent) IsExpired(at time.Time) bool {
+	return isExpired(r.ExpiryDate, at)
+}
+func (c Cassette) IsExpired(at time.Time) bool {
+	return isExpired(c.ExpiryDate, at)
+}
+
+// Check if any of the reagents or the cassette of the comment was expired at the given time
+func (c Comment) HasExpiredLot(at time.Time) bool {
+	for _, reagent := range c.Reagents {
+		if reagent.IsExpired(at) {
+			return true
+		}
+	}
+	return c.Cassette.IsExpired(at)
+}
+
+func isExpired(expiryDate time.Time, at time.Time) bool {
+	if expiryDate.IsZero() {
+		return false
+	}
+	return !at.Before(expiryDate.AddDate(0, 0, 1))
+}
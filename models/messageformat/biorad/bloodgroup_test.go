@@ -0,0 +1,123 @@
+package biorad
+
+import (
+	"testing"
+	"time"
+
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBloodGroup_Full(t *testing.T) {
+	// Arrange
+	value := ResultValue{Value: "A", RhD: "NEG", RhDConfirmation: "NEG", RhPhenotype: "ccee", Kell: "K-", Control: "NEG"}
+	// Act
+	bloodGroup, err := ParseBloodGroup(value)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, ABOGroupA, bloodGroup.ABO)
+	assert.Equal(t, ReactionNegative, bloodGroup.RhD)
+	assert.Equal(t, ReactionNegative, bloodGroup.RhDConfirmation)
+	assert.Equal(t, RhPhenotype{LittleC: true, LittleE: true}, *bloodGroup.Phenotype)
+	assert.Equal(t, KellNegative, bloodGroup.Kell)
+	assert.True(t, bloodGroup.IsValid())
+	assert.Equal(t, "A NEG", bloodGroup.String())
+}
+
+func TestParseBloodGroup_OnlyABOAndRhD(t *testing.T) {
+	// Arrange
+	value := ResultValue{Value: "AB", RhD: "POS"}
+	// Act
+	bloodGroup, err := ParseBloodGroup(value)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, ABOGroupAB, bloodGroup.ABO)
+	assert.Equal(t, ReactionPositive, bloodGroup.RhD)
+	assert.Nil(t, bloodGroup.Phenotype)
+	assert.Equal(t, Kell(""), bloodGroup.Kell)
+}
+
+func TestParseBloodGroup_PositiveControl(t *testing.T) {
+	// Arrange
+	value := ResultValue{Value: "O", RhD: "POS", Control: "POS"}
+	// Act
+	bloodGroup, err := ParseBloodGroup(value)
+	// Assert
+	assert.Nil(t, err)
+	assert.False(t, bloodGroup.IsValid())
+}
+
+func TestParseBloodGroup_InvalidValues(t *testing.T) {
+	// Act
+	_, errABO := ParseBloodGroup(ResultValue{Value: "X"})
+	_, errReaction := ParseBloodGroup(ResultValue{Value: "A", RhD: "WEAK"})
+	_, errPhenotype := ParseBloodGroup(ResultValue{Value: "A", RhPhenotype: "cc"})
+	_, errKell := ParseBloodGroup(ResultValue{Value: "A", Kell: "K"})
+	// Assert
+	assert.ErrorIs(t, errABO, errmsg.ErrBioRadInvalidABOGroup)
+	assert.ErrorIs(t, errReaction, errmsg.ErrBioRadInvalidReaction)
+	assert.ErrorIs(t, errPhenotype, errmsg.ErrBioRadInvalidRhPhenotype)
+	assert.ErrorIs(t, errKell, errmsg.ErrBioRadInvalidKell)
+}
+
+func TestParseRhPhenotype(t *testing.T) {
+	// Act
+	heterozygous, errHeterozygous := ParseRhPhenotype("CcEe")
+	homozygous, errHomozygous := ParseRhPhenotype("CCee")
+	_, errMissingE := ParseRhPhenotype("CCcc")
+	_, errMissingLittleE := ParseRhPhenotype("CCCe")
+	_, errSwappedPairs := ParseRhPhenotype("ceCE")
+	_, errMissingC := ParseRhPhenotype("EeEe")
+	_, errUnknownAntigen := ParseRhPhenotype("CcEk")
+	// Assert
+	assert.Nil(t, errHeterozygous)
+	assert.Equal(t, RhPhenotype{C: true, LittleC: true, E: true, LittleE: true}, heterozygous)
+	assert.Equal(t, "CcEe", heterozygous.String())
+	assert.Nil(t, errHomozygous)
+	assert.Equal(t, RhPhenotype{C: true, LittleE: true}, homozygous)
+	assert.Equal(t, "CCee", homozygous.String())
+	assert.ErrorIs(t, errMissingE, errmsg.ErrBioRadInvalidRhPhenotype)
+	assert.ErrorIs(t, errMissingLittleE, errmsg.ErrBioRadInvalidRhPhenotype)
+	assert.ErrorIs(t, errSwappedPairs, errmsg.ErrBioRadInvalidRhPhenotype)
+	assert.ErrorIs(t, errMissingC, errmsg.ErrBioRadInvalidRhPhenotype)
+	assert.ErrorIs(t, errUnknownAntigen, errmsg.ErrBioRadInvalidRhPhenotype)
+}
+
+func TestResultBloodGroup_NotInterpretation(t *testing.T) {
+	// Arrange
+	result := Result{
+		UniversalTestID: lis02a2.ExtendedUniversalTestID{ManufacturersTestType: "AntiA"},
+		Value:           ResultValue{Value: "40"},
+	}
+	// Act
+	_, err := result.BloodGroup()
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrBioRadNotInterpretationResult)
+}
+
+func TestOrderGroupBloodGroup_NoInterpretation(t *testing.T) {
+	// Arrange
+	orderGroup := OrderGroup{ResultGroups: []ResultGroup{{Result: Result{UniversalTestID: lis02a2.ExtendedUniversalTestID{ManufacturersTestType: "AntiA"}}}}}
+	// Act
+	_, found, err := orderGroup.BloodGroup()
+	// Assert
+	assert.Nil(t, err)
+	assert.False(t, found)
+}
+
+func TestCommentHasExpiredLot(t *testing.T) {
+	// Arrange
+	comment := Comment{
+		Reagents: []Reagent{{Name: "ID-Diluent 2", LotNumber: "05761.03.12", ExpiryDate: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)}, {}},
+		Cassette: Cassette{Type: "CAS", LotNumber: "50053.52.06", ExpiryDate: time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC)},
+	}
+	// Act
+	lastDay := comment.HasExpiredLot(time.Date(2022, 12, 31, 23, 59, 0, 0, time.UTC))
+	dayAfter := comment.HasExpiredLot(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	// Assert
+	assert.False(t, lastDay)
+	assert.True(t, dayAfter)
+	assert.False(t, comment.Reagents[0].IsExpired(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, comment.Reagents[1].IsExpired(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)))
+}
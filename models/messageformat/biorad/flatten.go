@@ -0,0 +1,34 @@
+package biorad
+
+import "github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+
+// Flattened result with the composite value and the reagent comments of the reaction
+// The value of the standard row is the first component (reaction strength or ABO group), its result comments are empty
+type ResultRow struct {
+	lis02a2.ResultRow
+	ResultValue ResultValue
+	Reagents    []Comment
+}
+
+// Flatten the message into one row per result (reactions and interpretations), in the order of the message
+func (m ResultMessage) Flatten() []ResultRow {
+	var rows []ResultRow
+	for _, patientGroup := range m.PatientGroups {
+		for _, orderGroup := range patientGroup.OrderGroups {
+			for _, resultGroup := range orderGroup.ResultGroups {
+				result := resultGroup.Result
+				row := lis02a2.NewResultRow(patientGroup.Patient, orderGroup.Order, result.UniversalTestID)
+				row.Value = result.Value.Value
+				row.Units = result.Units
+				row.ReferenceRange = result.ReferenceRange
+				row.AbnormalFlag = result.ResultAbnormalFlag
+				row.Status = result.ResultStatus
+				row.DateTimeTestStarted = result.DateTimeTestStarted
+				row.DateTimeCompleted = result.DateTimeCompleted
+				row.PatientComments = patientGroup.Comments
+				rows = append(rows, ResultRow{ResultRow: row, ResultValue: result.Value, Reagents: resultGroup.Comments})
+			}
+		}
+	}
+	return rows
+}
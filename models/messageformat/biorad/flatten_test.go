@@ -0,0 +1,37 @@
+package biorad
+
+import (
+	"testing"
+
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultMessageFlatten(t *testing.T) {
+	// Arrange
+	reagents := []Comment{{Reagents: []Reagent{{Name: "ID-Diluent 2", LotNumber: "05761.03.12"}}, Cassette: Cassette{Type: "CAS", Column: "4"}}}
+	message := ResultMessage{
+		PatientGroups: []PatientGroup{{
+			Patient: lis02a2.Patient{LabAssignedPatientID: "1010868845"},
+			OrderGroups: []OrderGroup{{
+				Order: lis02a2.Order{SpecimenID: "1122206642"},
+				ResultGroups: []ResultGroup{
+					{Result: Result{UniversalTestID: lis02a2.ExtendedUniversalTestID{ManufacturersTestType: "AntiA"}, Value: ResultValue{Value: "40"}}, Comments: reagents},
+					{Result: Result{UniversalTestID: lis02a2.ExtendedUniversalTestID{ManufacturersTestType: InterpretationTestType}, Value: ResultValue{Value: "A", RhD: "NEG"}}},
+				},
+			}},
+		}},
+	}
+	// Act
+	rows := message.Flatten()
+	// Assert
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "1122206642", rows[0].SpecimenID)
+	assert.Equal(t, "AntiA", rows[0].TestID)
+	assert.Equal(t, "40", rows[0].Value)
+	assert.Equal(t, reagents, rows[0].Reagents)
+	assert.Nil(t, rows[0].ResultComments)
+	assert.Equal(t, InterpretationTestType, rows[1].TestID)
+	assert.Equal(t, "A", rows[1].Value)
+	assert.Equal(t, ResultValue{Value: "A", RhD: "NEG"}, rows[1].ResultValue)
+}
@@ -0,0 +1,94 @@
+package biorad
+
+import (
+	"time"
+
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+)
+
+// Bio-Rad IH-Com (IH-1000, IH-500) message format declarations to use for (un)marshaling
+// Every reaction of a blood group test is sent as a separate result, followed by the interpretation
+// result (manufacturer test type "Result") carrying the composite blood group answer
+// The comments after each result carry the reagent and cassette lots used for the reaction
+
+// Record substructures //
+
+// Blood group composite value, e.g. A^NEG^NEG^ccee^K-^NEG
+// For reaction results only the first component is set with the reaction strength (e.g. 40)
+type ResultValue struct {
+	Value           string `astm:"1"` // reaction strength or ABO group for the interpretation
+	RhD             string `astm:"2"`
+	RhDConfirmation string `astm:"3"` // reaction of the second anti-D reagent
+	RhPhenotype     string `astm:"4"` // e.g. CcEe, ccee
+	Kell            string `astm:"5"` // K+ or K-
+	Control         string `astm:"6"`
+}
+
+// Reagent used for the reaction, e.g. ID-Diluent 2^^05761.03.12^20240131
+type Reagent struct {
+	Name       string    `astm:"1"`
+	Reserved   string    `astm:"2"`
+	LotNumber  string    `astm:"3"`
+	ExpiryDate time.Time `astm:"4"`
+}
+
+// Gel card cassette used for the reaction, e.g. CAS^5005352062212117030^50053.52.06^20221231^4
+type Cassette struct {
+	Type       string    `astm:"1"` // CAS
+	ID         string    `astm:"2"`
+	LotNumber  string    `astm:"3"`
+	ExpiryDate time.Time `astm:"4"`
+	Column     string    `astm:"5"` // column of the cassette used for the reaction
+}
+
+// Record structures //
+
+type Result struct {
+	UniversalTestID                          lis02a2.ExtendedUniversalTestID `astm:"3"`
+	Value                                    ResultValue                     `astm:"4"`
+	Units                                    string                          `astm:"5"`
+	ReferenceRange                           lis02a2.ReferenceRange          `astm:"6,omitempty"`
+	ResultAbnormalFlag                       lis02a2.ResultAbnormalFlag      `astm:"7"`
+	NatureOfAbnormalTesting                  string                          `astm:"8"`
+	ResultStatus                             lis02a2.ResultStatus            `astm:"9"`
+	DateOfChangeInInstrumentNormativeTesting time.Time                       `astm:"10,longdate"`
+	OperatorIDPerformed                      string                          `astm:"11.1"`
+	OperatorIDVerified                       string                          `astm:"11.2"`
+	DateTimeTestStarted                      time.Time                       `astm:"12,longdate"`
+	DateTimeCompleted                        time.Time                       `astm:"13,longdate"`
+	InstrumentIdentification                 string                          `astm:"14"`
+	InstrumentType                           string                          `astm:"15"` // e.g. IH-1000
+	InstrumentSerialNumber                   string                          `astm:"16"`
+	Operator                                 string                          `astm:"17"`
+	Raw                                      astmmodels.RawRecord            // original line for the round-trip preservation
+}
+type Comment struct {
+	Reagents []Reagent            `astm:"3"`
+	Cassette Cassette             `astm:"4"`
+	Raw      astmmodels.RawRecord // original line for the round-trip preservation
+}
+
+// Message structures //
+
+type ResultGroup struct {
+	Result   Result    `astm:"R"`
+	Comments []Comment `astm:"C,optional"`
+}
+type OrderGroup struct {
+	Order        lis02a2.Order `astm:"O"`
+	ResultGroups []ResultGroup
+}
+type PatientGroup struct {
+	Patient     lis02a2.Patient   `astm:"P"`
+	Comments    []lis02a2.Comment `astm:"C,optional"`
+	OrderGroups []OrderGroup
+}
+
+// Messages //
+
+type ResultMessage struct {
+	Header        lis02a2.Header `astm:"H"`
+	PatientGroups []PatientGroup
+	Terminator    lis02a2.Terminator `astm:"L"`
+}
@@ -0,0 +1,82 @@
+package euroimmun
+
+import (
+	"time"
+
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+)
+
+// Euroimmun Analyzer I (v1.0) message format declarations to use for (un)marshaling
+// The records are standard LIS02-A2 records, but the instrument sends an empty header
+// and some transmissions end without a terminator record
+
+// Records //
+
+// Standard patient record, but the instrument pads the patient IDs with spaces (e.g. "TEST-27-026-5-1  57  ")
+type Patient struct {
+	PracticeAssignedPatientID          string               `astm:"3,trim"` // 7.3
+	LabAssignedPatientID               string               `astm:"4,trim"` // 7.4
+	ID3                                string               `astm:"5,trim"` // 7.5
+	LastName                           string               `astm:"6.1"`    // 7.6.1
+	FirstName                          string               `astm:"6.2"`    // 7.6.2
+	MothersMaidenName                  string               `astm:"7"`      // 7.7
+	DOB                                time.Time            `astm:"8"`      // 7.8
+	Gender                             string               `astm:"9"`      // 7.9
+	Race                               string               `astm:"10"`     // 7.10
+	Address                            string               `astm:"11"`     // 7.11
+	F12                                string               `astm:"12"`     // 7.12
+	Telephone                          string               `astm:"13"`     // 7.13
+	AttendingPhysicianID               string               `astm:"14"`     // 7.14
+	SpecialField1                      string               `astm:"15"`     // 7.15
+	SpecialField2                      string               `astm:"16"`     // 7.16
+	Height                             string               `astm:"17"`     // 7.17
+	Weight                             string               `astm:"18"`     // 7.18
+	SuspectedDiagnosis                 string               `astm:"19"`     // 7.19
+	ActiveMedication                   string               `astm:"20"`     // 7.20
+	Diet                               string               `astm:"21"`     // 7.21
+	PracticeField1                     string               `astm:"22"`     // 7.22
+	PracticeField2                     string               `astm:"23"`     // 7.23
+	AdmissionAndDischargeDates         string               `astm:"24"`     // 7.24
+	AdmissionStatus                    string               `astm:"25"`     // 7.25
+	Location                           string               `astm:"26"`     // 7.26
+	NatureOfAlternativeDiagnosticCodes string               `astm:"27"`     // 7.27
+	AlternativeDiagnosticCodes         string               `astm:"28"`     // 7.28
+	Religion                           string               `astm:"29"`     // 7.29
+	MaritalStatus                      string               `astm:"30"`     // 7.30
+	IsolationStatus                    string               `astm:"31"`     // 7.31
+	Language                           string               `astm:"32"`     // 7.32
+	HospitalService                    string               `astm:"33"`     // 7.33
+	HospitalInstitution                string               `astm:"34"`     // 7.34
+	DosageCategory                     string               `astm:"35"`     // 7.35
+	Raw                                astmmodels.RawRecord // original line for the round-trip preservation
+}
+
+// Message structures //
+
+type PatientGroup struct {
+	Patient     Patient           `astm:"P"`
+	Comments    []lis02a2.Comment `astm:"C,optional"`
+	OrderGroups []lis02a2.OrderGroup
+}
+
+// Messages //
+
+type ResultMessage struct {
+	Header        lis02a2.Header `astm:"H"`
+	PatientGroups []PatientGroup
+	Terminator    lis02a2.Terminator `astm:"L,optional"`
+}
+
+// LIS02A2 returns the message in the standard structure (e.g. for the converters)
+func (m ResultMessage) LIS02A2() lis02a2.ResultMessage {
+	message := lis02a2.ResultMessage{Header: m.Header, Terminator: m.Terminator}
+	for _, patientGroup := range m.PatientGroups {
+		message.PatientGroups = append(message.PatientGroups, lis02a2.PatientGroup{
+			Patient:     lis02a2.Patient(patientGroup.Patient),
+			Comments:    patientGroup.Comments,
+			OrderGroups: patientGroup.OrderGroups,
+		})
+	}
+	return message
+}
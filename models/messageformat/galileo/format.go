@@ -0,0 +1,30 @@
+package galileo
+
+import (
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+)
+
+// Immucor Galileo (Echo) message format declarations to use for (un)marshaling
+// The records are standard LIS02-A2 records, only the message structure differs:
+// orders sent to the instrument can carry comments (e.g. donor references for crossmatches)
+
+// Message structures //
+
+type OrderGroup struct {
+	Order    lis02a2.Order     `astm:"O"`
+	Comments []lis02a2.Comment `astm:"C,optional"`
+}
+type PatientOrderGroup struct {
+	Patient     lis02a2.Patient   `astm:"P"`
+	Comments    []lis02a2.Comment `astm:"C,optional"`
+	OrderGroups []OrderGroup
+}
+
+// Messages //
+
+type OrderMessage struct {
+	Header        lis02a2.Header `astm:"H"`
+	PatientGroups []PatientOrderGroup
+	Terminator    lis02a2.Terminator `astm:"L"`
+}
+type ResultMessage = lis02a2.ResultMessage
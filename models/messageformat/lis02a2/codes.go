@@ -0,0 +1,171 @@
+package lis02a2
+
+// Coded field values defined by the LIS02-A2 standard
+// The commented section numbers below (eg: 11.13) refer to the document's sections
+
+// Request information status codes (11.13)
+type RequestInformationStatus string
+
+const (
+	RequestInformationStatusCorrection      RequestInformationStatus = "C" // correction of previously transmitted results
+	RequestInformationStatusPreliminary     RequestInformationStatus = "P" // preliminary results
+	RequestInformationStatusFinal           RequestInformationStatus = "F" // final results
+	RequestInformationStatusCancelled       RequestInformationStatus = "X" // no results available for the requested test
+	RequestInformationStatusPending         RequestInformationStatus = "I" // request results pending
+	RequestInformationStatusPartial         RequestInformationStatus = "S" // request partial or unfinalized results
+	RequestInformationStatusMIC             RequestInformationStatus = "M" // result is an MIC level
+	RequestInformationStatusPrevious        RequestInformationStatus = "R" // previously transmitted results
+	RequestInformationStatusAbort           RequestInformationStatus = "A" // abort or cancel the last request criteria
+	RequestInformationStatusNewOnly         RequestInformationStatus = "N" // requesting new or edited results only
+	RequestInformationStatusOrdersOnly      RequestInformationStatus = "O" // requesting test orders and demographics only
+	RequestInformationStatusDemographicOnly RequestInformationStatus = "D" // requesting demographics only
+)
+
+// Processing IDs (6.12)
+type ProcessingID string
+
+const (
+	ProcessingIDProduction     ProcessingID = "P" // production: treat message as an active message to be completed according to standard processing
+	ProcessingIDTraining       ProcessingID = "T" // training: message is initiated by a trainee and should not have an effect on the system
+	ProcessingIDDebugging      ProcessingID = "D" // debugging: message is initiated for the purpose of a debugging program
+	ProcessingIDQualityControl ProcessingID = "Q" // quality control: message is initiated for the purpose of transmitting quality control results
+)
+
+// Priorities (8.4.6)
+type Priority string
+
+const (
+	PriorityStat         Priority = "S" // stat
+	PriorityASAP         Priority = "A" // as soon as possible
+	PriorityRoutine      Priority = "R" // routine
+	PriorityCallback     Priority = "C" // callback
+	PriorityPreoperative Priority = "P" // preoperative
+)
+
+// Action codes (8.4.12)
+type ActionCode string
+
+const (
+	ActionCodeCancel         ActionCode = "C" // cancel request for the battery or tests named
+	ActionCodeAdd            ActionCode = "A" // add the requested tests or batteries to the existing specimen
+	ActionCodeNew            ActionCode = "N" // new requests accompanying a new specimen
+	ActionCodePending        ActionCode = "P" // pending specimen
+	ActionCodeReserved       ActionCode = "L" // reserved
+	ActionCodeInProcess      ActionCode = "X" // specimen or test already in process
+	ActionCodeQualityControl ActionCode = "Q" // treat specimen as a Q/C test specimen
+)
+
+// Report types (8.4.26)
+type ReportType string
+
+const (
+	ReportTypeOrder         ReportType = "O" // order record; user asking that analysis be performed
+	ReportTypeCorrection    ReportType = "C" // correction of previously transmitted results
+	ReportTypePreliminary   ReportType = "P" // preliminary results
+	ReportTypeFinal         ReportType = "F" // final results
+	ReportTypeCancelled     ReportType = "X" // order cannot be done, order cancelled
+	ReportTypePending       ReportType = "I" // in instrument pending
+	ReportTypeNoOrder       ReportType = "Y" // no order on record for this test
+	ReportTypeNoPatient     ReportType = "Z" // no record of this patient
+	ReportTypeQueryResponse ReportType = "Q" // response to query
+)
+
+// Result abnormal flags (9.7)
+type ResultAbnormalFlag string
+
+const (
+	ResultAbnormalFlagLow               ResultAbnormalFlag = "L"  // below low normal
+	ResultAbnormalFlagHigh              ResultAbnormalFlag = "H"  // above high normal
+	ResultAbnormalFlagPanicLow          ResultAbnormalFlag = "LL" // below panic normal
+	ResultAbnormalFlagPanicHigh         ResultAbnormalFlag = "HH" // above panic high
+	ResultAbnormalFlagBelowAbsoluteLow  ResultAbnormalFlag = "<"  // below absolute low, that is off low scale on an instrument
+	ResultAbnormalFlagAboveAbsoluteHigh ResultAbnormalFlag = ">"  // above absolute high, that is off high scale on an instrument
+	ResultAbnormalFlagNormal            ResultAbnormalFlag = "N"  // normal
+	ResultAbnormalFlagAbnormal          ResultAbnormalFlag = "A"  // abnormal
+	ResultAbnormalFlagVeryAbnormal      ResultAbnormalFlag = "AA" // very abnormal
+	ResultAbnormalFlagSignificantlyUp   ResultAbnormalFlag = "U"  // significant change up
+	ResultAbnormalFlagSignificantlyDown ResultAbnormalFlag = "D"  // significant change down
+	ResultAbnormalFlagBetter            ResultAbnormalFlag = "B"  // better, use when direction not relevant or not defined
+	ResultAbnormalFlagWorse             ResultAbnormalFlag = "W"  // worse, use when direction not relevant or not defined
+)
+
+// Result status codes (9.9)
+type ResultStatus string
+
+const (
+	ResultStatusCorrection    ResultStatus = "C" // correction of previously transmitted results
+	ResultStatusPreliminary   ResultStatus = "P" // preliminary results
+	ResultStatusFinal         ResultStatus = "F" // final results
+	ResultStatusCancelled     ResultStatus = "X" // order cannot be done
+	ResultStatusPending       ResultStatus = "I" // in instrument, results pending
+	ResultStatusPartial       ResultStatus = "S" // partial results
+	ResultStatusMIC           ResultStatus = "M" // result is an MIC level
+	ResultStatusPrevious      ResultStatus = "R" // result previously transmitted
+	ResultStatusNew           ResultStatus = "N" // result contains necessary information to run a new order
+	ResultStatusQueryResponse ResultStatus = "Q" // result is a response to an outstanding query
+	ResultStatusVerified      ResultStatus = "V" // operator verified or approved result
+	ResultStatusWarning       ResultStatus = "W" // warning: validity is questionable
+)
+
+// Termination codes (12.3)
+type TerminatorCode string
+
+const (
+	TerminatorCodeNormal         TerminatorCode = "N" // normal termination
+	TerminatorCodeSenderAborted  TerminatorCode = "T" // sender aborted
+	TerminatorCodeReceiverAbort  TerminatorCode = "R" // receiver requested abort
+	TerminatorCodeUnknownError   TerminatorCode = "E" // unknown system error
+	TerminatorCodeQueryError     TerminatorCode = "Q" // error in last request for information
+	TerminatorCodeNoInformation  TerminatorCode = "I" // no information available from last query
+	TerminatorCodeQueryProcessed TerminatorCode = "F" // last request for information processed
+)
+
+// Validity checks of the coded values (the empty value is always valid as every field is optional by default)
+
+func (c RequestInformationStatus) IsValid() bool {
+	return isValidCode(c, RequestInformationStatusCorrection, RequestInformationStatusPreliminary, RequestInformationStatusFinal,
+		RequestInformationStatusCancelled, RequestInformationStatusPending, RequestInformationStatusPartial, RequestInformationStatusMIC,
+		RequestInformationStatusPrevious, RequestInformationStatusAbort, RequestInformationStatusNewOnly, RequestInformationStatusOrdersOnly,
+		RequestInformationStatusDemographicOnly)
+}
+func (c ProcessingID) IsValid() bool {
+	return isValidCode(c, ProcessingIDProduction, ProcessingIDTraining, ProcessingIDDebugging, ProcessingIDQualityControl)
+}
+func (c Priority) IsValid() bool {
+	return isValidCode(c, PriorityStat, PriorityASAP, PriorityRoutine, PriorityCallback, PriorityPreoperative)
+}
+func (c ActionCode) IsValid() bool {
+	return isValidCode(c, ActionCodeCancel, ActionCodeAdd, ActionCodeNew, ActionCodePending, ActionCodeReserved,
+		ActionCodeInProcess, ActionCodeQualityControl)
+}
+func (c ReportType) IsValid() bool {
+	return isValidCode(c, ReportTypeOrder, ReportTypeCorrection, ReportTypePreliminary, ReportTypeFinal, ReportTypeCancelled,
+		ReportTypePending, ReportTypeNoOrder, ReportTypeNoPatient, ReportTypeQueryResponse)
+}
+func (c ResultAbnormalFlag) IsValid() bool {
+	return isValidCode(c, ResultAbnormalFlagLow, ResultAbnormalFlagHigh, ResultAbnormalFlagPanicLow, ResultAbnormalFlagPanicHigh,
+		ResultAbnormalFlagBelowAbsoluteLow, ResultAbnormalFlagAboveAbsoluteHigh, ResultAbnormalFlagNormal, ResultAbnormalFlagAbnormal,
+		ResultAbnormalFlagVeryAbnormal, ResultAbnormalFlagSignificantlyUp, ResultAbnormalFlagSignificantlyDown,
+		ResultAbnormalFlagBetter, ResultAbnormalFlagWorse)
+}
+func (c ResultStatus) IsValid() bool {
+	return isValidCode(c, ResultStatusCorrection, ResultStatusPreliminary, ResultStatusFinal, ResultStatusCancelled,
+		ResultStatusPending, ResultStatusPartial, ResultStatusMIC, ResultStatusPrevious, ResultStatusNew,
+		ResultStatusQueryResponse, ResultStatusVerified, ResultStatusWarning)
+}
+func (c TerminatorCode) IsValid() bool {
+	return isValidCode(c, TerminatorCodeNormal, TerminatorCodeSenderAborted, TerminatorCodeReceiverAbort,
+		TerminatorCodeUnknownError, TerminatorCodeQueryError, TerminatorCodeNoInformation, TerminatorCodeQueryProcessed)
+}
+
+func isValidCode[T ~string](value T, valids ...T) bool {
+	if value == "" {
+		return true
+	}
+	for _, valid := range valids {
+		if value == valid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package lis02a2
+
+import "time"
+
+// Flattened result: the result with the patient and order it belongs to and the comments attached at each level
+type ResultRow struct {
+	Patient             Patient
+	Order               Order
+	SpecimenID          string
+	TestID              string // manufacturer's test type, or the universal test ID if not set
+	UniversalTestID     ExtendedUniversalTestID
+	Value               string
+	Units               string
+	ReferenceRange      ReferenceRange
+	AbnormalFlag        ResultAbnormalFlag
+	Status              ResultStatus
+	DateTimeTestStarted time.Time
+	DateTimeCompleted   time.Time
+	PatientComments     []Comment
+	OrderComments       []Comment
+	ResultComments      []Comment
+}
+
+// Specimen ID of the row, used by GroupBySpecimen
+func (r ResultRow) Specimen() string {
+	return r.SpecimenID
+}
+
+// Results of a specimen in the order of the message
+type SpecimenRows[T any] struct {
+	SpecimenID string
+	Rows       []T
+}
+
+// Flatten the message into one row per result, in the order of the message
+// Orders without results are left out
+func (m ResultMessage) Flatten() []ResultRow {
+	var rows []ResultRow
+	for _, patientGroup := range m.PatientGroups {
+		for _, orderGroup := range patientGroup.OrderGroups {
+			for _, resultGroup := range orderGroup.ResultGroups {
+				row := NewResultRow(patientGroup.Patient, orderGroup.Order, resultGroup.Result.UniversalTestID)
+				row.Value = resultGroup.Result.DataMeasurementValue
+				row.Units = resultGroup.Result.Units
+				row.ReferenceRange = resultGroup.Result.ReferenceRange
+				row.AbnormalFlag = resultGroup.Result.ResultAbnormalFlag
+				row.Status = resultGroup.Result.ResultStatus
+				row.DateTimeTestStarted = resultGroup.Result.DateTimeTestStarted
+				row.DateTimeCompleted = resultGroup.Result.DateTimeCompleted
+				row.PatientComments = patientGroup.Comments
+				row.OrderComments = orderGroup.Comments
+				row.ResultComments = resultGroup.Comments
+				rows = append(rows, row)
+			}
+		}
+	}
+	return rows
+}
+
+// Flatten all the messages into one list of rows
+func (m ResultMultiMessage) Flatten() []ResultRow {
+	var rows []ResultRow
+	for _, message := range m.ResultMessages {
+		rows = append(rows, message.Flatten()...)
+	}
+	return rows
+}
+
+// Row with the patient, order and test identification set (for the flattening of manufacturer result structures)
+func NewResultRow(patient Patient, order Order, universalTestID ExtendedUniversalTestID) ResultRow {
+	testID := universalTestID.ManufacturersTestType
+	if testID == "" {
+		testID = universalTestID.UniversalTestID
+	}
+	return ResultRow{
+		Patient:         patient,
+		Order:           order,
+		SpecimenID:      order.SpecimenID,
+		TestID:          testID,
+		UniversalTestID: universalTestID,
+	}
+}
+
+// Group the rows by specimen, the specimens and their rows keep the order of the rows
+func GroupBySpecimen[T interface{ Specimen() string }](rows []T) []SpecimenRows[T] {
+	var groups []SpecimenRows[T]
+	indexes := make(map[string]int)
+	for _, row := range rows {
+		index, exists := indexes[row.Specimen()]
+		if !exists {
+			index = len(groups)
+			indexes[row.Specimen()] = index
+			groups = append(groups, SpecimenRows[T]{SpecimenID: row.Specimen()})
+		}
+		groups[index].Rows = append(groups[index].Rows, row)
+	}
+	return groups
+}
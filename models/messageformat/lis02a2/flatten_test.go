@@ -0,0 +1,97 @@
+package lis02a2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testResultMessage() ResultMessage {
+	completed := time.Date(2024, 6, 25, 9, 22, 45, 0, time.UTC)
+	return ResultMessage{
+		PatientGroups: []PatientGroup{{
+			Patient:  Patient{LabAssignedPatientID: "PAT-1"},
+			Comments: []Comment{{CommentText: "fasting"}},
+			OrderGroups: []OrderGroup{{
+				Order:    Order{SpecimenID: "S1"},
+				Comments: []Comment{{CommentText: "hemolytic"}},
+				ResultGroups: []ResultGroup{
+					{
+						Result: Result{
+							UniversalTestID:      ExtendedUniversalTestID{ManufacturersTestType: "TSH"},
+							DataMeasurementValue: "7.41",
+							Units:                "mU/l",
+							ResultAbnormalFlag:   ResultAbnormalFlagHigh,
+							ResultStatus:         ResultStatusFinal,
+							DateTimeCompleted:    completed,
+						},
+						Comments: []Comment{{CommentText: "repeated"}},
+					},
+					{Result: Result{UniversalTestID: ExtendedUniversalTestID{UniversalTestID: "3024-7"}, DataMeasurementValue: "12.1"}},
+				},
+			}, {
+				Order: Order{SpecimenID: "S2"},
+			}},
+		}, {
+			Patient: Patient{LabAssignedPatientID: "PAT-2"},
+			OrderGroups: []OrderGroup{{
+				Order:        Order{SpecimenID: "S1"},
+				ResultGroups: []ResultGroup{{Result: Result{UniversalTestID: ExtendedUniversalTestID{ManufacturersTestType: "FT4"}}}},
+			}},
+		}},
+	}
+}
+
+func TestResultMessageFlatten(t *testing.T) {
+	// Arrange
+	message := testResultMessage()
+	// Act
+	rows := message.Flatten()
+	// Assert
+	assert.Len(t, rows, 3)
+	assert.Equal(t, "PAT-1", rows[0].Patient.LabAssignedPatientID)
+	assert.Equal(t, "S1", rows[0].SpecimenID)
+	assert.Equal(t, "TSH", rows[0].TestID)
+	assert.Equal(t, "7.41", rows[0].Value)
+	assert.Equal(t, "mU/l", rows[0].Units)
+	assert.Equal(t, ResultAbnormalFlagHigh, rows[0].AbnormalFlag)
+	assert.Equal(t, ResultStatusFinal, rows[0].Status)
+	assert.Equal(t, time.Date(2024, 6, 25, 9, 22, 45, 0, time.UTC), rows[0].DateTimeCompleted)
+	assert.Equal(t, []Comment{{CommentText: "fasting"}}, rows[0].PatientComments)
+	assert.Equal(t, []Comment{{CommentText: "hemolytic"}}, rows[0].OrderComments)
+	assert.Equal(t, []Comment{{CommentText: "repeated"}}, rows[0].ResultComments)
+	assert.Equal(t, "3024-7", rows[1].TestID)
+	assert.Nil(t, rows[1].ResultComments)
+	assert.Equal(t, "PAT-2", rows[2].Patient.LabAssignedPatientID)
+	assert.Equal(t, "FT4", rows[2].TestID)
+}
+
+func TestResultMultiMessageFlatten(t *testing.T) {
+	// Arrange
+	message := ResultMultiMessage{ResultMessages: []ResultMessage{testResultMessage(), testResultMessage()}}
+	// Act
+	rows := message.Flatten()
+	// Assert
+	assert.Len(t, rows, 6)
+	assert.Equal(t, "TSH", rows[3].TestID)
+}
+
+func TestGroupBySpecimen(t *testing.T) {
+	// Arrange
+	rows := testResultMessage().Flatten()
+	// Act
+	groups := GroupBySpecimen(rows)
+	// Assert
+	assert.Len(t, groups, 1)
+	assert.Equal(t, "S1", groups[0].SpecimenID)
+	assert.Len(t, groups[0].Rows, 3)
+	assert.Equal(t, "FT4", groups[0].Rows[2].TestID)
+}
+
+func TestGroupBySpecimen_Empty(t *testing.T) {
+	// Act
+	groups := GroupBySpecimen([]ResultRow(nil))
+	// Assert
+	assert.Nil(t, groups)
+}
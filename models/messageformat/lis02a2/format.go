@@ -43,57 +43,59 @@ type Header struct {
 	CharacteristicsOfSender string                `astm:"9"`           // 6.9
 	ReceiverID              string                `astm:"10"`          // 6.10
 	Comment                 string                `astm:"11"`          // 6.11
-	ProcessingID            string                `astm:"12"`          // 6.12
+	ProcessingID            ProcessingID          `astm:"12"`          // 6.12
 	Version                 string                `astm:"13"`          // 6.13
 	DateAndTime             time.Time             `astm:"14,longdate"` // 6.14
+	Raw                     astmmodels.RawRecord  // original line for the round-trip preservation
 }
 type Patient struct {
-	PracticeAssignedPatientID          string    `astm:"3"`   // 7.3
-	LabAssignedPatientID               string    `astm:"4"`   // 7.4
-	ID3                                string    `astm:"5"`   // 7.5
-	LastName                           string    `astm:"6.1"` // 7.6.1
-	FirstName                          string    `astm:"6.2"` // 7.6.2
-	MothersMaidenName                  string    `astm:"7"`   // 7.7
-	DOB                                time.Time `astm:"8"`   // 7.8
-	Gender                             string    `astm:"9"`   // 7.9
-	Race                               string    `astm:"10"`  // 7.10
-	Address                            string    `astm:"11"`  // 7.11
-	F12                                string    `astm:"12"`  // 7.12
-	Telephone                          string    `astm:"13"`  // 7.13
-	AttendingPhysicianID               string    `astm:"14"`  // 7.14
-	SpecialField1                      string    `astm:"15"`  // 7.15
-	SpecialField2                      string    `astm:"16"`  // 7.16
-	Height                             string    `astm:"17"`  // 7.17
-	Weight                             string    `astm:"18"`  // 7.18
-	SuspectedDiagnosis                 string    `astm:"19"`  // 7.19
-	ActiveMedication                   string    `astm:"20"`  // 7.20
-	Diet                               string    `astm:"21"`  // 7.21
-	PracticeField1                     string    `astm:"22"`  // 7.22
-	PracticeField2                     string    `astm:"23"`  // 7.23
-	AdmissionAndDischargeDates         string    `astm:"24"`  // 7.24
-	AdmissionStatus                    string    `astm:"25"`  // 7.25
-	Location                           string    `astm:"26"`  // 7.26
-	NatureOfAlternativeDiagnosticCodes string    `astm:"27"`  // 7.27
-	AlternativeDiagnosticCodes         string    `astm:"28"`  // 7.28
-	Religion                           string    `astm:"29"`  // 7.29
-	MaritalStatus                      string    `astm:"30"`  // 7.30
-	IsolationStatus                    string    `astm:"31"`  // 7.31
-	Language                           string    `astm:"32"`  // 7.32
-	HospitalService                    string    `astm:"33"`  // 7.33
-	HospitalInstitution                string    `astm:"34"`  // 7.34
-	DosageCategory                     string    `astm:"35"`  // 7.35
+	PracticeAssignedPatientID          string               `astm:"3"`   // 7.3
+	LabAssignedPatientID               string               `astm:"4"`   // 7.4
+	ID3                                string               `astm:"5"`   // 7.5
+	LastName                           string               `astm:"6.1"` // 7.6.1
+	FirstName                          string               `astm:"6.2"` // 7.6.2
+	MothersMaidenName                  string               `astm:"7"`   // 7.7
+	DOB                                time.Time            `astm:"8"`   // 7.8
+	Gender                             string               `astm:"9"`   // 7.9
+	Race                               string               `astm:"10"`  // 7.10
+	Address                            string               `astm:"11"`  // 7.11
+	F12                                string               `astm:"12"`  // 7.12
+	Telephone                          string               `astm:"13"`  // 7.13
+	AttendingPhysicianID               string               `astm:"14"`  // 7.14
+	SpecialField1                      string               `astm:"15"`  // 7.15
+	SpecialField2                      string               `astm:"16"`  // 7.16
+	Height                             string               `astm:"17"`  // 7.17
+	Weight                             string               `astm:"18"`  // 7.18
+	SuspectedDiagnosis                 string               `astm:"19"`  // 7.19
+	ActiveMedication                   string               `astm:"20"`  // 7.20
+	Diet                               string               `astm:"21"`  // 7.21
+	PracticeField1                     string               `astm:"22"`  // 7.22
+	PracticeField2                     string               `astm:"23"`  // 7.23
+	AdmissionAndDischargeDates         string               `astm:"24"`  // 7.24
+	AdmissionStatus                    string               `astm:"25"`  // 7.25
+	Location                           string               `astm:"26"`  // 7.26
+	NatureOfAlternativeDiagnosticCodes string               `astm:"27"`  // 7.27
+	AlternativeDiagnosticCodes         string               `astm:"28"`  // 7.28
+	Religion                           string               `astm:"29"`  // 7.29
+	MaritalStatus                      string               `astm:"30"`  // 7.30
+	IsolationStatus                    string               `astm:"31"`  // 7.31
+	Language                           string               `astm:"32"`  // 7.32
+	HospitalService                    string               `astm:"33"`  // 7.33
+	HospitalInstitution                string               `astm:"34"`  // 7.34
+	DosageCategory                     string               `astm:"35"`  // 7.35
+	Raw                                astmmodels.RawRecord // original line for the round-trip preservation
 }
 type Order struct {
 	SpecimenID                   string                  `astm:"3"`           // 8.4.3
 	InstrumentSpecimenID         string                  `astm:"4"`           // 8.4.4
 	UniversalTestID              StandardUniversalTestID `astm:"5"`           // 8.4.5
-	Priority                     string                  `astm:"6"`           // 8.4.6
+	Priority                     Priority                `astm:"6"`           // 8.4.6
 	RequestedOrderDateTime       time.Time               `astm:"7,longdate"`  // 8.4.7
 	SpecimenCollectionDateTime   time.Time               `astm:"8,longdate"`  // 8.4.8
 	CollectionEndTime            time.Time               `astm:"9,longdate"`  // 8.4.9
 	CollectionVolume             string                  `astm:"10"`          // 8.4.10
 	CollectionID                 string                  `astm:"11"`          // 8.4.11
-	ActionCode                   string                  `astm:"12"`          // 8.4.12
+	ActionCode                   ActionCode              `astm:"12"`          // 8.4.12
 	DangerCode                   string                  `astm:"13"`          // 8.4.13
 	RelevantClinicalInformation  string                  `astm:"14"`          // 8.4.14
 	DateTimeSpecimenReceived     string                  `astm:"15"`          // 8.4.15
@@ -108,12 +110,13 @@ type Order struct {
 	DateTimeResultsReported      time.Time               `astm:"23,longdate"` // 8.4.23
 	InstrumentCharge             string                  `astm:"24"`          // 8.4.24
 	InstrumentSectionID          string                  `astm:"25"`          // 8.4.25
-	ReportType                   string                  `astm:"26"`          // 8.4.26
+	ReportType                   ReportType              `astm:"26"`          // 8.4.26
 	Reserved                     string                  `astm:"27"`          // 8.4.27
 	LocationOfSpecimenCollection string                  `astm:"28"`          // 8.4.28
 	NosocomialInfectionFlag      string                  `astm:"29"`          // 8.4.29
 	SpecimenService              string                  `astm:"30"`          // 8.4.30
 	SpecimenInstitution          string                  `astm:"31"`          // 8.4.31
+	Raw                          astmmodels.RawRecord    // original line for the round-trip preservation
 }
 type Result struct {
 	UniversalTestID                          ExtendedUniversalTestID `astm:"3"`           // 9.3
@@ -121,51 +124,78 @@ type Result struct {
 	InitialMeasurementValue                  string                  `astm:"4.2"`         // 9.4
 	MeasurementValueOfDevice                 string                  `astm:"4.3"`         // 9.4
 	Units                                    string                  `astm:"5"`           // 9.5
-	ReferenceRange                           string                  `astm:"6"`           // 9.6
-	ResultAbnormalFlag                       string                  `astm:"7"`           // 9.7
+	ReferenceRange                           ReferenceRange          `astm:"6,omitempty"` // 9.6
+	ResultAbnormalFlag                       ResultAbnormalFlag      `astm:"7"`           // 9.7
 	NatureOfAbnormalTesting                  string                  `astm:"8"`           // 9.8
-	ResultStatus                             string                  `astm:"9"`           // 9.9
+	ResultStatus                             ResultStatus            `astm:"9"`           // 9.9
 	DateOfChangeInInstrumentNormativeTesting time.Time               `astm:"10,longdate"` // 9.10
 	OperatorIDPerformed                      string                  `astm:"11.1"`        // 9.11
 	OperatorIDVerified                       string                  `astm:"11.2"`        // 9.11
 	DateTimeTestStarted                      time.Time               `astm:"12,longdate"` // 9.12
 	DateTimeCompleted                        time.Time               `astm:"13,longdate"` // 9.13
 	InstrumentIdentification                 string                  `astm:"14"`          // 9.14
+	Raw                                      astmmodels.RawRecord    // original line for the round-trip preservation
 }
 type Query struct {
-	StartingRangeIDNumber           string `astm:"3"`  // 11.3
-	EndingRangeIDNumber             string `astm:"4"`  // 11.4
-	UniversalTestID                 string `astm:"5"`  // 11.5
-	NatureOfRequestTimeLimits       string `astm:"6"`  // 11.6
-	BeginningRequestResultsDateTime string `astm:"7"`  // 11.7
-	EndingRequestResultsDateTime    string `astm:"8"`  // 11.8
-	RequestingPhysicianName         string `astm:"9"`  // 11.9
-	RequestingPhysicianTelephone    string `astm:"10"` // 11.10
-	UserField1                      string `astm:"11"` // 11.11
-	UserField2                      string `astm:"12"` // 11.12
-	RequestInformationStatus        string `astm:"13"` // 11.13
+	StartingRangeIDNumber           string                   `astm:"3"`  // 11.3
+	EndingRangeIDNumber             string                   `astm:"4"`  // 11.4
+	UniversalTestID                 string                   `astm:"5"`  // 11.5
+	NatureOfRequestTimeLimits       string                   `astm:"6"`  // 11.6
+	BeginningRequestResultsDateTime string                   `astm:"7"`  // 11.7
+	EndingRequestResultsDateTime    string                   `astm:"8"`  // 11.8
+	RequestingPhysicianName         string                   `astm:"9"`  // 11.9
+	RequestingPhysicianTelephone    string                   `astm:"10"` // 11.10
+	UserField1                      string                   `astm:"11"` // 11.11
+	UserField2                      string                   `astm:"12"` // 11.12
+	RequestInformationStatus        RequestInformationStatus `astm:"13"` // 11.13
+	Raw                             astmmodels.RawRecord     // original line for the round-trip preservation
 }
 type Comment struct {
-	CommentSource string `astm:"3"` // 10.3
-	CommentText   string `astm:"4"` // 10.4
-	CommentType   string `astm:"5"` // 10.5
+	CommentSource string               `astm:"3"` // 10.3
+	CommentText   string               `astm:"4"` // 10.4
+	CommentType   string               `astm:"5"` // 10.5
+	Raw           astmmodels.RawRecord // original line for the round-trip preservation
+}
+type Scientific struct {
+	AnalyticalMethod            string               `astm:"3"`           // 13.3
+	Instrumentation             string               `astm:"4"`           // 13.4
+	Reagents                    string               `astm:"5"`           // 13.5
+	UnitsOfMeasure              string               `astm:"6"`           // 13.6
+	QualityControl              string               `astm:"7"`           // 13.7
+	SpecimenDescriptor          string               `astm:"8"`           // 13.8
+	Reserved                    string               `astm:"9"`           // 13.9
+	Container                   string               `astm:"10"`          // 13.10
+	SpecimenID                  string               `astm:"11"`          // 13.11
+	Analyte                     string               `astm:"12"`          // 13.12
+	Result                      string               `astm:"13"`          // 13.13
+	ResultUnits                 string               `astm:"14"`          // 13.14
+	CollectionDateTime          time.Time            `astm:"15,longdate"` // 13.15
+	ResultDateTime              time.Time            `astm:"16,longdate"` // 13.16
+	AnalyticalPreprocessingStep string               `astm:"17"`          // 13.17
+	PatientDiagnosis            string               `astm:"18"`          // 13.18
+	PatientBirthdate            time.Time            `astm:"19"`          // 13.19
+	PatientSex                  string               `astm:"20"`          // 13.20
+	PatientRace                 string               `astm:"21"`          // 13.21
+	Raw                         astmmodels.RawRecord // original line for the round-trip preservation
 }
 type Manufacturer struct {
-	F3  string `astm:"3"`  // 14.3
-	F4  string `astm:"4"`  // 14.4
-	F5  string `astm:"5"`  // 14.5
-	F6  string `astm:"6"`  // 14.6
-	F7  string `astm:"7"`  // 14.7
-	F8  string `astm:"8"`  // 14.8
-	F9  string `astm:"9"`  // 14.9
-	F10 string `astm:"10"` // 14.10
-	F11 string `astm:"11"` // 14.11
-	F12 string `astm:"12"` // 14.12
-	F13 string `astm:"13"` // 14.13
-	F14 string `astm:"14"` // 14.14
+	F3  string               `astm:"3"`  // 14.3
+	F4  string               `astm:"4"`  // 14.4
+	F5  string               `astm:"5"`  // 14.5
+	F6  string               `astm:"6"`  // 14.6
+	F7  string               `astm:"7"`  // 14.7
+	F8  string               `astm:"8"`  // 14.8
+	F9  string               `astm:"9"`  // 14.9
+	F10 string               `astm:"10"` // 14.10
+	F11 string               `astm:"11"` // 14.11
+	F12 string               `astm:"12"` // 14.12
+	F13 string               `astm:"13"` // 14.13
+	F14 string               `astm:"14"` // 14.14
+	Raw astmmodels.RawRecord // original line for the round-trip preservation
 }
 type Terminator struct { //Hasta la vista...
-	TerminatorCode string `astm:"3"` // 12.3
+	TerminatorCode TerminatorCode       `astm:"3"` // 12.3
+	Raw            astmmodels.RawRecord // original line for the round-trip preservation
 }
 
 // Message structures //
@@ -184,6 +214,10 @@ type ResultGroup struct {
 	Result   Result    `astm:"R"`
 	Comments []Comment `astm:"C,optional"`
 }
+type ScientificGroup struct {
+	Scientific Scientific `astm:"S"`
+	Comments   []Comment  `astm:"C,optional"`
+}
 type PatientOrder struct {
 	Patient Patient `astm:"P"`
 	Orders  []Order `astm:"O"`
@@ -210,3 +244,11 @@ type OrderMessage struct {
 	PatientOrders []PatientOrder
 	Terminator    Terminator `astm:"L"`
 }
+type ScientificMessage struct {
+	Header           Header `astm:"H"`
+	ScientificGroups []ScientificGroup
+	Terminator       Terminator `astm:"L"`
+}
+type ScientificMultiMessage struct {
+	ScientificMessages []ScientificMessage
+}
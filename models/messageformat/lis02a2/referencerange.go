@@ -0,0 +1,142 @@
+package lis02a2
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/krendel52/go-astm/v3/errmsg"
+)
+
+// Reference range of a result record (9.6)
+// The range is given as lower-upper (e.g. 73.5-83.5), as an open range (e.g. <5, >=10)
+// or as a qualitative normal value (e.g. N, NEG). Vendors may add a suffix as a second component.
+type ReferenceRange struct {
+	Range  string `astm:"1"`
+	Suffix string `astm:"2"`
+}
+
+// Parsed form of the reference range
+// Numeric ranges have at least one bound, qualitative ranges only have the Qualitative value set
+type ReferenceRangeBounds struct {
+	Lower          *float64
+	Upper          *float64
+	LowerInclusive bool
+	UpperInclusive bool
+	Qualitative    string
+}
+
+// Regular expressions to recognize the reference range formats
+var (
+	regexClosedRange = regexp.MustCompile(`^(-?\d+(?:[.,]\d+)?)\s*-\s*(-?\d+(?:[.,]\d+)?)$`)
+	regexOpenRange   = regexp.MustCompile(`^(<=|>=|<|>)\s*(-?\d+(?:[.,]\d+)?)$`)
+	regexNumber      = regexp.MustCompile(`^(<=|>=|<|>)?\s*(-?\d+(?:[.,]\d+)?)$`)
+)
+
+func (r ReferenceRange) IsEmpty() bool {
+	return strings.TrimSpace(r.Range) == ""
+}
+
+func (r ReferenceRange) IsNumeric() bool {
+	bounds, err := r.Bounds()
+	return err == nil && (bounds.Lower != nil || bounds.Upper != nil)
+}
+
+func (r ReferenceRange) Bounds() (bounds ReferenceRangeBounds, err error) {
+	value := strings.TrimSpace(r.Range)
+	// Empty range has no bounds
+	if value == "" {
+		return bounds, nil
+	}
+	// Closed range: lower-upper, both inclusive
+	if matches := regexClosedRange.FindStringSubmatch(value); matches != nil {
+		lower, _ := parseDecimal(matches[1])
+		upper, _ := parseDecimal(matches[2])
+		if lower > upper {
+			return ReferenceRangeBounds{}, errmsg.ErrReferenceRangeInvalidBounds
+		}
+		bounds.Lower, bounds.LowerInclusive = &lower, true
+		bounds.Upper, bounds.UpperInclusive = &upper, true
+		return bounds, nil
+	}
+	// Open range: only one of the bounds is given
+	if matches := regexOpenRange.FindStringSubmatch(value); matches != nil {
+		limit, _ := parseDecimal(matches[2])
+		switch matches[1] {
+		case "<":
+			bounds.Upper = &limit
+		case "<=":
+			bounds.Upper, bounds.UpperInclusive = &limit, true
+		case ">":
+			bounds.Lower = &limit
+		case ">=":
+			bounds.Lower, bounds.LowerInclusive = &limit, true
+		}
+		return bounds, nil
+	}
+	// Anything else is a qualitative normal value
+	bounds.Qualitative = value
+	return bounds, nil
+}
+
+// Evaluate the given result value against the range and determine the abnormal flag
+// Numeric values outside the range are flagged L or H, qualitative values not matching the normal are flagged A
+// If the value is given with a comparator (e.g. >8) and the flag can not be decided, an empty flag is returned
+func (r ReferenceRange) Evaluate(value string) (flag ResultAbnormalFlag, err error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", errmsg.ErrReferenceRangeMissingValue
+	}
+	bounds, err := r.Bounds()
+	if err != nil {
+		return "", err
+	}
+	// Without a range there is nothing to evaluate against
+	if r.IsEmpty() {
+		return "", nil
+	}
+	// Qualitative range: the value has to match the normal value
+	if bounds.Qualitative != "" {
+		if strings.EqualFold(value, bounds.Qualitative) {
+			return ResultAbnormalFlagNormal, nil
+		}
+		return ResultAbnormalFlagAbnormal, nil
+	}
+	// Numeric range: parse the value with its optional comparator
+	matches := regexNumber.FindStringSubmatch(value)
+	if matches == nil {
+		return "", errmsg.ErrReferenceRangeNonNumericValue
+	}
+	number, _ := parseDecimal(matches[2])
+	comparator := matches[1]
+	// Check the lower bound (a value only known to be above something can not be low)
+	if bounds.Lower != nil && !strings.HasPrefix(comparator, ">") {
+		if number < *bounds.Lower || (number == *bounds.Lower && !bounds.LowerInclusive) {
+			return ResultAbnormalFlagLow, nil
+		}
+	}
+	// Check the upper bound (a value only known to be below something can not be high)
+	if bounds.Upper != nil && !strings.HasPrefix(comparator, "<") {
+		if number > *bounds.Upper || (number == *bounds.Upper && !bounds.UpperInclusive) {
+			return ResultAbnormalFlagHigh, nil
+		}
+	}
+	// A comparator value inside the range can still be outside of it
+	if comparator != "" {
+		if (strings.HasPrefix(comparator, ">") && bounds.Upper != nil) ||
+			(strings.HasPrefix(comparator, "<") && bounds.Lower != nil) {
+			return "", nil
+		}
+	}
+	return ResultAbnormalFlagNormal, nil
+}
+
+// Evaluate the measurement value of the result against its reference range
+func (r Result) EvaluateAbnormalFlag() (flag ResultAbnormalFlag, err error) {
+	return r.ReferenceRange.Evaluate(r.DataMeasurementValue)
+}
+
+func parseDecimal(input string) (float64, error) {
+	// Some instruments use decimal comma (e.g. 7,41)
+	return strconv.ParseFloat(strings.ReplaceAll(input, ",", "."), 64)
+}
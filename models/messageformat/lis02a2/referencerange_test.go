@@ -0,0 +1,172 @@
+package lis02a2
+
+import (
+	"testing"
+
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReferenceRangeBounds_Closed(t *testing.T) {
+	// Arrange
+	referenceRange := ReferenceRange{Range: "73.5-83.5", Suffix: "REFERENCE_RANGE"}
+	// Act
+	bounds, err := referenceRange.Bounds()
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 73.5, *bounds.Lower)
+	assert.Equal(t, 83.5, *bounds.Upper)
+	assert.True(t, bounds.LowerInclusive)
+	assert.True(t, bounds.UpperInclusive)
+	assert.Equal(t, "", bounds.Qualitative)
+}
+
+func TestReferenceRangeBounds_NegativeLowerAndDecimalComma(t *testing.T) {
+	// Arrange
+	referenceRange := ReferenceRange{Range: "-2,5-3,5"}
+	// Act
+	bounds, err := referenceRange.Bounds()
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, -2.5, *bounds.Lower)
+	assert.Equal(t, 3.5, *bounds.Upper)
+}
+
+func TestReferenceRangeBounds_OpenUpper(t *testing.T) {
+	// Arrange
+	referenceRange := ReferenceRange{Range: "<5"}
+	// Act
+	bounds, err := referenceRange.Bounds()
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, bounds.Lower)
+	assert.Equal(t, 5.0, *bounds.Upper)
+	assert.False(t, bounds.UpperInclusive)
+}
+
+func TestReferenceRangeBounds_OpenLowerInclusive(t *testing.T) {
+	// Arrange
+	referenceRange := ReferenceRange{Range: ">=10"}
+	// Act
+	bounds, err := referenceRange.Bounds()
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 10.0, *bounds.Lower)
+	assert.True(t, bounds.LowerInclusive)
+	assert.Nil(t, bounds.Upper)
+}
+
+func TestReferenceRangeBounds_Qualitative(t *testing.T) {
+	// Arrange
+	referenceRange := ReferenceRange{Range: "N"}
+	// Act
+	bounds, err := referenceRange.Bounds()
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, bounds.Lower)
+	assert.Nil(t, bounds.Upper)
+	assert.Equal(t, "N", bounds.Qualitative)
+	assert.False(t, referenceRange.IsNumeric())
+}
+
+func TestReferenceRangeBounds_InvalidOrder(t *testing.T) {
+	// Arrange
+	referenceRange := ReferenceRange{Range: "10-5"}
+	// Act
+	_, err := referenceRange.Bounds()
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrReferenceRangeInvalidBounds)
+}
+
+func TestReferenceRangeEvaluate_Numeric(t *testing.T) {
+	// Arrange
+	referenceRange := ReferenceRange{Range: "2.95-3.35", Suffix: "REFERENCE_RANGE"}
+	// Act
+	low, errLow := referenceRange.Evaluate("2.94")
+	normalLower, errNormalLower := referenceRange.Evaluate("2.95")
+	normal, errNormal := referenceRange.Evaluate("3.22")
+	high, errHigh := referenceRange.Evaluate("3.36")
+	// Assert
+	assert.Nil(t, errLow)
+	assert.Nil(t, errNormalLower)
+	assert.Nil(t, errNormal)
+	assert.Nil(t, errHigh)
+	assert.Equal(t, ResultAbnormalFlagLow, low)
+	assert.Equal(t, ResultAbnormalFlagNormal, normalLower)
+	assert.Equal(t, ResultAbnormalFlagNormal, normal)
+	assert.Equal(t, ResultAbnormalFlagHigh, high)
+}
+
+func TestReferenceRangeEvaluate_OpenRange(t *testing.T) {
+	// Arrange
+	referenceRange := ReferenceRange{Range: "<5"}
+	// Act
+	normal, errNormal := referenceRange.Evaluate("4.9")
+	high, errHigh := referenceRange.Evaluate("5")
+	// Assert
+	assert.Nil(t, errNormal)
+	assert.Nil(t, errHigh)
+	assert.Equal(t, ResultAbnormalFlagNormal, normal)
+	assert.Equal(t, ResultAbnormalFlagHigh, high)
+}
+
+func TestReferenceRangeEvaluate_ComparatorValue(t *testing.T) {
+	// Arrange
+	referenceRange := ReferenceRange{Range: "5-10"}
+	// Act
+	high, errHigh := referenceRange.Evaluate(">12")
+	undecided, errUndecided := referenceRange.Evaluate(">8")
+	low, errLow := referenceRange.Evaluate("<3")
+	// Assert
+	assert.Nil(t, errHigh)
+	assert.Nil(t, errUndecided)
+	assert.Nil(t, errLow)
+	assert.Equal(t, ResultAbnormalFlagHigh, high)
+	assert.Equal(t, ResultAbnormalFlag(""), undecided)
+	assert.Equal(t, ResultAbnormalFlagLow, low)
+}
+
+func TestReferenceRangeEvaluate_Qualitative(t *testing.T) {
+	// Arrange
+	referenceRange := ReferenceRange{Range: "NEG"}
+	// Act
+	normal, errNormal := referenceRange.Evaluate("neg")
+	abnormal, errAbnormal := referenceRange.Evaluate("POS")
+	// Assert
+	assert.Nil(t, errNormal)
+	assert.Nil(t, errAbnormal)
+	assert.Equal(t, ResultAbnormalFlagNormal, normal)
+	assert.Equal(t, ResultAbnormalFlagAbnormal, abnormal)
+}
+
+func TestReferenceRangeEvaluate_NonNumericValue(t *testing.T) {
+	// Arrange
+	referenceRange := ReferenceRange{Range: "5-10"}
+	// Act
+	_, err := referenceRange.Evaluate("Nonreactive")
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrReferenceRangeNonNumericValue)
+}
+
+func TestReferenceRangeEvaluate_EmptyRange(t *testing.T) {
+	// Arrange
+	referenceRange := ReferenceRange{}
+	// Act
+	flag, err := referenceRange.Evaluate("5")
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, ResultAbnormalFlag(""), flag)
+}
+
+func TestResultEvaluateAbnormalFlag(t *testing.T) {
+	// Arrange
+	result := Result{
+		DataMeasurementValue: "6,77",
+		ReferenceRange:       ReferenceRange{Range: "0-5"},
+	}
+	// Act
+	flag, err := result.EvaluateAbnormalFlag()
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, ResultAbnormalFlagHigh, flag)
+}
@@ -0,0 +1,78 @@
+package yumizen
+
+import (
+	"time"
+
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+	"github.com/krendel52/go-astm/v3/streamcodec"
+)
+
+// Horiba Yumizen H550 message format declarations to use for (un)marshaling
+// Results are preceded by manufacturer records carrying histograms, matrices (scattergrams)
+// and the traceability information of the reagents used
+
+// Record substructures //
+
+// Lot of a reagent, e.g. 240415I1(^20240902000000^20241202
+type ReagentLot struct {
+	LotNumber  string    `astm:"1"`
+	OpenedAt   time.Time `astm:"2,longdate"`
+	ExpiryDate time.Time `astm:"3"`
+}
+
+// Record structures //
+
+type Stream struct {
+	StreamType string               `astm:"3"` // HISTOGRAM or MATRIX
+	Group      string               `astm:"4"` // e.g. RBC/PLT or LMNE
+	Name       string               `astm:"5"` // e.g. PltAlongRes or LMNEResAbs
+	Header     streamcodec.Stream   `astm:"6"`
+	Contents   streamcodec.Stream   `astm:"7"`
+	Raw        astmmodels.RawRecord // original line for the round-trip preservation
+}
+type Traceability struct {
+	RecordType   string               `astm:"3"` // REAGENT
+	ReagentNames []string             `astm:"4"`
+	ReagentLots  []ReagentLot         `astm:"5"` // in the same order as the names
+	Raw          astmmodels.RawRecord // original line for the round-trip preservation
+}
+
+// Message structures //
+
+type OrderGroup struct {
+	Order        lis02a2.Order `astm:"O"`
+	Histograms   []Stream      `astm:"M,subname:HISTOGRAM,optional"`
+	Matrices     []Stream      `astm:"M,subname:MATRIX,optional"`
+	Traceability Traceability  `astm:"M,subname:REAGENT,optional"`
+	ResultGroups []lis02a2.ResultGroup
+}
+type PatientGroup struct {
+	Patient     lis02a2.Patient   `astm:"P"`
+	Comments    []lis02a2.Comment `astm:"C,optional"`
+	OrderGroups []OrderGroup
+}
+
+// Messages //
+
+type ResultMessage struct {
+	Header        lis02a2.Header `astm:"H"`
+	PatientGroups []PatientGroup
+	Terminator    lis02a2.Terminator `astm:"L"`
+}
+
+// LIS02A2 returns the message in the standard structure without the manufacturer records (e.g. for the converters)
+func (m ResultMessage) LIS02A2() lis02a2.ResultMessage {
+	message := lis02a2.ResultMessage{Header: m.Header, Terminator: m.Terminator}
+	for _, patientGroup := range m.PatientGroups {
+		standardPatientGroup := lis02a2.PatientGroup{Patient: patientGroup.Patient, Comments: patientGroup.Comments}
+		for _, orderGroup := range patientGroup.OrderGroups {
+			standardPatientGroup.OrderGroups = append(standardPatientGroup.OrderGroups, lis02a2.OrderGroup{
+				Order:        orderGroup.Order,
+				ResultGroups: orderGroup.ResultGroups,
+			})
+		}
+		message.PatientGroups = append(message.PatientGroups, standardPatientGroup)
+	}
+	return message
+}
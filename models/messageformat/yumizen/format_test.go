@@ -0,0 +1,31 @@
+package yumizen
+
+import (
+	"testing"
+
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultMessageLIS02A2(t *testing.T) {
+	// Arrange
+	message := ResultMessage{
+		Header: lis02a2.Header{SenderNameOrID: "H550"},
+		PatientGroups: []PatientGroup{{
+			Patient: lis02a2.Patient{LabAssignedPatientID: "PAT-1"},
+			OrderGroups: []OrderGroup{{
+				Order:        lis02a2.Order{SpecimenID: "PX449L"},
+				Histograms:   []Stream{{StreamType: "HISTOGRAM"}},
+				Traceability: testTraceability(),
+				ResultGroups: []lis02a2.ResultGroup{{Result: lis02a2.Result{DataMeasurementValue: "78.4"}}},
+			}},
+		}},
+	}
+	// Act
+	result := message.LIS02A2()
+	// Assert
+	assert.Equal(t, "H550", result.Header.SenderNameOrID)
+	assert.Equal(t, "PAT-1", result.PatientGroups[0].Patient.LabAssignedPatientID)
+	assert.Equal(t, "PX449L", result.PatientGroups[0].OrderGroups[0].Order.SpecimenID)
+	assert.Equal(t, "78.4", result.PatientGroups[0].OrderGroups[0].ResultGroups[0].Result.DataMeasurementValue)
+}
@@ -0,0 +1,61 @@
+package yumizen
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/krendel52/go-astm/v3/errmsg"
+)
+
+// Reagent name paired with its lot from the traceability record
+type Reagent struct {
+	Name string
+	Lot  ReagentLot
+}
+
+// The expiry date is the last day the lot can be used
+func (l ReagentLot) IsExpired(at time.Time) bool {
+	if l.ExpiryDate.IsZero() {
+		return false
+	}
+	return !at.Before(l.ExpiryDate.AddDate(0, 0, 1))
+}
+
+// Pair the reagent names with their lots (names without a lot get an empty lot)
+func (t Traceability) Reagents() []Reagent {
+	reagents := make([]Reagent, len(t.ReagentNames))
+	for i, name := range t.ReagentNames {
+		reagents[i].Name = name
+		if i < len(t.ReagentLots) {
+			reagents[i].Lot = t.ReagentLots[i]
+		}
+	}
+	return reagents
+}
+
+func (t Traceability) ExpiredReagents(at time.Time) []Reagent {
+	expired := make([]Reagent, 0)
+	for _, reagent := range t.Reagents() {
+		if reagent.Lot.IsExpired(at) {
+			expired = append(expired, reagent)
+		}
+	}
+	return expired
+}
+
+// Check the traceability record for reporting: every reagent must have a lot that was not expired at the given time
+func (t Traceability) Validate(at time.Time) error {
+	if len(t.ReagentNames) != len(t.ReagentLots) {
+		return fmt.Errorf("%w: %d names, %d lots", errmsg.ErrYumizenReagentLotCountMismatch, len(t.ReagentNames), len(t.ReagentLots))
+	}
+	expired := t.ExpiredReagents(at)
+	if len(expired) > 0 {
+		names := make([]string, len(expired))
+		for i, reagent := range expired {
+			names[i] = reagent.Name + " (" + reagent.Lot.LotNumber + ")"
+		}
+		return fmt.Errorf("%w: %s", errmsg.ErrYumizenReagentLotExpired, strings.Join(names, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package yumizen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/stretchr/testify/assert"
+)
+
+func testTraceability() Traceability {
+	return Traceability{
+		RecordType:   "REAGENT",
+		ReagentNames: []string{"CLEANER", "DILUENT", "LYSE"},
+		ReagentLots: []ReagentLot{
+			{LotNumber: "240415I1(", ExpiryDate: time.Date(2024, 12, 2, 0, 0, 0, 0, time.UTC)},
+			{LotNumber: "240423H1(", ExpiryDate: time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)},
+			{LotNumber: "240411M11", ExpiryDate: time.Date(2024, 10, 28, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+}
+
+func TestTraceabilityReagents(t *testing.T) {
+	// Arrange
+	traceability := testTraceability()
+	traceability.ReagentLots = traceability.ReagentLots[:2]
+	// Act
+	reagents := traceability.Reagents()
+	// Assert
+	assert.Len(t, reagents, 3)
+	assert.Equal(t, "DILUENT", reagents[1].Name)
+	assert.Equal(t, "240423H1(", reagents[1].Lot.LotNumber)
+	assert.Equal(t, ReagentLot{}, reagents[2].Lot)
+}
+
+func TestTraceabilityValidate_Valid(t *testing.T) {
+	// Act
+	err := testTraceability().Validate(time.Date(2024, 10, 28, 23, 0, 0, 0, time.UTC))
+	// Assert
+	assert.Nil(t, err)
+}
+
+func TestTraceabilityValidate_Expired(t *testing.T) {
+	// Arrange
+	traceability := testTraceability()
+	at := time.Date(2024, 10, 29, 0, 0, 0, 0, time.UTC)
+	// Act
+	expired := traceability.ExpiredReagents(at)
+	err := traceability.Validate(at)
+	// Assert
+	assert.Len(t, expired, 1)
+	assert.Equal(t, "LYSE", expired[0].Name)
+	assert.ErrorIs(t, err, errmsg.ErrYumizenReagentLotExpired)
+	assert.EqualError(t, err, "reagent lot expired: LYSE (240411M11)")
+}
+
+func TestTraceabilityValidate_CountMismatch(t *testing.T) {
+	// Arrange
+	traceability := testTraceability()
+	traceability.ReagentNames = traceability.ReagentNames[:2]
+	// Act
+	err := traceability.Validate(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrYumizenReagentLotCountMismatch)
+}
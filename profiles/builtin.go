@@ -0,0 +1,104 @@
+package profiles
+
+import (
+	"regexp"
+
+	"github.com/blutspende/bloodlab-common/messagetype"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"github.com/krendel52/go-astm/v3/models/messageformat/biorad"
+	"github.com/krendel52/go-astm/v3/models/messageformat/euroimmun"
+	"github.com/krendel52/go-astm/v3/models/messageformat/galileo"
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+	"github.com/krendel52/go-astm/v3/models/messageformat/yumizen"
+)
+
+// Names of the built-in profiles
+const (
+	NameGalileo   string = "immucor-galileo"
+	NameBioRadIH  string = "biorad-ih-v5.2"
+	NameYumizen   string = "horiba-yumizen-h550"
+	NameEuroimmun string = "euroimmun-analyzer1-v1.0"
+)
+
+// Built-in profiles for the instruments with examples in the repository
+// Note: Euroimmun Analyzer I sends an empty header, so it is a fallback profile to only match if nothing else does
+func init() {
+	MustRegister(Galileo())
+	MustRegister(BioRadIH())
+	MustRegister(Yumizen())
+	MustRegister(Euroimmun())
+}
+
+func Galileo() Profile {
+	config := astmmodels.DefaultConfiguration
+	return Profile{
+		Name:          NameGalileo,
+		Manufacturer:  "Immucor",
+		Instrument:    "Galileo",
+		SenderName:    regexp.MustCompile(`^Echo$`),
+		Configuration: config,
+		Messages: map[messagetype.MessageType]func() interface{}{
+			messagetype.Order:  func() interface{} { return &galileo.OrderMessage{} },
+			messagetype.Result: func() interface{} { return &galileo.ResultMessage{} },
+			messagetype.Query:  func() interface{} { return &lis02a2.QueryMessage{} },
+		},
+	}
+}
+
+func BioRadIH() Profile {
+	config := astmmodels.DefaultConfiguration
+	// Patients are sent with sequence number 1 for every patient
+	config.EnforceSequenceNumberCheck = false
+	return Profile{
+		Name:          NameBioRadIH,
+		Manufacturer:  "Bio-Rad",
+		Instrument:    "IH-Com",
+		SenderName:    regexp.MustCompile(`^Bio-Rad$`),
+		SenderVersion: regexp.MustCompile(`^IH v5\.2$`),
+		// The version is sent in the field after the sender name
+		Sender: func(header lis02a2.Header) (name string, version string) {
+			name, _ = SenderComponents(1, 0)(header)
+			return name, header.SenderStreetAddress
+		},
+		Configuration: config,
+		Messages: map[messagetype.MessageType]func() interface{}{
+			messagetype.Order:  func() interface{} { return &lis02a2.OrderMessage{} },
+			messagetype.Result: func() interface{} { return &biorad.ResultMessage{} },
+			messagetype.Query:  func() interface{} { return &lis02a2.QueryMessage{} },
+		},
+	}
+}
+
+func Yumizen() Profile {
+	config := astmmodels.DefaultConfiguration
+	// Manufacturer records are numbered throughout the order, not per record type
+	config.EnforceSequenceNumberCheck = false
+	return Profile{
+		Name:         NameYumizen,
+		Manufacturer: "Horiba",
+		Instrument:   "Yumizen H550",
+		SenderName:   regexp.MustCompile(`^H550$`),
+		// The sender field is name^serial number^software version
+		Sender:        SenderComponents(1, 3),
+		Configuration: config,
+		Messages: map[messagetype.MessageType]func() interface{}{
+			messagetype.Result: func() interface{} { return &yumizen.ResultMessage{} },
+			messagetype.Query:  func() interface{} { return &lis02a2.QueryMessage{} },
+		},
+	}
+}
+
+func Euroimmun() Profile {
+	config := astmmodels.DefaultConfiguration
+	return Profile{
+		Name:          NameEuroimmun,
+		Manufacturer:  "Euroimmun",
+		Instrument:    "Analyzer I",
+		SenderName:    regexp.MustCompile(`^$`),
+		Fallback:      true,
+		Configuration: config,
+		Messages: map[messagetype.MessageType]func() interface{}{
+			messagetype.Result: func() interface{} { return &euroimmun.ResultMessage{} },
+		},
+	}
+}
@@ -0,0 +1,187 @@
+package profiles
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/blutspende/bloodlab-common/messagetype"
+	"github.com/krendel52/go-astm/v3"
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+)
+
+// Instrument profile: everything needed to communicate with a specific instrument
+type Profile struct {
+	Name          string
+	Manufacturer  string
+	Instrument    string
+	SenderName    *regexp.Regexp // matched against the sender name read from the header
+	SenderVersion *regexp.Regexp // matched against the sender version read from the header (nil matches any)
+	Sender        SenderFunc     // reads the sender name and version from the header (nil uses DefaultSender)
+	Fallback      bool           // only matched if no other profile matches the sender or the receiver (e.g. for an empty sender)
+	Configuration astmmodels.Configuration
+	Messages      map[messagetype.MessageType]func() interface{}
+}
+
+// Reads the sender name and version from an unmarshalled header
+type SenderFunc func(header lis02a2.Header) (name string, version string)
+
+// Default sender reading: name and version are the first two components of the sender field (e.g. ARCHITECT^9.45^...)
+func DefaultSender(header lis02a2.Header) (name string, version string) {
+	return SenderComponents(1, 2)(header)
+}
+
+// Sender reading from the given components of the sender field (1-based, 0 means not present)
+func SenderComponents(namePos int, versionPos int) SenderFunc {
+	return func(header lis02a2.Header) (name string, version string) {
+		components := strings.Split(header.SenderNameOrID, componentDelimiter(header))
+		if namePos > 0 && len(components) >= namePos {
+			name = components[namePos-1]
+		}
+		if versionPos > 0 && len(components) >= versionPos {
+			version = components[versionPos-1]
+		}
+		return name, version
+	}
+}
+
+func componentDelimiter(header lis02a2.Header) string {
+	if header.Delimiters.Component == "" {
+		return astmmodels.DefaultDelimiters.Component
+	}
+	return header.Delimiters.Component
+}
+
+// Check if the profile matches the given sender name and version
+func (p Profile) Matches(senderName string, senderVersion string) bool {
+	if p.SenderName == nil || !p.SenderName.MatchString(senderName) {
+		return false
+	}
+	return p.SenderVersion == nil || p.SenderVersion.MatchString(senderVersion)
+}
+
+// Create a new (empty) message structure of the given type to unmarshal into
+func (p Profile) NewMessage(messageType messagetype.MessageType) (interface{}, error) {
+	factory, exists := p.Messages[messageType]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s %s", errmsg.ErrProfileUnsupportedMessageType, p.Name, messageType)
+	}
+	return factory(), nil
+}
+
+// Identify the message type and unmarshal the message into the structure of the profile
+func (p Profile) Unmarshal(messageData []byte) (message interface{}, messageType messagetype.MessageType, err error) {
+	config := p.Configuration
+	messageType, err = astm.IdentifyMessage(messageData, config)
+	if err != nil {
+		return nil, "", err
+	}
+	message, err = p.NewMessage(messageType)
+	if err != nil {
+		return nil, messageType, err
+	}
+	err = astm.Unmarshal(messageData, message, config)
+	if err != nil {
+		return nil, messageType, err
+	}
+	return message, messageType, nil
+}
+
+func (p Profile) sender(header lis02a2.Header) (name string, version string) {
+	if p.Sender == nil {
+		return DefaultSender(header)
+	}
+	return p.Sender(header)
+}
+
+// Registry of the profiles in registration order (earlier registrations take precedence in lookups)
+var (
+	registryMutex sync.RWMutex
+	registry      []Profile
+)
+
+func Register(profile Profile) error {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	if profile.Name == "" || profile.SenderName == nil {
+		return errmsg.ErrProfileInvalid
+	}
+	for _, registered := range registry {
+		if registered.Name == profile.Name {
+			return fmt.Errorf("%w: %s", errmsg.ErrProfileAlreadyRegistered, profile.Name)
+		}
+	}
+	registry = append(registry, profile)
+	return nil
+}
+
+func MustRegister(profile Profile) {
+	if err := Register(profile); err != nil {
+		panic(err)
+	}
+}
+
+func All() []Profile {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	return append([]Profile(nil), registry...)
+}
+
+func Get(name string) (Profile, bool) {
+	for _, profile := range All() {
+		if profile.Name == name {
+			return profile, true
+		}
+	}
+	return Profile{}, false
+}
+
+// Look up the profile by sender name and version, the fallback profiles are checked last
+func Lookup(senderName string, senderVersion string) (Profile, bool) {
+	profiles := All()
+	for _, fallback := range []bool{false, true} {
+		for _, profile := range profiles {
+			if profile.Fallback == fallback && profile.Matches(senderName, senderVersion) {
+				return profile, true
+			}
+		}
+	}
+	return Profile{}, false
+}
+
+// Identify the instrument profile from the header of the message
+// The sender is checked first, then the receiver (for messages sent by the LIS to the instrument), then the sender with the fallback profiles
+func Identify(messageData []byte) (Profile, error) {
+	// Only the header is read, the rest of the message is ignored
+	var message struct {
+		Header lis02a2.Header `astm:"H"`
+	}
+	config := astmmodels.DefaultConfiguration
+	err := astm.Unmarshal(messageData, &message, config)
+	if err != nil {
+		return Profile{}, err
+	}
+	profiles := All()
+	for _, profile := range profiles {
+		if !profile.Fallback && profile.Matches(profile.sender(message.Header)) {
+			return profile, nil
+		}
+	}
+	receiver := strings.Split(message.Header.ReceiverID, componentDelimiter(message.Header))[0]
+	if receiver != "" {
+		for _, profile := range profiles {
+			if !profile.Fallback && profile.Matches(receiver, "") {
+				return profile, nil
+			}
+		}
+	}
+	for _, profile := range profiles {
+		if profile.Fallback && profile.Matches(profile.sender(message.Header)) {
+			return profile, nil
+		}
+	}
+	return Profile{}, errmsg.ErrProfileNotFound
+}
@@ -0,0 +1,409 @@
+package profiles
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/blutspende/bloodlab-common/messagetype"
+	"github.com/krendel52/go-astm/v3"
+	"github.com/krendel52/go-astm/v3/enums/jsonkeys"
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"github.com/krendel52/go-astm/v3/models/messageformat/biorad"
+	"github.com/krendel52/go-astm/v3/models/messageformat/euroimmun"
+	"github.com/krendel52/go-astm/v3/models/messageformat/galileo"
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+	"github.com/krendel52/go-astm/v3/models/messageformat/yumizen"
+	"github.com/stretchr/testify/assert"
+)
+
+// Example file reading helper
+func readExample(t *testing.T, path string) []byte {
+	data, err := os.ReadFile("../examples/" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestLookup_BuiltinProfiles(t *testing.T) {
+	// Act
+	galileoProfile, galileoOk := Lookup("Echo", "")
+	bioRadProfile, bioRadOk := Lookup("Bio-Rad", "IH v5.2")
+	_, bioRadOtherVersionOk := Lookup("Bio-Rad", "IH v6.0")
+	yumizenProfile, yumizenOk := Lookup("H550", "1.2.1.4")
+	_, unknownOk := Lookup("ARCHITECT", "9.45")
+	// Assert
+	assert.True(t, galileoOk)
+	assert.Equal(t, NameGalileo, galileoProfile.Name)
+	assert.True(t, bioRadOk)
+	assert.Equal(t, NameBioRadIH, bioRadProfile.Name)
+	assert.False(t, bioRadOtherVersionOk)
+	assert.True(t, yumizenOk)
+	assert.Equal(t, NameYumizen, yumizenProfile.Name)
+	assert.False(t, unknownOk)
+}
+
+func TestGet_BuiltinProfile(t *testing.T) {
+	// Act
+	profile, ok := Get(NameEuroimmun)
+	_, unknownOk := Get("unknown")
+	// Assert
+	assert.True(t, ok)
+	assert.Equal(t, "Euroimmun", profile.Manufacturer)
+	assert.False(t, unknownOk)
+}
+
+func TestRegister_Duplicate(t *testing.T) {
+	// Act
+	err := Register(Galileo())
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrProfileAlreadyRegistered)
+}
+
+func TestRegister_Invalid(t *testing.T) {
+	// Act
+	err := Register(Profile{Name: "no-sender"})
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrProfileInvalid)
+}
+
+func TestIdentify_Unknown(t *testing.T) {
+	// Arrange
+	message := "H|\\^&|||ARCHITECT^9.45^F3452430028^H1P1O1R1C1Q1L1|||||||P|1|20250613131519\nL|1\n"
+	// Act
+	_, err := Identify([]byte(message))
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrProfileNotFound)
+}
+
+func TestIdentify_CustomProfile(t *testing.T) {
+	// Arrange
+	registryBackup := All()
+	MustRegister(Profile{
+		Name:       "abbott-architect",
+		SenderName: regexp.MustCompile(`^ARCHITECT$`),
+		Messages: map[messagetype.MessageType]func() interface{}{
+			messagetype.Result: func() interface{} { return &lis02a2.ResultMessage{} },
+		},
+	})
+	message := "H|\\^&|||ARCHITECT^9.45^F3452430028^H1P1O1R1C1Q1L1|||||||P|1|20250613131519\nL|1\n"
+	// Act
+	profile, err := Identify([]byte(message))
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "abbott-architect", profile.Name)
+	// Teardown
+	registry = registryBackup
+}
+
+func TestIdentify_EmptySenderKnownReceiver(t *testing.T) {
+	// Arrange
+	lines, err := astm.Marshal(astm.NewOrder().Receiver("Echo").AddTest("SPEC1", "ABO", lis02a2.PriorityRoutine).Build(), astmmodels.DefaultConfiguration)
+	assert.Nil(t, err)
+	message := bytes.Join(lines, []byte("\n"))
+	// Act
+	profile, err := Identify(message)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, NameGalileo, profile.Name)
+}
+
+func TestIdentify_EmptySenderFallback(t *testing.T) {
+	// Arrange
+	message := "H|\\^&\nL|1|N\n"
+	// Act
+	profile, err := Identify([]byte(message))
+	lookupProfile, ok := Lookup("", "")
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, NameEuroimmun, profile.Name)
+	assert.True(t, ok)
+	assert.Equal(t, NameEuroimmun, lookupProfile.Name)
+}
+
+func TestIdentify_EmptySenderCustomProfile(t *testing.T) {
+	// Arrange
+	registryBackup := All()
+	MustRegister(Profile{
+		Name:       "custom-empty-sender",
+		SenderName: regexp.MustCompile(`^$`),
+	})
+	message := "H|\\^&\nL|1|N\n"
+	// Act
+	profile, err := Identify([]byte(message))
+	lookupProfile, ok := Lookup("", "")
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "custom-empty-sender", profile.Name)
+	assert.True(t, ok)
+	assert.Equal(t, "custom-empty-sender", lookupProfile.Name)
+	// Teardown
+	registry = registryBackup
+}
+
+func TestGalileoOrderExample(t *testing.T) {
+	// Arrange
+	data := readExample(t, "galileo/order.astm")
+	// Act
+	profile, err := Identify(data)
+	message, messageType, errUnmarshal := profile.Unmarshal(data)
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, errUnmarshal)
+	assert.Equal(t, NameGalileo, profile.Name)
+	assert.Equal(t, messagetype.Order, messageType)
+	order := message.(*galileo.OrderMessage)
+	assert.Equal(t, "Patient", order.PatientGroups[0].Patient.LastName)
+	assert.Equal(t, "0651439A", order.PatientGroups[0].OrderGroups[0].Order.SpecimenID)
+	assert.Equal(t, "Donor^R02460", order.PatientGroups[0].OrderGroups[0].Comments[0].CommentText)
+}
+
+func TestGalileoResultExample(t *testing.T) {
+	// Arrange
+	data := readExample(t, "galileo/result.astm")
+	// Act
+	profile, err := Identify(data)
+	message, messageType, errUnmarshal := profile.Unmarshal(data)
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, errUnmarshal)
+	assert.Equal(t, NameGalileo, profile.Name)
+	assert.Equal(t, messagetype.Result, messageType)
+	result := message.(*galileo.ResultMessage)
+	assert.Len(t, result.PatientGroups[0].OrderGroups, 2)
+	assert.Len(t, result.PatientGroups[0].OrderGroups[0].ResultGroups, 9)
+	assert.Equal(t, "B Pos", result.PatientGroups[0].OrderGroups[0].ResultGroups[8].Result.DataMeasurementValue)
+}
+
+func TestBioRadBloodtypeExample(t *testing.T) {
+	// Arrange
+	data := readExample(t, "ihcom_v52/bloodtype.astm")
+	// Act
+	profile, err := Identify(data)
+	message, messageType, errUnmarshal := profile.Unmarshal(data)
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, errUnmarshal)
+	assert.Equal(t, NameBioRadIH, profile.Name)
+	assert.Equal(t, messagetype.Result, messageType)
+	result := message.(*biorad.ResultMessage)
+	assert.Len(t, result.PatientGroups[0].OrderGroups[0].ResultGroups, 4)
+	bloodGroup, found, err := result.PatientGroups[0].OrderGroups[0].BloodGroup()
+	assert.Nil(t, err)
+	assert.True(t, found)
+	assert.Equal(t, biorad.ABOGroupA, bloodGroup.ABO)
+	assert.Equal(t, biorad.ReactionNegative, bloodGroup.RhD)
+}
+
+func TestBioRadBloodtypeMultiPatientExample(t *testing.T) {
+	// Arrange
+	data := readExample(t, "ihcom_v52/bloodtype_por.astm")
+	// Act
+	profile, err := Identify(data)
+	message, _, errUnmarshal := profile.Unmarshal(data)
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, errUnmarshal)
+	result := message.(*biorad.ResultMessage)
+	assert.Len(t, result.PatientGroups, 2)
+	assert.Equal(t, "Testis", result.PatientGroups[1].Patient.LastName)
+}
+
+func TestBioRadBloodtypeMultiPatientExampleFlatten(t *testing.T) {
+	// Arrange
+	data := readExample(t, "ihcom_v52/bloodtype_por.astm")
+	message, _, err := BioRadIH().Unmarshal(data)
+	assert.Nil(t, err)
+	// Act
+	rows := message.(*biorad.ResultMessage).Flatten()
+	groups := lis02a2.GroupBySpecimen(rows)
+	// Assert
+	assert.Len(t, rows, 5)
+	assert.Equal(t, "Testus", rows[0].Patient.LastName)
+	assert.Equal(t, "AntiA", rows[0].TestID)
+	assert.Equal(t, "40", rows[0].Value)
+	assert.Equal(t, "4", rows[0].Reagents[0].Cassette.Column)
+	assert.Equal(t, biorad.InterpretationTestType, rows[3].TestID)
+	assert.Equal(t, "ccee", rows[3].ResultValue.RhPhenotype)
+	assert.Equal(t, "Testis", rows[4].Patient.LastName)
+	assert.Len(t, groups, 1)
+	assert.Equal(t, "1122206642", groups[0].SpecimenID)
+	assert.Len(t, groups[0].Rows, 5)
+}
+
+func TestYumizenResultExample(t *testing.T) {
+	// Arrange
+	// The original capture (result.astm) has doubled backslashes, so it does not parse
+	data := readExample(t, "yumizen/result_corrected.astm")
+	// Act
+	profile, err := Identify(data)
+	message, messageType, errUnmarshal := profile.Unmarshal(data)
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, errUnmarshal)
+	assert.Equal(t, NameYumizen, profile.Name)
+	assert.Equal(t, messagetype.Result, messageType)
+	result := message.(*yumizen.ResultMessage)
+	orderGroup := result.PatientGroups[0].OrderGroups[0]
+	assert.Equal(t, "PX449L", orderGroup.Order.SpecimenID)
+	assert.Len(t, orderGroup.Histograms, 1)
+	assert.Len(t, orderGroup.Matrices, 1)
+	assert.Equal(t, []string{"CLEANER", "DILUENT", "LYSE"}, orderGroup.Traceability.ReagentNames)
+	assert.Len(t, orderGroup.Traceability.ReagentLots, 3)
+	assert.Equal(t, "423H1(", orderGroup.Traceability.ReagentLots[1].LotNumber)
+	assert.Len(t, orderGroup.ResultGroups, 20)
+	header, errHeader := orderGroup.Histograms[0].Header.Float32()
+	assert.Nil(t, errHeader)
+	assert.Len(t, header, 12)
+}
+
+func TestEuroimmunResultExample(t *testing.T) {
+	// Arrange
+	data := readExample(t, "euroimmun_analyzer1_v10/sampleigg.astm")
+	// Act
+	profile, err := Identify(data)
+	message, messageType, errUnmarshal := profile.Unmarshal(data)
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, errUnmarshal)
+	assert.Equal(t, NameEuroimmun, profile.Name)
+	assert.Equal(t, messagetype.Result, messageType)
+	result := message.(*euroimmun.ResultMessage)
+	assert.Len(t, result.PatientGroups, 20)
+	assert.Equal(t, "TEST-27-079-5-1", result.PatientGroups[0].Patient.LabAssignedPatientID)
+	assert.Equal(t, "7,41", result.PatientGroups[1].OrderGroups[0].ResultGroups[0].Result.DataMeasurementValue)
+	// Check the vendor padding is trimmed
+	assert.Equal(t, "TEST-27-026-5-1  57", result.PatientGroups[14].Patient.LabAssignedPatientID)
+	// Check the standard form keeps the patients and results
+	standard := result.LIS02A2()
+	assert.Len(t, standard.PatientGroups, 20)
+	assert.Equal(t, "TEST-27-026-5-1  57", standard.PatientGroups[14].Patient.LabAssignedPatientID)
+	assert.Equal(t, "7,41", standard.PatientGroups[1].OrderGroups[0].ResultGroups[0].Result.DataMeasurementValue)
+}
+
+func TestEuroimmunPatientFields(t *testing.T) {
+	// Arrange
+	data := "H|\\^&|||\nP|1|PRACTICE-1  |LAB-1  |  ID3|Doe^Jane||19800102|F\nL|1|N\n"
+	var message euroimmun.ResultMessage
+	// Act
+	err := astm.Unmarshal([]byte(data), &message, Euroimmun().Configuration)
+	// Assert
+	assert.Nil(t, err)
+	patient := message.PatientGroups[0].Patient
+	assert.Equal(t, "PRACTICE-1", patient.PracticeAssignedPatientID)
+	assert.Equal(t, "LAB-1", patient.LabAssignedPatientID)
+	assert.Equal(t, "ID3", patient.ID3)
+	assert.Equal(t, "Doe", patient.LastName)
+	assert.Equal(t, "Jane", patient.FirstName)
+	assert.Equal(t, 1980, patient.DOB.Year())
+	assert.Equal(t, "F", patient.Gender)
+	assert.Equal(t, "Jane", message.LIS02A2().PatientGroups[0].Patient.FirstName)
+}
+
+func TestRoundTripPreservation_Examples(t *testing.T) {
+	// Arrange
+	examples := map[string]messagetype.MessageType{
+		"euroimmun_analyzer1_v10/sampleigg.astm": messagetype.Result,
+		"galileo/order.astm":                     messagetype.Order,
+		"galileo/result.astm":                    messagetype.Result,
+		"ihcom_v52/bloodtype.astm":               messagetype.Result,
+		"ihcom_v52/bloodtype_por.astm":           messagetype.Result,
+		"yumizen/result_corrected.astm":          messagetype.Result,
+	}
+	for path, messageType := range examples {
+		data := readExample(t, path)
+		profile, err := Identify(data)
+		assert.Nil(t, err, path)
+		config := profile.Configuration
+		config.PreserveRawRecords = true
+		message, err := profile.NewMessage(messageType)
+		assert.Nil(t, err, path)
+		// Act
+		errUnmarshal := astm.Unmarshal(data, message, config)
+		lines, errMarshal := astm.Marshal(message, config)
+		// Assert
+		assert.Nil(t, errUnmarshal, path)
+		assert.Nil(t, errMarshal, path)
+		expected := strings.Split(strings.TrimRight(strings.ReplaceAll(string(data), "\r", ""), "\n"), "\n")
+		actual := make([]string, len(lines))
+		for i, line := range lines {
+			actual[i] = string(line)
+		}
+		assert.Equal(t, expected, actual, path)
+	}
+}
+
+func TestJSONRoundTrip_Examples(t *testing.T) {
+	// Arrange
+	examples := map[string]messagetype.MessageType{
+		"euroimmun_analyzer1_v10/sampleigg.astm": messagetype.Result,
+		"galileo/order.astm":                     messagetype.Order,
+		"galileo/result.astm":                    messagetype.Result,
+		"ihcom_v52/bloodtype.astm":               messagetype.Result,
+		"ihcom_v52/bloodtype_por.astm":           messagetype.Result,
+		"yumizen/result_corrected.astm":          messagetype.Result,
+	}
+	for path, messageType := range examples {
+		for _, keys := range []string{jsonkeys.FieldNames, jsonkeys.Positions} {
+			data := readExample(t, path)
+			profile, err := Identify(data)
+			assert.Nil(t, err, path)
+			config := profile.Configuration
+			config.JSONKeys = keys
+			message, _ := profile.NewMessage(messageType)
+			converted, _ := profile.NewMessage(messageType)
+			assert.Nil(t, astm.Unmarshal(data, message, config), path)
+			// Act
+			jsonData, errToJSON := astm.ToJSON(message, config)
+			errFromJSON := astm.FromJSON(jsonData, converted, config)
+			// Assert
+			assert.Nil(t, errToJSON, path)
+			assert.Nil(t, errFromJSON, path)
+			expected, _ := astm.Marshal(message, config)
+			actual, errMarshal := astm.Marshal(converted, config)
+			assert.Nil(t, errMarshal, path)
+			assert.Equal(t, expected, actual, path+" "+keys)
+		}
+	}
+}
+
+func TestGenericRoundTrip_Examples(t *testing.T) {
+	// Arrange
+	examples := []string{
+		"euroimmun_analyzer1_v10/sampleigg.astm",
+		"galileo/order.astm",
+		"galileo/result.astm",
+		"ihcom_v52/bloodtype.astm",
+		"ihcom_v52/bloodtype_por.astm",
+		"yumizen/result_corrected.astm",
+	}
+	for _, path := range examples {
+		data := readExample(t, path)
+		// Act
+		records, errUnmarshal := astm.UnmarshalGeneric(data)
+		jsonData, errToJSON := json.Marshal(records)
+		var converted []astmmodels.GenericRecord
+		errFromJSON := json.Unmarshal(jsonData, &converted)
+		lines, errMarshal := astm.MarshalGeneric(converted)
+		// Assert
+		assert.Nil(t, errUnmarshal, path)
+		assert.Nil(t, errToJSON, path)
+		assert.Nil(t, errFromJSON, path)
+		assert.Nil(t, errMarshal, path)
+		// Note: the padding of the lines is not kept
+		expected := strings.Split(strings.TrimRight(strings.ReplaceAll(string(data), "\r", ""), "\n"), "\n")
+		for i := range expected {
+			expected[i] = strings.Trim(expected[i], " ")
+		}
+		actual := make([]string, len(lines))
+		for i, line := range lines {
+			actual[i] = string(line)
+		}
+		assert.Equal(t, expected, actual, path)
+	}
+}
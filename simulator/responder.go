@@ -0,0 +1,70 @@
+package simulator
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/blutspende/bloodlab-common/messagetype"
+	"github.com/krendel52/go-astm/v3"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+	"github.com/krendel52/go-astm/v3/models/messageformat/lis02a2"
+)
+
+// Responder answering the orders with a result message, like an instrument after measuring the specimens
+// The values are looked up by the test code of the order (manufacturer's test type), tests without a value get an empty result
+// The other messages are not answered
+func ResultResponder(values map[string]string, configuration ...astmmodels.Configuration) Responder {
+	return func(message []byte) ([]byte, error) {
+		messageType, err := astm.IdentifyMessage(message, configuration...)
+		if err != nil || messageType != messagetype.Order {
+			return nil, err
+		}
+		var orderMessage lis02a2.OrderMessage
+		if err = astm.Unmarshal(message, &orderMessage, configuration...); err != nil {
+			return nil, err
+		}
+		lines, err := astm.Marshal(results(orderMessage, values, time.Now()), configuration...)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.Join(lines, []byte("\n")), nil
+	}
+}
+
+// Result message without the manufacturer record
+type resultMessage struct {
+	Header        lis02a2.Header `astm:"H"`
+	PatientGroups []lis02a2.PatientGroup
+	Terminator    lis02a2.Terminator `astm:"L"`
+}
+
+func results(orderMessage lis02a2.OrderMessage, values map[string]string, completed time.Time) resultMessage {
+	message := resultMessage{
+		Header: lis02a2.Header{
+			SenderNameOrID: orderMessage.Header.ReceiverID,
+			ReceiverID:     orderMessage.Header.SenderNameOrID,
+			ProcessingID:   lis02a2.ProcessingIDProduction,
+			Version:        astm.BuilderVersion,
+			DateAndTime:    completed,
+		},
+		Terminator: lis02a2.Terminator{TerminatorCode: lis02a2.TerminatorCodeNormal},
+	}
+	for _, patientOrder := range orderMessage.PatientOrders {
+		patientGroup := lis02a2.PatientGroup{Patient: patientOrder.Patient}
+		for _, order := range patientOrder.Orders {
+			order.ReportType = lis02a2.ReportTypeFinal
+			testCode := order.UniversalTestID.ManufacturersTestType
+			patientGroup.OrderGroups = append(patientGroup.OrderGroups, lis02a2.OrderGroup{
+				Order: order,
+				ResultGroups: []lis02a2.ResultGroup{{Result: lis02a2.Result{
+					UniversalTestID:      lis02a2.ExtendedUniversalTestID{ManufacturersTestType: testCode},
+					DataMeasurementValue: values[testCode],
+					ResultStatus:         lis02a2.ResultStatusFinal,
+					DateTimeCompleted:    completed,
+				}}},
+			})
+		}
+		message.PatientGroups = append(message.PatientGroups, patientGroup)
+	}
+	return message
+}
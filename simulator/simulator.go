@@ -0,0 +1,181 @@
+package simulator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"github.com/blutspende/bloodlab-common/messagetype"
+	"github.com/krendel52/go-astm/v3"
+	"github.com/krendel52/go-astm/v3/enums/lineseparator"
+	"github.com/krendel52/go-astm/v3/enums/lis1arole"
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/lis1a"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+)
+
+// Programmable fake instrument for testing a LIS without hardware
+// After connecting it replays its messages (e.g. the .astm files of the examples) over LIS1-A, waiting for the answer of every query,
+// then it receives the messages of the LIS and answers them with the Responder until the connection is closed
+type Simulator struct {
+	Messages          [][]byte     // replayed in order after connecting
+	Responder         Responder    // answers the received messages (nil answers nothing)
+	Faults            lis1a.Faults // injected in every connection
+	Timing            lis1a.Timing
+	ContentionMessage []byte                   // sent after a contention (nil sends the last sent message, or an empty message)
+	OnReceive         func(message []byte)     // called with every received message (nil ignores them)
+	ErrorHandler      func(err error)          // called with the interrupted transfers and the errors of the connections in Serve (nil ignores them)
+	Configuration     astmmodels.Configuration // for identifying the messages, the received records are joined with its line separator
+}
+
+// Answers a received message, a nil answer sends nothing
+type Responder func(message []byte) (answer []byte, err error)
+
+func New(messages ...[]byte) *Simulator {
+	return &Simulator{
+		Messages:      messages,
+		Timing:        lis1a.DefaultTiming(lis1arole.Instrument),
+		Configuration: astmmodels.DefaultConfiguration,
+	}
+}
+
+// Connect to the LIS and run the session until the LIS closes the connection or the context is cancelled
+func (s *Simulator) Dial(ctx context.Context, address string) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return s.Run(ctx, conn)
+}
+
+// Accept the connections of the LIS one after the other until the context is cancelled
+func (s *Simulator) Serve(ctx context.Context, listener net.Listener) error {
+	stop := context.AfterFunc(ctx, func() { _ = listener.Close() })
+	defer stop()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		err = s.Run(ctx, conn)
+		_ = conn.Close()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			s.handleError(err)
+		}
+	}
+}
+
+func (s *Simulator) handleError(err error) {
+	if s.ErrorHandler != nil {
+		s.ErrorHandler(err)
+	}
+}
+
+// Run the session on an established connection, it returns nil when the LIS closes the connection
+func (s *Simulator) Run(ctx context.Context, conn net.Conn) error {
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+	link := lis1a.NewConn(conn, lis1arole.Instrument)
+	link.Timing = s.Timing
+	link.Faults = s.Faults
+	session := session{simulator: s, link: link, lastSent: s.ContentionMessage}
+
+	// Replay the messages
+	for _, message := range s.Messages {
+		if err := session.send(message); err != nil {
+			return contextError(ctx, err)
+		}
+		// The LIS answers a query with the orders
+		if messageType, _ := astm.IdentifyMessage(message, s.Configuration); messageType == messagetype.Query {
+			if err := session.receive(); err != nil {
+				return contextError(ctx, err)
+			}
+		}
+	}
+	// Answer the messages of the LIS
+	for {
+		if err := session.receive(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return contextError(ctx, err)
+		}
+	}
+}
+
+type session struct {
+	simulator *Simulator
+	link      *lis1a.Conn
+	lastSent  []byte
+}
+
+func (s *session) send(message []byte) error {
+	if s.simulator.ContentionMessage == nil {
+		s.lastSent = message
+	}
+	return s.link.Send(splitRecords(message))
+}
+
+// Receive a message and send the answer of the responder
+// After a contention the instrument has priority: it sends its message and receives the one of the LIS afterwards
+func (s *session) receive() error {
+	records, err := s.link.Receive()
+	if errors.Is(err, errmsg.ErrLIS1AContention) {
+		time.Sleep(s.link.Timing.ContentionDelay)
+		if err = s.link.Send(splitRecords(s.lastSent)); err != nil {
+			return err
+		}
+		return s.receive()
+	}
+	if errors.Is(err, errmsg.ErrLIS1ATransferInterrupted) {
+		// The LIS sends the message again
+		s.simulator.handleError(err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	message := bytes.Join(records, []byte(s.lineSeparator()))
+	if s.simulator.OnReceive != nil {
+		s.simulator.OnReceive(message)
+	}
+	if s.simulator.Responder == nil {
+		return nil
+	}
+	answer, err := s.simulator.Responder(message)
+	if err != nil || answer == nil {
+		return err
+	}
+	return s.send(answer)
+}
+
+func (s *session) lineSeparator() string {
+	if s.simulator.Configuration.LineSeparator == "" {
+		return lineseparator.LF
+	}
+	return s.simulator.Configuration.LineSeparator
+}
+
+// Split a message into its records at any line separator (empty lines are dropped)
+func splitRecords(message []byte) [][]byte {
+	return bytes.FieldsFunc(message, func(r rune) bool { return r == '\r' || r == '\n' })
+}
+
+// The cancellation of the context closes the connection, its error is reported instead of the one of the closed connection
+func contextError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
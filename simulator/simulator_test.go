@@ -0,0 +1,231 @@
+package simulator
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/krendel52/go-astm/v3/enums/lis1arole"
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/krendel52/go-astm/v3/lis1a"
+	"github.com/stretchr/testify/assert"
+)
+
+var testTiming = lis1a.Timing{
+	ReplyTimeout:    500 * time.Millisecond,
+	ReceiveTimeout:  time.Second,
+	BusyDelay:       10 * time.Millisecond,
+	ContentionDelay: 50 * time.Millisecond,
+	MaxAttempts:     6,
+}
+
+const testOrder = "H|\\^&|||LIS|||||Analyzer||P|LIS2-A2|20240709103536\n" +
+	"P|1||P1\n" +
+	"O|1|S1||^^^TSH|R||||||N\n" +
+	"O|2|S1||^^^FT4|R||||||N\n" +
+	"L|1|N"
+
+const testQuery = "H|\\^&|||Analyzer|||||LIS||P|LIS2-A2|20240709103536\n" +
+	"Q|1|^S1||^^^ALL||||||||O\n" +
+	"L|1|N"
+
+// Start the simulator connecting to a LIS on localhost and return the connection of the LIS side
+func startSimulator(t *testing.T, simulator *Simulator) (lis *lis1a.Conn, done chan error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	simulator.Timing = testTiming
+	done = make(chan error, 1)
+	go func() {
+		done <- simulator.Dial(context.Background(), listener.Addr().String())
+	}()
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis = lis1a.NewConn(conn, lis1arole.Computer)
+	lis.Timing = testTiming
+	t.Cleanup(func() { lis.Close() })
+	return lis, done
+}
+
+func TestSimulatorReplaysExample(t *testing.T) {
+	// Arrange
+	example, err := os.ReadFile("../examples/ihcom_v52/bloodtype.astm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis, done := startSimulator(t, New(example))
+	// Act
+	records, err := lis.Receive()
+	lis.Close()
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Split(strings.TrimRight(strings.ReplaceAll(string(example), "\r", ""), "\n"), "\n"), recordStrings(records))
+	assert.Nil(t, <-done)
+}
+
+func TestSimulatorAnswersOrdersWithResults(t *testing.T) {
+	// Arrange
+	simulator := New()
+	simulator.Responder = ResultResponder(map[string]string{"TSH": "7.41"})
+	var received [][]byte
+	simulator.OnReceive = func(message []byte) { received = append(received, message) }
+	lis, done := startSimulator(t, simulator)
+	// Act
+	err := lis.Send(splitRecords([]byte(testOrder)))
+	records, errReceive := lis.Receive()
+	lis.Close()
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, errReceive)
+	assert.Nil(t, <-done)
+	assert.Equal(t, [][]byte{[]byte(testOrder)}, received)
+	lines := recordStrings(records)
+	assert.Len(t, lines, 7)
+	assert.True(t, strings.HasPrefix(lines[0], "H|\\^&|||Analyzer|||||LIS||P|LIS2-A2|"))
+	assert.True(t, strings.HasPrefix(lines[2], "O|1|S1||^^^TSH|R||||||N|"))
+	assert.True(t, strings.HasPrefix(lines[3], "R|1|^^^TSH^^^|7.41^^|||||F|"))
+	assert.True(t, strings.HasPrefix(lines[5], "R|1|^^^FT4^^^|^^|||||F|"))
+	assert.Equal(t, "L|1|N", lines[6])
+}
+
+func TestSimulatorWaitsForTheAnswerOfAQuery(t *testing.T) {
+	// Arrange
+	simulator := New([]byte(testQuery))
+	simulator.Responder = ResultResponder(nil)
+	lis, done := startSimulator(t, simulator)
+	// Act
+	query, err := lis.Receive()
+	errSend := lis.Send(splitRecords([]byte(testOrder)))
+	results, errReceive := lis.Receive()
+	lis.Close()
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, errSend)
+	assert.Nil(t, errReceive)
+	assert.Nil(t, <-done)
+	assert.Equal(t, "Q|1|^S1||^^^ALL||||||||O", string(query[1]))
+	assert.Len(t, results, 7)
+}
+
+func TestSimulatorFaults(t *testing.T) {
+	// Arrange
+	simulator := New([]byte(testOrder))
+	simulator.Faults = lis1a.Faults{BadChecksums: 2, NAKs: 2}
+	lis, done := startSimulator(t, simulator)
+	// Act
+	records, err := lis.Receive()
+	errSend := lis.Send(splitRecords([]byte(testQuery)))
+	lis.Close()
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, errSend)
+	assert.Nil(t, <-done)
+	assert.Equal(t, testOrder, string(bytes.Join(records, []byte("\n"))))
+}
+
+func TestSimulatorDroppedEOT(t *testing.T) {
+	// Arrange
+	simulator := New([]byte(testQuery))
+	simulator.Faults = lis1a.Faults{DroppedEOTs: 1}
+	lis, _ := startSimulator(t, simulator)
+	// Act
+	records, err := lis.Receive()
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLIS1ATimeout)
+	assert.Len(t, records, 3)
+}
+
+func TestSimulatorUnansweredFrame(t *testing.T) {
+	// Arrange
+	simulator := New()
+	simulator.Faults = lis1a.Faults{Timeouts: 1}
+	var errs []error
+	simulator.ErrorHandler = func(err error) { errs = append(errs, err) }
+	var received [][]byte
+	simulator.OnReceive = func(message []byte) { received = append(received, message) }
+	lis, done := startSimulator(t, simulator)
+	// Act
+	err := lis.Send(splitRecords([]byte(testQuery)))
+	errAgain := lis.Send(splitRecords([]byte(testQuery)))
+	lis.Close()
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLIS1ATimeout)
+	assert.Nil(t, errAgain)
+	assert.Nil(t, <-done)
+	assert.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], errmsg.ErrLIS1ATransferInterrupted)
+	assert.Equal(t, [][]byte{[]byte(testQuery)}, received)
+}
+
+func TestSimulatorContention(t *testing.T) {
+	// Arrange
+	simulator := New()
+	simulator.Faults = lis1a.Faults{Contentions: 1}
+	simulator.ContentionMessage = []byte(testQuery)
+	simulator.Responder = ResultResponder(nil)
+	lis, done := startSimulator(t, simulator)
+	// Act
+	err := lis.Send(splitRecords([]byte(testOrder)))
+	// The computer system yields and receives the message of the instrument before sending again
+	query, errReceive := lis.Receive()
+	errSend := lis.Send(splitRecords([]byte(testOrder)))
+	results, errResults := lis.Receive()
+	lis.Close()
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrLIS1AContention)
+	assert.Nil(t, errReceive)
+	assert.Equal(t, testQuery, string(bytes.Join(query, []byte("\n"))))
+	assert.Nil(t, errSend)
+	assert.Nil(t, errResults)
+	assert.Len(t, results, 7)
+	assert.Nil(t, <-done)
+}
+
+func TestSimulatorServeUntilCancelled(t *testing.T) {
+	// Arrange
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	simulator := New([]byte(testQuery))
+	simulator.Timing = testTiming
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- simulator.Serve(ctx, listener)
+	}()
+	// Act
+	var messages [][]string
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		lis := lis1a.NewConn(conn, lis1arole.Computer)
+		lis.Timing = testTiming
+		records, _ := lis.Receive()
+		messages = append(messages, recordStrings(records))
+		lis.Close()
+	}
+	cancel()
+	// Assert
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Len(t, messages, 2)
+	assert.Equal(t, messages[0], messages[1])
+}
+
+func recordStrings(records [][]byte) []string {
+	lines := make([]string, len(records))
+	for i, record := range records {
+		lines[i] = string(record)
+	}
+	return lines
+}
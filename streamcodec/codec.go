@@ -0,0 +1,279 @@
+package streamcodec
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/krendel52/go-astm/v3/errmsg"
+)
+
+// Maximum number of values decompressed from a stream, a small compressed payload must not exhaust the memory
+// (the Yumizen histograms have a few hundred values, the matrices a few ten thousands)
+const MaxStreamValues = 1 << 20
+
+// Deflate (also inside zlib and gzip) can not compress the data by more than this ratio
+const maxCompressionRatio = 1032
+
+// Stream substructure: a componented field with the descriptor and the encoded data
+// e.g. FLOATLE-stream/deflate:base64^Y2AAggf/XRjgtIMDiAkA
+type Stream struct {
+	Descriptor string `astm:"1"`
+	Data       string `astm:"2"`
+}
+
+func (s Stream) IsEmpty() bool {
+	return s.Descriptor == "" && s.Data == ""
+}
+
+// Decode the stream data into numeric values of any supported data type
+func (s Stream) Values() ([]float64, error) {
+	return DecodeValues(s.Descriptor, s.Data)
+}
+
+// Decode the stream data into float32 values (FLOATLE or FLOATBE data type)
+func (s Stream) Float32() ([]float32, error) {
+	return DecodeFloat32(s.Descriptor, s.Data)
+}
+
+func NewStream(descriptor string, values []float64) (Stream, error) {
+	data, err := EncodeValues(descriptor, values)
+	if err != nil {
+		return Stream{}, err
+	}
+	return Stream{Descriptor: descriptor, Data: data}, nil
+}
+
+func NewFloat32Stream(descriptor string, values []float32) (Stream, error) {
+	data, err := EncodeFloat32(descriptor, values)
+	if err != nil {
+		return Stream{}, err
+	}
+	return Stream{Descriptor: descriptor, Data: data}, nil
+}
+
+func DecodeValues(descriptor string, data string) (values []float64, err error) {
+	// Parse the descriptor and get the raw bytes
+	parsedDescriptor, err := ParseDescriptor(descriptor)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := DecodeBytes(parsedDescriptor, data)
+	if err != nil {
+		return nil, err
+	}
+	// Convert the raw bytes into values
+	size := dataTypeSizes[parsedDescriptor.DataType]
+	if len(raw)%size != 0 {
+		return nil, fmt.Errorf("%w: %d bytes are not a multiple of the value size %d", errmsg.ErrStreamCodecInvalidDataLength, len(raw), size)
+	}
+	values = make([]float64, len(raw)/size)
+	for i := range values {
+		values[i] = readValue(raw[i*size:(i+1)*size], parsedDescriptor.DataType)
+	}
+	return values, nil
+}
+
+func DecodeFloat32(descriptor string, data string) (values []float32, err error) {
+	// Parse the descriptor and make sure it is a float stream
+	parsedDescriptor, err := ParseDescriptor(descriptor)
+	if err != nil {
+		return nil, err
+	}
+	if parsedDescriptor.DataType != DataTypeFloatLE && parsedDescriptor.DataType != DataTypeFloatBE {
+		return nil, fmt.Errorf("%w: %q", errmsg.ErrStreamCodecDataTypeMismatch, parsedDescriptor.DataType)
+	}
+	// Decode as generic values and convert them back (float32 to float64 conversion is lossless)
+	decoded, err := DecodeValues(descriptor, data)
+	if err != nil {
+		return nil, err
+	}
+	values = make([]float32, len(decoded))
+	for i, value := range decoded {
+		values[i] = float32(value)
+	}
+	return values, nil
+}
+
+func EncodeValues(descriptor string, values []float64) (data string, err error) {
+	// Parse the descriptor
+	parsedDescriptor, err := ParseDescriptor(descriptor)
+	if err != nil {
+		return "", err
+	}
+	// Convert the values into raw bytes
+	size := dataTypeSizes[parsedDescriptor.DataType]
+	raw := make([]byte, len(values)*size)
+	for i, value := range values {
+		writeValue(raw[i*size:(i+1)*size], parsedDescriptor.DataType, value)
+	}
+	// Compress and encode the raw bytes
+	return EncodeBytes(parsedDescriptor, raw)
+}
+
+func EncodeFloat32(descriptor string, values []float32) (data string, err error) {
+	converted := make([]float64, len(values))
+	for i, value := range values {
+		converted[i] = float64(value)
+	}
+	return EncodeValues(descriptor, converted)
+}
+
+func DecodeBytes(descriptor Descriptor, data string) (raw []byte, err error) {
+	// Decode the text representation
+	var compressed []byte
+	switch descriptor.Encoding {
+	case EncodingBase64:
+		compressed, err = base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+	case EncodingHex:
+		compressed, err = hex.DecodeString(strings.TrimSpace(data))
+	default:
+		return nil, errmsg.ErrStreamCodecUnsupportedEncoding
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errmsg.ErrStreamCodecDecodingError, err.Error())
+	}
+	// Decompress the data
+	var reader io.ReadCloser
+	switch descriptor.Compression {
+	case CompressionNone:
+		return compressed, nil
+	case CompressionDeflate:
+		reader = flate.NewReader(bytes.NewReader(compressed))
+	case CompressionZlib:
+		reader, err = zlib.NewReader(bytes.NewReader(compressed))
+	case CompressionGzip:
+		reader, err = gzip.NewReader(bytes.NewReader(compressed))
+	default:
+		return nil, errmsg.ErrStreamCodecUnsupportedCompression
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errmsg.ErrStreamCodecDecodingError, err.Error())
+	}
+	defer reader.Close()
+	// Limit the decompressed size to what the data can hold and to the maximum number of values
+	limit := int64(len(compressed)) * maxCompressionRatio
+	if maxLength := int64(MaxStreamValues * dataTypeSizes[descriptor.DataType]); maxLength < limit {
+		limit = maxLength
+	}
+	raw, err = io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errmsg.ErrStreamCodecDecodingError, err.Error())
+	}
+	if int64(len(raw)) > limit {
+		return nil, fmt.Errorf("%w: decompressed data exceeds %d bytes", errmsg.ErrStreamCodecInvalidDataLength, limit)
+	}
+	return raw, nil
+}
+
+func EncodeBytes(descriptor Descriptor, raw []byte) (data string, err error) {
+	// Compress the data
+	var buffer bytes.Buffer
+	var writer io.WriteCloser
+	switch descriptor.Compression {
+	case CompressionNone:
+		buffer.Write(raw)
+	case CompressionDeflate:
+		writer, err = flate.NewWriter(&buffer, flate.BestCompression)
+	case CompressionZlib:
+		writer, err = zlib.NewWriterLevel(&buffer, zlib.BestCompression)
+	case CompressionGzip:
+		writer, err = gzip.NewWriterLevel(&buffer, gzip.BestCompression)
+	default:
+		return "", errmsg.ErrStreamCodecUnsupportedCompression
+	}
+	if err != nil {
+		return "", err
+	}
+	if writer != nil {
+		if _, err = writer.Write(raw); err != nil {
+			return "", err
+		}
+		if err = writer.Close(); err != nil {
+			return "", err
+		}
+	}
+	// Encode the text representation
+	switch descriptor.Encoding {
+	case EncodingBase64:
+		return base64.StdEncoding.EncodeToString(buffer.Bytes()), nil
+	case EncodingHex:
+		return strings.ToUpper(hex.EncodeToString(buffer.Bytes())), nil
+	}
+	return "", errmsg.ErrStreamCodecUnsupportedEncoding
+}
+
+func readValue(raw []byte, dataType DataType) float64 {
+	switch dataType {
+	case DataTypeFloatLE:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(raw)))
+	case DataTypeFloatBE:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(raw)))
+	case DataTypeDoubleLE:
+		return math.Float64frombits(binary.LittleEndian.Uint64(raw))
+	case DataTypeDoubleBE:
+		return math.Float64frombits(binary.BigEndian.Uint64(raw))
+	case DataTypeInt8:
+		return float64(int8(raw[0]))
+	case DataTypeUint8:
+		return float64(raw[0])
+	case DataTypeInt16LE:
+		return float64(int16(binary.LittleEndian.Uint16(raw)))
+	case DataTypeInt16BE:
+		return float64(int16(binary.BigEndian.Uint16(raw)))
+	case DataTypeUint16LE:
+		return float64(binary.LittleEndian.Uint16(raw))
+	case DataTypeUint16BE:
+		return float64(binary.BigEndian.Uint16(raw))
+	case DataTypeInt32LE:
+		return float64(int32(binary.LittleEndian.Uint32(raw)))
+	case DataTypeInt32BE:
+		return float64(int32(binary.BigEndian.Uint32(raw)))
+	case DataTypeUint32LE:
+		return float64(binary.LittleEndian.Uint32(raw))
+	case DataTypeUint32BE:
+		return float64(binary.BigEndian.Uint32(raw))
+	}
+	return 0
+}
+
+func writeValue(raw []byte, dataType DataType, value float64) {
+	switch dataType {
+	case DataTypeFloatLE:
+		binary.LittleEndian.PutUint32(raw, math.Float32bits(float32(value)))
+	case DataTypeFloatBE:
+		binary.BigEndian.PutUint32(raw, math.Float32bits(float32(value)))
+	case DataTypeDoubleLE:
+		binary.LittleEndian.PutUint64(raw, math.Float64bits(value))
+	case DataTypeDoubleBE:
+		binary.BigEndian.PutUint64(raw, math.Float64bits(value))
+	case DataTypeInt8:
+		raw[0] = byte(int8(value))
+	case DataTypeUint8:
+		raw[0] = uint8(value)
+	case DataTypeInt16LE:
+		binary.LittleEndian.PutUint16(raw, uint16(int16(value)))
+	case DataTypeInt16BE:
+		binary.BigEndian.PutUint16(raw, uint16(int16(value)))
+	case DataTypeUint16LE:
+		binary.LittleEndian.PutUint16(raw, uint16(value))
+	case DataTypeUint16BE:
+		binary.BigEndian.PutUint16(raw, uint16(value))
+	case DataTypeInt32LE:
+		binary.LittleEndian.PutUint32(raw, uint32(int32(value)))
+	case DataTypeInt32BE:
+		binary.BigEndian.PutUint32(raw, uint32(int32(value)))
+	case DataTypeUint32LE:
+		binary.LittleEndian.PutUint32(raw, uint32(value))
+	case DataTypeUint32BE:
+		binary.BigEndian.PutUint32(raw, uint32(value))
+	}
+}
@@ -0,0 +1,140 @@
+package streamcodec
+
+import (
+	"testing"
+
+	"github.com/krendel52/go-astm/v3/errmsg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDescriptor(t *testing.T) {
+	// Arrange
+	input := "FLOATLE-stream/deflate:base64"
+	// Act
+	descriptor, err := ParseDescriptor(input)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, DataTypeFloatLE, descriptor.DataType)
+	assert.Equal(t, CompressionDeflate, descriptor.Compression)
+	assert.Equal(t, EncodingBase64, descriptor.Encoding)
+	assert.Equal(t, input, descriptor.String())
+}
+
+func TestParseDescriptor_Invalid(t *testing.T) {
+	// Arrange
+	input := "FLOATLE/deflate"
+	// Act
+	_, err := ParseDescriptor(input)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrStreamCodecInvalidDescriptor)
+}
+
+func TestParseDescriptor_UnsupportedParts(t *testing.T) {
+	// Act
+	_, errType := ParseDescriptor("COMPLEX-stream/deflate:base64")
+	_, errCompression := ParseDescriptor("FLOATLE-stream/lzma:base64")
+	_, errEncoding := ParseDescriptor("FLOATLE-stream/deflate:base32")
+	// Assert
+	assert.ErrorIs(t, errType, errmsg.ErrStreamCodecUnsupportedDataType)
+	assert.ErrorIs(t, errCompression, errmsg.ErrStreamCodecUnsupportedCompression)
+	assert.ErrorIs(t, errEncoding, errmsg.ErrStreamCodecUnsupportedEncoding)
+}
+
+func TestDecodeFloat32_YumizenHistogramHeader(t *testing.T) {
+	// Arrange
+	descriptor := "FLOATLE-stream/deflate:base64"
+	data := "Y2AAAQ4nMMXQA6IdgMghPS3IoXrKWsdVH00cIXIN9iA5AA=="
+	// Act
+	values, err := DecodeFloat32(descriptor, data)
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []float32{0, 34, 0, 70, 2, 3, 3.2875001, 21.6975, 11.309, 0, 1, 2}, values)
+}
+
+func TestDecodeValues_YumizenMatrixHeader(t *testing.T) {
+	// Arrange
+	stream := Stream{
+		Descriptor: "FLOATLE-stream/deflate:base64",
+		Data:       "Y2AAggf/XRjgtIMDiAkA",
+	}
+	// Act
+	values, err := stream.Values()
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{0, 2047, 0, 2047, 3, 0}, values)
+}
+
+func TestDecodeFloat32_DataTypeMismatch(t *testing.T) {
+	// Arrange
+	data, _ := EncodeValues("INT16LE-stream/none:hex", []float64{1, 2})
+	// Act
+	_, err := DecodeFloat32("INT16LE-stream/none:hex", data)
+	// Assert
+	assert.ErrorIs(t, err, errmsg.ErrStreamCodecDataTypeMismatch)
+}
+
+func TestDecodeValues_InvalidData(t *testing.T) {
+	// Act
+	_, errEncoding := DecodeValues("FLOATLE-stream/deflate:base64", "not base64!")
+	_, errLength := DecodeValues("INT32LE-stream/none:hex", "010203")
+	// Assert
+	assert.ErrorIs(t, errEncoding, errmsg.ErrStreamCodecDecodingError)
+	assert.ErrorIs(t, errLength, errmsg.ErrStreamCodecInvalidDataLength)
+}
+
+func TestEncodeFloat32_RoundTrip(t *testing.T) {
+	// Arrange
+	values := []float32{0, 1.5, -2.25, 3.2875001, 21.6975}
+	// Act
+	stream, err := NewFloat32Stream("FLOATLE-stream/deflate:base64", values)
+	decoded, errDecode := stream.Float32()
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, errDecode)
+	assert.Equal(t, values, decoded)
+}
+
+func TestEncodeValues_RoundTripAllTypes(t *testing.T) {
+	// Arrange
+	values := []float64{0, 1, 2, 100, 127}
+	for dataType := range dataTypeSizes {
+		for _, compression := range []Compression{CompressionNone, CompressionDeflate, CompressionZlib, CompressionGzip} {
+			for _, encoding := range []Encoding{EncodingBase64, EncodingHex} {
+				descriptor := Descriptor{DataType: dataType, Compression: compression, Encoding: encoding}.String()
+				// Act
+				stream, err := NewStream(descriptor, values)
+				decoded, errDecode := stream.Values()
+				// Assert
+				assert.Nil(t, err, descriptor)
+				assert.Nil(t, errDecode, descriptor)
+				assert.Equal(t, values, decoded, descriptor)
+			}
+		}
+	}
+}
+
+func TestEncodeValues_SignedBigEndian(t *testing.T) {
+	// Act
+	data, err := EncodeValues("INT16BE-stream/none:hex", []float64{-2, 258})
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "FFFE0102", data)
+}
+
+func TestDecodeValues_MaxStreamValues(t *testing.T) {
+	// Arrange
+	for _, compression := range []Compression{CompressionDeflate, CompressionZlib, CompressionGzip} {
+		descriptor := Descriptor{DataType: DataTypeUint8, Compression: compression, Encoding: EncodingBase64}
+		data, errMax := EncodeBytes(descriptor, make([]byte, MaxStreamValues))
+		dataTooLong, errTooLong := EncodeBytes(descriptor, make([]byte, MaxStreamValues+1))
+		// Act
+		values, err := DecodeValues(descriptor.String(), data)
+		_, errExceeded := DecodeValues(descriptor.String(), dataTooLong)
+		// Assert
+		assert.Nil(t, errMax)
+		assert.Nil(t, errTooLong)
+		assert.Nil(t, err, descriptor.String())
+		assert.Len(t, values, MaxStreamValues, descriptor.String())
+		assert.ErrorIs(t, errExceeded, errmsg.ErrStreamCodecInvalidDataLength, descriptor.String())
+	}
+}
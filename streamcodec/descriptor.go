@@ -0,0 +1,114 @@
+package streamcodec
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/krendel52/go-astm/v3/errmsg"
+)
+
+// Binary stream descriptors as sent by e.g. Horiba Yumizen instruments in manufacturer records
+// Format: <type>-stream/<compression>:<encoding> (e.g. FLOATLE-stream/deflate:base64)
+
+type DataType string
+
+const (
+	DataTypeFloatLE  DataType = "FLOATLE"
+	DataTypeFloatBE  DataType = "FLOATBE"
+	DataTypeDoubleLE DataType = "DOUBLELE"
+	DataTypeDoubleBE DataType = "DOUBLEBE"
+	DataTypeInt8     DataType = "INT8"
+	DataTypeUint8    DataType = "UINT8"
+	DataTypeInt16LE  DataType = "INT16LE"
+	DataTypeInt16BE  DataType = "INT16BE"
+	DataTypeUint16LE DataType = "UINT16LE"
+	DataTypeUint16BE DataType = "UINT16BE"
+	DataTypeInt32LE  DataType = "INT32LE"
+	DataTypeInt32BE  DataType = "INT32BE"
+	DataTypeUint32LE DataType = "UINT32LE"
+	DataTypeUint32BE DataType = "UINT32BE"
+)
+
+type Compression string
+
+const (
+	CompressionNone    Compression = "none"
+	CompressionDeflate Compression = "deflate"
+	CompressionZlib    Compression = "zlib"
+	CompressionGzip    Compression = "gzip"
+)
+
+type Encoding string
+
+const (
+	EncodingBase64 Encoding = "base64"
+	EncodingHex    Encoding = "hex"
+)
+
+type Descriptor struct {
+	DataType    DataType
+	Compression Compression
+	Encoding    Encoding
+}
+
+// Regular expression to split the descriptor into its parts
+var regexDescriptor = regexp.MustCompile(`^([A-Za-z0-9]+)-stream/([A-Za-z0-9]+):([A-Za-z0-9]+)$`)
+
+func ParseDescriptor(input string) (descriptor Descriptor, err error) {
+	// Split the descriptor into type, compression and encoding
+	matches := regexDescriptor.FindStringSubmatch(strings.TrimSpace(input))
+	if matches == nil {
+		return Descriptor{}, fmt.Errorf("%w: %q", errmsg.ErrStreamCodecInvalidDescriptor, input)
+	}
+	descriptor = Descriptor{
+		DataType:    DataType(strings.ToUpper(matches[1])),
+		Compression: Compression(strings.ToLower(matches[2])),
+		Encoding:    Encoding(strings.ToLower(matches[3])),
+	}
+	// Check that every part is supported
+	err = descriptor.Validate()
+	if err != nil {
+		return Descriptor{}, err
+	}
+	return descriptor, nil
+}
+
+func (d Descriptor) Validate() error {
+	if _, exists := dataTypeSizes[d.DataType]; !exists {
+		return fmt.Errorf("%w: %q", errmsg.ErrStreamCodecUnsupportedDataType, d.DataType)
+	}
+	switch d.Compression {
+	case CompressionNone, CompressionDeflate, CompressionZlib, CompressionGzip:
+	default:
+		return fmt.Errorf("%w: %q", errmsg.ErrStreamCodecUnsupportedCompression, d.Compression)
+	}
+	switch d.Encoding {
+	case EncodingBase64, EncodingHex:
+	default:
+		return fmt.Errorf("%w: %q", errmsg.ErrStreamCodecUnsupportedEncoding, d.Encoding)
+	}
+	return nil
+}
+
+func (d Descriptor) String() string {
+	return string(d.DataType) + "-stream/" + string(d.Compression) + ":" + string(d.Encoding)
+}
+
+// Size of a single value in bytes for each data type
+var dataTypeSizes = map[DataType]int{
+	DataTypeFloatLE:  4,
+	DataTypeFloatBE:  4,
+	DataTypeDoubleLE: 8,
+	DataTypeDoubleBE: 8,
+	DataTypeInt8:     1,
+	DataTypeUint8:    1,
+	DataTypeInt16LE:  2,
+	DataTypeInt16BE:  2,
+	DataTypeUint16LE: 2,
+	DataTypeUint16BE: 2,
+	DataTypeInt32LE:  4,
+	DataTypeInt32BE:  4,
+	DataTypeUint32LE: 4,
+	DataTypeUint32BE: 4,
+}
@@ -1,12 +1,21 @@
 package astm
 
 import (
+	"fmt"
 	"github.com/blutspende/bloodlab-common/encoding"
+	"github.com/krendel52/go-astm/v3/errmsg"
 	"github.com/krendel52/go-astm/v3/functions"
 	"github.com/krendel52/go-astm/v3/models/astmmodels"
 )
 
 func Unmarshal(messageData []byte, targetStruct interface{}, configuration ...astmmodels.Configuration) (err error) {
+	// Last resort only: malformed input is rejected with the errors of the parsing, a panic would be a bug
+	// of the library (see the fuzz tests), but it is still returned as an error instead of crashing the caller
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%w: %v", errmsg.ErrUnmarshalUnexpectedFailure, recovered)
+		}
+	}()
 	// Load configuration
 	config, err := functions.LoadConfiguration(configuration...)
 	if err != nil {
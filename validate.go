@@ -0,0 +1,21 @@
+package astm
+
+import (
+	"github.com/krendel52/go-astm/v3/functions"
+	"github.com/krendel52/go-astm/v3/models/astmmodels"
+)
+
+func Validate(sourceStruct interface{}, configuration ...astmmodels.Configuration) (report astmmodels.ValidationReport, err error) {
+	// Load configuration
+	config, err := functions.LoadConfiguration(configuration...)
+	if err != nil {
+		return astmmodels.ValidationReport{}, err
+	}
+	// Collect all the problems of the source structure
+	report, err = functions.ValidateStruct(sourceStruct, config)
+	if err != nil {
+		return astmmodels.ValidationReport{}, err
+	}
+	// Return the report and no error if the structure could be validated
+	return report, nil
+}